@@ -1,7 +1,12 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/sensu/uchiwa/uchiwa"
 	"github.com/sensu/uchiwa/uchiwa/audit"
@@ -9,6 +14,7 @@ import (
 	"github.com/sensu/uchiwa/uchiwa/authorization"
 	"github.com/sensu/uchiwa/uchiwa/config"
 	"github.com/sensu/uchiwa/uchiwa/filters"
+	"github.com/sensu/uchiwa/uchiwa/logger"
 )
 
 func main() {
@@ -29,13 +35,47 @@ func main() {
 	}
 
 	// Audit
-	audit.Log = audit.LogMock
+	auditLog, err := audit.New(config.Uchiwa.Audit.Logfile, audit.FileOptions{
+		Compress:   config.Uchiwa.Audit.Compress,
+		MaxBackups: config.Uchiwa.Audit.MaxBackups,
+		MaxSizeMB:  config.Uchiwa.Audit.MaxSizeMB,
+	})
+	if err != nil {
+		logger.Fatalf("Could not open the audit log file: %s", err)
+	}
+	audit.Log = audit.Retain(auditLog, config.Uchiwa.Audit.Retain)
 
 	// Authorization
-	uchiwa.Authorization = &authorization.Uchiwa{}
+	resources := make(map[string][]string)
+	for name, role := range config.Uchiwa.Authorization.Roles {
+		if len(role.Resources) > 0 {
+			resources[name] = role.Resources
+		}
+	}
+	uchiwa.Authorization = &authorization.Uchiwa{Resources: resources}
 
 	// Filters
-	uchiwa.Filters = &filters.Uchiwa{}
+	uchiwa.Filters = &filters.Uchiwa{
+		RedactedCheckFields: config.Uchiwa.Redact.CheckFields,
+		RedactExemptRoles:   config.Uchiwa.Redact.ExemptRoles,
+	}
+
+	// Gracefully stop the web server on SIGINT & SIGTERM
+	stopped := make(chan struct{})
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+
+		logger.Warning("Shutting down Uchiwa")
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := u.Shutdown(ctx); err != nil {
+			logger.Warningf("Could not gracefully shut down the web server: %s", err)
+		}
+		close(stopped)
+	}()
 
 	u.WebServer(publicPath, auth)
+	<-stopped
 }
@@ -0,0 +1,136 @@
+package uchiwa
+
+import (
+	"sort"
+	"time"
+
+	"github.com/sensu/uchiwa/uchiwa/helpers"
+	"github.com/sensu/uchiwa/uchiwa/structs"
+)
+
+// mutatingActions contains the audited actions that modify the resources
+var mutatingActions = []string{
+	"clearsilence",
+	"createstash",
+	"deleteclient",
+	"deleteresult",
+	"deletestash",
+	"request",
+	"resolve",
+	"silence",
+}
+
+// userActivity holds the number of audited actions performed by a user
+type userActivity struct {
+	User    string         `json:"user"`
+	Total   int            `json:"total"`
+	Actions map[string]int `json:"actions"`
+}
+
+// activityEntry holds a mutating action performed by a user
+type activityEntry struct {
+	Actor     string `json:"actor"`
+	Action    string `json:"action"`
+	Target    string `json:"target"`
+	Dc        string `json:"dc,omitempty"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// buildAuditActivity counts, per user and action, the provided audit entries
+// recorded since the provided time. The most active users come first
+func buildAuditActivity(entries []structs.AuditLog, since time.Time) []userActivity {
+	users := make(map[string]*userActivity)
+
+	for _, entry := range entries {
+		if entry.Date.Before(since) {
+			continue
+		}
+
+		activity, ok := users[entry.User]
+		if !ok {
+			activity = &userActivity{User: entry.User, Actions: make(map[string]int)}
+			users[entry.User] = activity
+		}
+
+		activity.Total++
+		activity.Actions[entry.Action]++
+	}
+
+	activities := make([]userActivity, 0, len(users))
+	for _, activity := range users {
+		activities = append(activities, *activity)
+	}
+
+	sort.Slice(activities, func(i, j int) bool {
+		if activities[i].Total != activities[j].Total {
+			return activities[i].Total > activities[j].Total
+		}
+		return activities[i].User < activities[j].User
+	})
+
+	return activities
+}
+
+// eventResources returns the resources targeted by an action on the event of
+// the provided client and check, for the audit log
+func eventResources(client, check, dc string) []structs.AuditResource {
+	return []structs.AuditResource{
+		{Type: "client", Name: client, Dc: dc},
+		{Type: "check", Name: check, Dc: dc},
+	}
+}
+
+// resourceAuditTrail returns the provided audit entries targeting the
+// resource of the provided type and name, the most recent first. The entries
+// of every datacenter are returned if the datacenter of the resource is empty
+func resourceAuditTrail(entries []structs.AuditLog, resource structs.AuditResource) []interface{} {
+	trail := []interface{}{}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		for _, target := range entries[i].Resources {
+			if target.Type != resource.Type || target.Name != resource.Name {
+				continue
+			}
+			if resource.Dc != "" && target.Dc != resource.Dc {
+				continue
+			}
+
+			trail = append(trail, entries[i])
+			break
+		}
+	}
+
+	return trail
+}
+
+// buildActivityFeed returns the mutating actions among the provided audit
+// entries, recorded since the provided time, the most recent first. The
+// actions targeting a datacenter that is not visible are omitted
+func buildActivityFeed(entries []structs.AuditLog, since time.Time, visible func(dc string) bool) []activityEntry {
+	feed := []activityEntry{}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if entry.Date.Before(since) || !helpers.IsStringInArray(entry.Action, mutatingActions) {
+			continue
+		}
+
+		var dc string
+		if len(entry.Resources) > 0 {
+			dc = entry.Resources[0].Dc
+		}
+		if !visible(dc) {
+			continue
+		}
+
+		feed = append(feed, activityEntry{
+			Actor:     entry.User,
+			Action:    entry.Action,
+			Target:    entry.URL,
+			Dc:        dc,
+			Timestamp: entry.Date.Unix(),
+		})
+	}
+
+	return feed
+}
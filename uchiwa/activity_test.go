@@ -0,0 +1,82 @@
+package uchiwa
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sensu/uchiwa/uchiwa/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildAuditActivity(t *testing.T) {
+	entries := []structs.AuditLog{
+		{Date: time.Unix(100, 0), Action: "login", User: "foo"},
+		{Date: time.Unix(200, 0), Action: "login", User: "bar"},
+		{Date: time.Unix(300, 0), Action: "resolve", User: "bar"},
+		{Date: time.Unix(400, 0), Action: "resolve", User: "bar"},
+		{Date: time.Unix(500, 0), Action: "logout", User: "foo"},
+		{Date: time.Unix(600, 0), Action: "login", User: "qux"},
+	}
+
+	activities := buildAuditActivity(entries, time.Unix(0, 0))
+	assert.Equal(t, []userActivity{
+		{User: "bar", Total: 3, Actions: map[string]int{"login": 1, "resolve": 2}},
+		{User: "foo", Total: 2, Actions: map[string]int{"login": 1, "logout": 1}},
+		{User: "qux", Total: 1, Actions: map[string]int{"login": 1}},
+	}, activities)
+
+	activities = buildAuditActivity(entries, time.Unix(400, 0))
+	assert.Equal(t, []userActivity{
+		{User: "bar", Total: 1, Actions: map[string]int{"resolve": 1}},
+		{User: "foo", Total: 1, Actions: map[string]int{"logout": 1}},
+		{User: "qux", Total: 1, Actions: map[string]int{"login": 1}},
+	}, activities)
+
+	assert.Equal(t, []userActivity{}, buildAuditActivity(nil, time.Unix(0, 0)))
+}
+
+func TestResourceAuditTrail(t *testing.T) {
+	entries := []structs.AuditLog{
+		{Action: "deleteresult", User: "foo", Resources: eventResources("foo", "disk", "us-east-1")},
+		{Action: "logout", User: "foo"},
+		{Action: "request", User: "bar", Resources: []structs.AuditResource{{Type: "check", Name: "foo", Dc: "us-east-1"}}},
+		{Action: "deleteclient", User: "bar", Resources: []structs.AuditResource{{Type: "client", Name: "foo", Dc: "us-west-1"}}},
+		{Action: "resolve", User: "baz", Resources: eventResources("foo", "cpu", "us-east-1")},
+	}
+
+	// The most recent entries come first
+	trail := resourceAuditTrail(entries, structs.AuditResource{Type: "client", Name: "foo"})
+	assert.Equal(t, []interface{}{entries[4], entries[3], entries[0]}, trail)
+
+	trail = resourceAuditTrail(entries, structs.AuditResource{Type: "client", Name: "foo", Dc: "us-east-1"})
+	assert.Equal(t, []interface{}{entries[4], entries[0]}, trail)
+
+	trail = resourceAuditTrail(entries, structs.AuditResource{Type: "check", Name: "disk"})
+	assert.Equal(t, []interface{}{entries[0]}, trail)
+
+	// No matching entry
+	trail = resourceAuditTrail(entries, structs.AuditResource{Type: "stash", Name: "foo"})
+	assert.Equal(t, []interface{}{}, trail)
+}
+
+func TestBuildActivityFeed(t *testing.T) {
+	entries := []structs.AuditLog{
+		{Date: time.Unix(100, 0), Action: "deleteclient", User: "foo", URL: "/clients/foo?dc=us-east-1", Resources: []structs.AuditResource{{Type: "client", Name: "foo", Dc: "us-east-1"}}},
+		{Date: time.Unix(200, 0), Action: "silence", User: "bar", URL: "/silenced?check=disk&dc=us-west-1", Resources: []structs.AuditResource{{Type: "check", Name: "disk", Dc: "us-west-1"}}},
+		{Date: time.Unix(300, 0), Action: "logout", User: "foo"},
+		{Date: time.Unix(400, 0), Action: "createstash", User: "baz", URL: "/stashes/foo?dc=us-east-1", Resources: []structs.AuditResource{{Type: "stash", Name: "foo", Dc: "us-east-1"}}},
+	}
+	visible := func(dc string) bool { return dc == "us-east-1" }
+
+	// The most recent actions come first
+	feed := buildActivityFeed(entries, time.Unix(0, 0), visible)
+	assert.Equal(t, []activityEntry{
+		{Actor: "baz", Action: "createstash", Target: "/stashes/foo?dc=us-east-1", Dc: "us-east-1", Timestamp: 400},
+		{Actor: "foo", Action: "deleteclient", Target: "/clients/foo?dc=us-east-1", Dc: "us-east-1", Timestamp: 100},
+	}, feed)
+
+	feed = buildActivityFeed(entries, time.Unix(150, 0), func(string) bool { return true })
+	assert.Equal(t, 2, len(feed))
+	assert.Equal(t, "createstash", feed[0].Action)
+	assert.Equal(t, "silence", feed[1].Action)
+}
@@ -2,7 +2,9 @@ package uchiwa
 
 import (
 	"fmt"
+	"sort"
 
+	"github.com/sensu/uchiwa/uchiwa/helpers"
 	"github.com/sensu/uchiwa/uchiwa/logger"
 )
 
@@ -91,6 +93,218 @@ func (u *Uchiwa) GetAggregateResults(name, severity, dc string) (*[]interface{},
 	return &results, nil
 }
 
+// aggregateCheckResults holds the number of results of a check within an
+// aggregate, and the clients that produced them
+type aggregateCheckResults struct {
+	Count   int      `json:"count"`
+	Clients []string `json:"clients"`
+}
+
+// groupAggregateResults buckets the results of an aggregate, as returned for
+// a given severity, by their check
+func groupAggregateResults(results []interface{}) map[string]*aggregateCheckResults {
+	groups := make(map[string]*aggregateCheckResults)
+
+	for _, r := range results {
+		result, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		check, ok := result["check"].(string)
+		if !ok {
+			continue
+		}
+
+		group, ok := groups[check]
+		if !ok {
+			group = &aggregateCheckResults{Clients: []string{}}
+			groups[check] = group
+		}
+
+		summaries, _ := result["summary"].([]interface{})
+		for _, s := range summaries {
+			summary, ok := s.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			clients, _ := summary["clients"].([]interface{})
+			if total, ok := summary["total"].(float64); ok {
+				group.Count += int(total)
+			} else {
+				group.Count += len(clients)
+			}
+
+			if len(clients) > 0 {
+				group.Clients = MergeStringSlices(group.Clients, helpers.InterfaceToString(clients))
+			}
+		}
+	}
+
+	for _, group := range groups {
+		sort.Strings(group.Clients)
+	}
+
+	return groups
+}
+
+// aggregateSeverities lists the non-OK severities of the results of an
+// aggregate, from the worst to the least severe, along with their status
+var aggregateSeverities = []struct {
+	name   string
+	status int
+}{
+	{"critical", 2},
+	{"unknown", 3},
+	{"warning", 1},
+}
+
+// aggregateOffender holds a client contributing to the non-OK portion of an
+// aggregate, along with its worst severity and the checks at fault
+type aggregateOffender struct {
+	Name     string      `json:"name"`
+	Severity string      `json:"severity"`
+	Status   int         `json:"status"`
+	Checks   []string    `json:"checks"`
+	Client   interface{} `json:"client"`
+}
+
+// aggregateOffenders returns the clients of the provided datacenter found in
+// the non-OK results of an aggregate, keyed by severity as returned by the
+// /aggregates/:name/results/:severity API, sorted from the worst severity and
+// the most checks at fault. Only the provided clients are considered, so the
+// clients hidden from the user are ignored
+func aggregateOffenders(results map[string][]interface{}, clients []interface{}, dc string) []aggregateOffender {
+	offenders := []aggregateOffender{}
+	index := make(map[string]int)
+	rank := make(map[string]int)
+
+	for i, severity := range aggregateSeverities {
+		for check, group := range groupAggregateResults(results[severity.name]) {
+			for _, name := range group.Clients {
+				client := findClientInDc(name, dc, clients)
+				if client == nil {
+					continue
+				}
+
+				position, ok := index[name]
+				if !ok {
+					position = len(offenders)
+					index[name] = position
+					rank[name] = i
+					offenders = append(offenders, aggregateOffender{
+						Name:     name,
+						Severity: severity.name,
+						Status:   severity.status,
+						Checks:   []string{},
+						Client:   client,
+					})
+				}
+
+				if !helpers.IsStringInArray(check, offenders[position].Checks) {
+					offenders[position].Checks = append(offenders[position].Checks, check)
+				}
+			}
+		}
+	}
+
+	for i := range offenders {
+		sort.Strings(offenders[i].Checks)
+	}
+
+	sort.Slice(offenders, func(i, j int) bool {
+		if rank[offenders[i].Name] != rank[offenders[j].Name] {
+			return rank[offenders[i].Name] < rank[offenders[j].Name]
+		}
+		if len(offenders[i].Checks) != len(offenders[j].Checks) {
+			return len(offenders[i].Checks) > len(offenders[j].Checks)
+		}
+		return offenders[i].Name < offenders[j].Name
+	})
+
+	return offenders
+}
+
+// aggregateSubscription holds the number of member checks and distinct
+// clients of an aggregate contributed by a subscription
+type aggregateSubscription struct {
+	Subscription string `json:"subscription"`
+	Checks       int    `json:"checks"`
+	Clients      int    `json:"clients"`
+}
+
+// aggregateSubscriptions computes the contribution of each subscription to an
+// aggregate, from its member checks, as returned by the /aggregates/:name/checks
+// API, and the subscribers of their definition within the provided datacenter.
+// Only the provided checks and clients are considered, so the members hidden
+// from the user are ignored, as are the checks without subscribers
+func aggregateSubscriptions(members, checks, clients []interface{}, dc string) []aggregateSubscription {
+	subscribers := make(map[string][]string)
+	for _, c := range checks {
+		check, ok := c.(map[string]interface{})
+		if !ok || check["dc"] != dc {
+			continue
+		}
+
+		name, _ := check["name"].(string)
+		s, _ := check["subscribers"].([]interface{})
+		subscribers[name] = helpers.InterfaceToString(s)
+	}
+
+	visible := make(map[string]bool)
+	for _, c := range clients {
+		client, ok := c.(map[string]interface{})
+		if !ok || client["dc"] != dc {
+			continue
+		}
+
+		if name, ok := client["name"].(string); ok {
+			visible[name] = true
+		}
+	}
+
+	counts := make(map[string]*aggregateSubscription)
+	contributors := make(map[string]map[string]bool)
+	for _, m := range members {
+		member, ok := m.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name, _ := member["name"].(string)
+		memberClients, _ := member["clients"].([]interface{})
+
+		for _, subscription := range subscribers[name] {
+			count, ok := counts[subscription]
+			if !ok {
+				count = &aggregateSubscription{Subscription: subscription}
+				counts[subscription] = count
+				contributors[subscription] = make(map[string]bool)
+			}
+			count.Checks++
+
+			for _, client := range helpers.InterfaceToString(memberClients) {
+				if visible[client] {
+					contributors[subscription][client] = true
+				}
+			}
+		}
+	}
+
+	contributions := make([]aggregateSubscription, 0, len(counts))
+	for subscription, count := range counts {
+		count.Clients = len(contributors[subscription])
+		contributions = append(contributions, *count)
+	}
+
+	sort.Slice(contributions, func(i, j int) bool {
+		return contributions[i].Subscription < contributions[j].Subscription
+	})
+
+	return contributions
+}
+
 func (u *Uchiwa) findAggregate(name string) ([]interface{}, error) {
 	var checks []interface{}
 	for _, c := range u.Data.Aggregates {
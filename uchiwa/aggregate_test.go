@@ -1,12 +1,30 @@
 package uchiwa
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
 
+	"github.com/dgrijalva/jwt-go"
+	"github.com/sensu/uchiwa/uchiwa/config"
+	"github.com/sensu/uchiwa/uchiwa/filters"
+	"github.com/sensu/uchiwa/uchiwa/sensu"
 	"github.com/sensu/uchiwa/uchiwa/structs"
 	"github.com/stretchr/testify/assert"
 )
 
+// hiddenResultsFilters hides every aggregate result from the users
+type hiddenResultsFilters struct {
+	*filters.Uchiwa
+}
+
+func (f hiddenResultsFilters) AggregateResults(data *[]interface{}, token *jwt.Token) []interface{} {
+	return []interface{}{}
+}
+
 func TestFindAggregate(t *testing.T) {
 	u := Uchiwa{
 		Data: &structs.Data{},
@@ -29,3 +47,125 @@ func TestFindAggregate(t *testing.T) {
 	_, err = u.findAggregate("qux")
 	assert.NotNil(t, err)
 }
+
+func TestGroupAggregateResults(t *testing.T) {
+	results := []interface{}{
+		map[string]interface{}{"check": "cpu", "summary": []interface{}{
+			map[string]interface{}{"output": "CRITICAL", "total": float64(2), "clients": []interface{}{"web-02", "web-01"}},
+			map[string]interface{}{"output": "CRITICAL: 99%", "total": float64(1), "clients": []interface{}{"web-03"}},
+		}},
+		map[string]interface{}{"check": "disk", "summary": []interface{}{
+			map[string]interface{}{"output": "CRITICAL", "clients": []interface{}{"db-01"}},
+		}},
+		map[string]interface{}{"check": "mem"},
+		map[string]interface{}{"summary": []interface{}{}},
+		"foo",
+	}
+
+	groups := groupAggregateResults(results)
+	assert.Equal(t, 3, len(groups))
+	assert.Equal(t, &aggregateCheckResults{Count: 3, Clients: []string{"web-01", "web-02", "web-03"}}, groups["cpu"])
+	assert.Equal(t, &aggregateCheckResults{Count: 1, Clients: []string{"db-01"}}, groups["disk"])
+	assert.Equal(t, &aggregateCheckResults{Count: 0, Clients: []string{}}, groups["mem"])
+}
+
+func TestAggregateSubscriptions(t *testing.T) {
+	members := []interface{}{
+		map[string]interface{}{"name": "http", "clients": []interface{}{"web-1", "web-2"}},
+		map[string]interface{}{"name": "disk", "clients": []interface{}{"web-1", "db-1"}},
+		map[string]interface{}{"name": "standalone", "clients": []interface{}{"web-1"}},
+	}
+	checks := []interface{}{
+		map[string]interface{}{"name": "http", "dc": "us-east-1", "subscribers": []interface{}{"web"}},
+		map[string]interface{}{"name": "disk", "dc": "us-east-1", "subscribers": []interface{}{"web", "db"}},
+		map[string]interface{}{"name": "http", "dc": "us-west-1", "subscribers": []interface{}{"proxy"}},
+	}
+	clients := []interface{}{
+		map[string]interface{}{"name": "web-1", "dc": "us-east-1"},
+		map[string]interface{}{"name": "web-2", "dc": "us-east-1"},
+		map[string]interface{}{"name": "db-1", "dc": "us-west-1"},
+	}
+
+	expected := []aggregateSubscription{
+		{Subscription: "db", Checks: 1, Clients: 1},
+		{Subscription: "web", Checks: 2, Clients: 2},
+	}
+	assert.Equal(t, expected, aggregateSubscriptions(members, checks, clients, "us-east-1"))
+
+	assert.Equal(t, []aggregateSubscription{}, aggregateSubscriptions(nil, checks, clients, "us-east-1"))
+}
+
+func TestAggregateOffenders(t *testing.T) {
+	result := func(check string, clients ...interface{}) interface{} {
+		return map[string]interface{}{
+			"check":   check,
+			"summary": []interface{}{map[string]interface{}{"clients": clients, "total": float64(len(clients))}},
+		}
+	}
+	results := map[string][]interface{}{
+		"critical": {result("disk", "bar")},
+		"warning":  {result("cpu", "foo", "bar", "qux"), result("ram", "qux")},
+	}
+	clients := []interface{}{
+		map[string]interface{}{"name": "foo", "dc": "us-east-1"},
+		map[string]interface{}{"name": "bar", "dc": "us-east-1"},
+		map[string]interface{}{"name": "qux", "dc": "us-east-1"},
+	}
+
+	offenders := aggregateOffenders(results, clients, "us-east-1")
+	assert.Equal(t, 3, len(offenders))
+
+	assert.Equal(t, "bar", offenders[0].Name)
+	assert.Equal(t, "critical", offenders[0].Severity)
+	assert.Equal(t, 2, offenders[0].Status)
+	assert.Equal(t, []string{"cpu", "disk"}, offenders[0].Checks)
+	assert.Equal(t, clients[1], offenders[0].Client)
+
+	assert.Equal(t, "qux", offenders[1].Name)
+	assert.Equal(t, []string{"cpu", "ram"}, offenders[1].Checks)
+	assert.Equal(t, "foo", offenders[2].Name)
+	assert.Equal(t, "warning", offenders[2].Severity)
+
+	// The clients hidden from the user are ignored
+	offenders = aggregateOffenders(results, clients[:1], "us-east-1")
+	assert.Equal(t, 1, len(offenders))
+	assert.Equal(t, "foo", offenders[0].Name)
+}
+
+func TestAggregateOffendersHandlerFilters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/aggregates/cpu/results/critical" {
+			fmt.Fprint(w, `[{"check":"cpu","summary":[{"clients":["foo"],"total":1}]}]`)
+			return
+		}
+		fmt.Fprint(w, "[]")
+	}))
+	defer server.Close()
+
+	api := sensu.API{URL: server.URL, Timeout: 1}
+	api.Init()
+	u := &Uchiwa{
+		Config:      &config.Config{},
+		Data:        &structs.Data{Clients: []interface{}{map[string]interface{}{"name": "foo", "dc": "us-east-1"}}},
+		Datacenters: &[]sensu.Sensu{{Name: "us-east-1", APIs: []sensu.API{api}}},
+		Mu:          &sync.Mutex{},
+	}
+	defer func() { Filters = &filters.Uchiwa{} }()
+
+	offenders := func() []aggregateOffender {
+		w := httptest.NewRecorder()
+		u.aggregateHandler(w, httptest.NewRequest("GET", "/aggregates/cpu/offenders?dc=us-east-1", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var result []aggregateOffender
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &result))
+		return result
+	}
+
+	Filters = &filters.Uchiwa{}
+	assert.Equal(t, 1, len(offenders()))
+
+	// The results hidden from the user are not revealed by the offenders
+	Filters = hiddenResultsFilters{&filters.Uchiwa{}}
+	assert.Equal(t, 0, len(offenders()))
+}
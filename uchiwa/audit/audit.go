@@ -1,10 +1,68 @@
 package audit
 
-import "github.com/sensu/uchiwa/uchiwa/structs"
+import (
+	"sync"
+	"time"
+
+	"github.com/sensu/uchiwa/uchiwa/structs"
+)
 
 // Log writes to audit log (Sensu Enterprise only)
 var Log func(structs.AuditLog) error
 
+// retained holds the most recent audit entries, when enabled
+var retained struct {
+	entries []structs.AuditLog
+	mutex   sync.Mutex
+	next    int
+	size    int
+}
+
 func LogMock(log structs.AuditLog) error {
 	return nil
 }
+
+// Retain returns an audit logger that keeps the provided number of most
+// recent entries in memory, so they can later be retrieved with the Entries
+// function, before passing them to the provided logger
+func Retain(next func(structs.AuditLog) error, size int) func(structs.AuditLog) error {
+	retained.mutex.Lock()
+	retained.entries = nil
+	retained.next = 0
+	retained.size = size
+	retained.mutex.Unlock()
+
+	if size < 1 {
+		return next
+	}
+
+	return func(log structs.AuditLog) error {
+		if log.Date.IsZero() {
+			log.Date = time.Now()
+		}
+
+		retained.mutex.Lock()
+		if len(retained.entries) < retained.size {
+			retained.entries = append(retained.entries, log)
+		} else {
+			retained.entries[retained.next] = log
+			retained.next = (retained.next + 1) % retained.size
+		}
+		retained.mutex.Unlock()
+
+		return next(log)
+	}
+}
+
+// Entries returns the retained audit entries, from the oldest to the most
+// recent
+func Entries() []structs.AuditLog {
+	retained.mutex.Lock()
+	defer retained.mutex.Unlock()
+
+	entries := make([]structs.AuditLog, 0, len(retained.entries))
+	entries = append(entries, retained.entries[retained.next:]...)
+	entries = append(entries, retained.entries[:retained.next]...)
+
+	return entries
+}
@@ -0,0 +1,39 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sensu/uchiwa/uchiwa/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetain(t *testing.T) {
+	var logged []string
+	next := func(log structs.AuditLog) error {
+		logged = append(logged, log.User)
+		return nil
+	}
+
+	// Retention disabled
+	log := Retain(next, 0)
+	log(structs.AuditLog{User: "foo"})
+	assert.Equal(t, []structs.AuditLog{}, Entries())
+
+	log = Retain(next, 2)
+	for _, user := range []string{"foo", "bar", "qux"} {
+		log(structs.AuditLog{User: user})
+	}
+
+	entries := Entries()
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "bar", entries[0].User)
+	assert.Equal(t, "qux", entries[1].User)
+	assert.False(t, entries[0].Date.IsZero())
+	assert.Equal(t, []string{"foo", "foo", "bar", "qux"}, logged)
+
+	// The date of the entries is kept when provided
+	date := time.Unix(1000, 0)
+	log(structs.AuditLog{Date: date})
+	assert.Equal(t, date, Entries()[1].Date)
+}
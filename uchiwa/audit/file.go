@@ -0,0 +1,210 @@
+package audit
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/sensu/uchiwa/uchiwa/structs"
+)
+
+// FileOptions contains the rotation settings of an audit log file. The file
+// is only rotated if MaxSizeMB is positive, and every backup is kept unless
+// MaxBackups is positive
+type FileOptions struct {
+	Compress   bool
+	MaxBackups int
+	MaxSizeMB  int
+}
+
+// rotatingFile is a file, opened in append mode, that is rotated once it
+// reaches its maximum size. The previous files are named after the file with
+// an increasing suffix, from the most recent to the oldest, e.g. audit.log.1
+type rotatingFile struct {
+	mutex   sync.Mutex
+	file    *os.File
+	maxSize int64
+	options FileOptions
+	path    string
+	size    int64
+}
+
+// File returns an audit logger that appends the entries, as JSON lines, to
+// the file at the provided path
+func File(path string, options FileOptions) (func(structs.AuditLog) error, error) {
+	f, err := openRotatingFile(path, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(log structs.AuditLog) error {
+		b, err := json.Marshal(log)
+		if err != nil {
+			return err
+		}
+
+		_, err = f.Write(append(b, '\n'))
+		return err
+	}, nil
+}
+
+// New returns the audit logger that appends the entries to the file at the
+// provided path, or discards them if no path is provided
+func New(path string, options FileOptions) (func(structs.AuditLog) error, error) {
+	if path == "" {
+		return LogMock, nil
+	}
+
+	return File(path, options)
+}
+
+func openRotatingFile(path string, options FileOptions) (*rotatingFile, error) {
+	f := &rotatingFile{
+		maxSize: int64(options.MaxSizeMB) * 1024 * 1024,
+		options: options,
+		path:    path,
+	}
+
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// open opens the file in append mode and records its current size
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// Write appends p to the file, after rotating it if p would exceed its
+// maximum size. A single write is never split across two files. If the
+// rotation fails, p is still appended to the reopened file and the rotation
+// error is returned
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var rotateErr error
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		rotateErr = f.rotate()
+		if f.file == nil {
+			return 0, rotateErr
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	if err != nil {
+		return n, err
+	}
+	return n, rotateErr
+}
+
+// backup returns the path of the nth most recent backup
+func (f *rotatingFile) backup(n int) string {
+	if f.options.Compress {
+		return fmt.Sprintf("%s.%d.gz", f.path, n)
+	}
+	return fmt.Sprintf("%s.%d", f.path, n)
+}
+
+// rotate moves the current file to the first backup, optionally compressing
+// it, shifts the previous backups, removes the extra ones and reopens the file.
+// The file is reopened even if the rotation fails, so the subsequent entries
+// are still written. Its handle is only nil if it could not be reopened
+func (f *rotatingFile) rotate() error {
+	err := f.file.Close()
+	f.file = nil
+	if err == nil {
+		err = f.shift()
+	}
+
+	if openErr := f.open(); err == nil {
+		err = openErr
+	}
+	return err
+}
+
+// shift moves the closed file to the first backup, optionally compressing it,
+// shifts the previous backups and removes the extra ones
+func (f *rotatingFile) shift() error {
+	// Find the first free backup and shift every backup before it
+	n := 1
+	for exists(f.backup(n)) {
+		n++
+	}
+	for i := n; i > 1; i-- {
+		if err := os.Rename(f.backup(i-1), f.backup(i)); err != nil {
+			return err
+		}
+	}
+
+	if f.options.Compress {
+		if err := compressFile(f.path, f.backup(1)); err != nil {
+			return err
+		}
+	} else if err := os.Rename(f.path, f.backup(1)); err != nil {
+		return err
+	}
+
+	if f.options.MaxBackups > 0 {
+		for i := f.options.MaxBackups + 1; exists(f.backup(i)); i++ {
+			if err := os.Remove(f.backup(i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// compressFile compresses the file at src into dst with gzip and removes src
+func compressFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(out)
+	if _, err := io.Copy(gz, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(src)
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,131 @@
+package audit
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sensu/uchiwa/uchiwa/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+
+	log, err := File(path, FileOptions{})
+	assert.Nil(t, err)
+	assert.Nil(t, log(structs.AuditLog{Action: "login", User: "foo"}))
+	assert.Nil(t, log(structs.AuditLog{Action: "logout", User: "foo"}))
+
+	b, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	assert.Equal(t, 2, len(lines))
+	assert.Contains(t, lines[0], `"action":"login"`)
+	assert.Contains(t, lines[1], `"action":"logout"`)
+}
+
+func TestNew(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+
+	// The entries are discarded without a configured path, like by default
+	log, err := New("", FileOptions{})
+	assert.Nil(t, err)
+	assert.Nil(t, log(structs.AuditLog{Action: "login", User: "foo"}))
+
+	log, err = New(path, FileOptions{})
+	assert.Nil(t, err)
+	assert.Nil(t, log(structs.AuditLog{Action: "login", User: "foo"}))
+	assert.True(t, exists(path))
+}
+
+func TestRotatingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+
+	f, err := openRotatingFile(path, FileOptions{MaxBackups: 2})
+	assert.Nil(t, err)
+	f.maxSize = 10
+
+	for _, line := range []string{"aaaaaaaa\n", "bbbbbbbb\n", "cccccccc\n", "dddddddd\n"} {
+		_, err := f.Write([]byte(line))
+		assert.Nil(t, err)
+	}
+
+	b, _ := ioutil.ReadFile(path)
+	assert.Equal(t, "dddddddd\n", string(b))
+	b, _ = ioutil.ReadFile(path + ".1")
+	assert.Equal(t, "cccccccc\n", string(b))
+	b, _ = ioutil.ReadFile(path + ".2")
+	assert.Equal(t, "bbbbbbbb\n", string(b))
+	assert.False(t, exists(path+".3"))
+}
+
+func TestRotatingFileFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+
+	// An extra backup that can't be removed
+	assert.Nil(t, os.MkdirAll(filepath.Join(path+".2", "foo"), 0700))
+
+	f, err := openRotatingFile(path, FileOptions{MaxBackups: 1})
+	assert.Nil(t, err)
+	f.maxSize = 10
+
+	_, err = f.Write([]byte("aaaaaaaa\n"))
+	assert.Nil(t, err)
+
+	// The entry is written despite the failed rotation, and so are the next ones
+	_, err = f.Write([]byte("bbbbbbbb\n"))
+	assert.NotNil(t, err)
+	assert.NotNil(t, f.file)
+
+	assert.Nil(t, os.RemoveAll(path+".2"))
+	_, err = f.Write([]byte("cccccccc\n"))
+	assert.Nil(t, err)
+
+	b, _ := ioutil.ReadFile(path)
+	assert.Equal(t, "cccccccc\n", string(b))
+	b, _ = ioutil.ReadFile(path + ".1")
+	assert.Equal(t, "bbbbbbbb\n", string(b))
+}
+
+func TestRotatingFileCompress(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+
+	f, err := openRotatingFile(path, FileOptions{Compress: true})
+	assert.Nil(t, err)
+	f.maxSize = 10
+
+	for _, line := range []string{"aaaaaaaa\n", "bbbbbbbb\n", "cccccccc\n"} {
+		_, err := f.Write([]byte(line))
+		assert.Nil(t, err)
+	}
+
+	assert.False(t, exists(path+".1"))
+	for n, expected := range map[string]string{".1.gz": "bbbbbbbb\n", ".2.gz": "aaaaaaaa\n"} {
+		file, err := os.Open(path + n)
+		assert.Nil(t, err)
+		gz, err := gzip.NewReader(file)
+		assert.Nil(t, err)
+		b, _ := ioutil.ReadAll(gz)
+		assert.Equal(t, expected, string(b))
+		file.Close()
+	}
+}
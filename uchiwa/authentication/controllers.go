@@ -39,6 +39,8 @@ func restrictedHandler(next http.Handler) http.Handler {
 			if err != nil {
 				logger.Debug("No JWT token provided")
 			} else {
+				normalizeUsername(token, usernameClaim)
+
 				xsrfTokenFromClaims, ok := token.Claims["xsrfToken"]
 				if !ok {
 					logger.Debug("The XSRF Token is missing from the JWT claims")
@@ -21,6 +21,15 @@ const JWTToken = "jwtToken"
 var (
 	privateKey *rsa.PrivateKey
 	publicKey  *rsa.PublicKey
+
+	// usernameClaim contains the name of the claim holding the username, when
+	// the identity provider does not use the username claim
+	usernameClaim string
+
+	// expectedAudience and expectedIssuer contain the values that the aud and
+	// iss claims of a JWT must match, when configured
+	expectedAudience string
+	expectedIssuer   string
 )
 
 // GetJWTFromContext retrieves the JWT Token from the request
@@ -58,6 +67,18 @@ func GetToken(user *User, xsfrToken string) (string, error) {
 	t.Claims["username"] = user.Username
 	t.Claims["xsrfToken"] = xsfrToken
 
+	if usernameClaim != "" {
+		t.Claims[usernameClaim] = user.Username
+	}
+
+	if expectedAudience != "" {
+		t.Claims["aud"] = expectedAudience
+	}
+
+	if expectedIssuer != "" {
+		t.Claims["iss"] = expectedIssuer
+	}
+
 	if privateKey == nil {
 		return "", errors.New("Could not generate a token for the user. Invalid private key")
 	}
@@ -93,6 +114,10 @@ func generateToken() (*rsa.PrivateKey, *rsa.PublicKey) {
 // ones with the generateToken() function
 func initToken(a structs.Auth) {
 	var err error
+	usernameClaim = a.UsernameClaim
+	expectedAudience = a.ExpectedAudience
+	expectedIssuer = a.ExpectedIssuer
+
 	privateKey, publicKey, err = loadToken(a)
 	if err != nil {
 		// At this point we need to generate temporary RSA keys
@@ -134,6 +159,23 @@ func loadToken(a structs.Auth) (*rsa.PrivateKey, *rsa.PublicKey, error) {
 	return privateKey, publicKey, nil
 }
 
+// normalizeUsername sets the canonical username claim of the JWT from the
+// provided claim, so the username can always be found under the same claim
+// regardless of the identity provider
+func normalizeUsername(token *jwt.Token, claim string) {
+	if claim == "" || claim == "username" {
+		return
+	}
+
+	username, ok := token.Claims[claim].(string)
+	if !ok || username == "" {
+		logger.Debugf("The claim %s could not be used as the username", claim)
+		return
+	}
+
+	token.Claims["username"] = username
+}
+
 // setJWTIntoContext injects the JWT Token into the request for later use
 func setJWTInContext(r *http.Request, token *jwt.Token) {
 	context.Set(r, JWTToken, token)
@@ -160,5 +202,43 @@ func verifyJWT(tokenString string) (*jwt.Token, error) {
 		return nil, errors.New("")
 	}
 
+	if err := validateClaims(token, expectedAudience, expectedIssuer); err != nil {
+		logger.Debug(err)
+		return nil, errors.New("")
+	}
+
 	return token, nil
 }
+
+// validateClaims verifies that the aud and iss claims of the JWT match the
+// provided audience and issuer. Empty values are not validated
+func validateClaims(token *jwt.Token, audience, issuer string) error {
+	if audience != "" && !hasAudience(token.Claims["aud"], audience) {
+		return fmt.Errorf("The JWT was not issued for the audience %s", audience)
+	}
+
+	if issuer != "" {
+		if iss, ok := token.Claims["iss"].(string); !ok || iss != issuer {
+			return fmt.Errorf("The JWT was not issued by %s", issuer)
+		}
+	}
+
+	return nil
+}
+
+// hasAudience returns whether the aud claim, which is either a string or an
+// array of strings, contains the provided audience
+func hasAudience(claim interface{}, audience string) bool {
+	switch aud := claim.(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, a := range aud {
+			if a == audience {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,109 @@
+package authentication
+
+import (
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeUsername(t *testing.T) {
+	// No claim configured
+	token := jwt.New(jwt.GetSigningMethod("RS256"))
+	token.Claims["username"] = "foo"
+	token.Claims["sub"] = "bar"
+	normalizeUsername(token, "")
+	assert.Equal(t, "foo", token.Claims["username"])
+
+	// The username claim itself is configured
+	normalizeUsername(token, "username")
+	assert.Equal(t, "foo", token.Claims["username"])
+
+	// preferred_username claim
+	token = jwt.New(jwt.GetSigningMethod("RS256"))
+	token.Claims["preferred_username"] = "foo"
+	normalizeUsername(token, "preferred_username")
+	assert.Equal(t, "foo", token.Claims["username"])
+
+	// email claim takes precedence over an existing username claim
+	token = jwt.New(jwt.GetSigningMethod("RS256"))
+	token.Claims["username"] = "bar"
+	token.Claims["email"] = "foo@example.com"
+	normalizeUsername(token, "email")
+	assert.Equal(t, "foo@example.com", token.Claims["username"])
+
+	// Missing claim
+	token = jwt.New(jwt.GetSigningMethod("RS256"))
+	token.Claims["username"] = "bar"
+	normalizeUsername(token, "sub")
+	assert.Equal(t, "bar", token.Claims["username"])
+
+	// Claim that is not a string
+	token = jwt.New(jwt.GetSigningMethod("RS256"))
+	token.Claims["sub"] = 1234
+	normalizeUsername(token, "sub")
+	_, ok := token.Claims["username"]
+	assert.False(t, ok)
+}
+
+func TestGetTokenUsernameClaim(t *testing.T) {
+	privateKey, publicKey = generateToken()
+	usernameClaim = "sub"
+	defer func() { usernameClaim = "" }()
+
+	tokenString, err := GetToken(&User{Username: "foo"}, "xsrf")
+	assert.Nil(t, err)
+
+	token, err := verifyJWT(tokenString)
+	assert.Nil(t, err)
+	assert.Equal(t, "foo", token.Claims["sub"])
+	assert.Equal(t, "foo", token.Claims["username"])
+}
+
+func TestValidateClaims(t *testing.T) {
+	token := jwt.New(jwt.GetSigningMethod("RS256"))
+
+	// Nothing configured
+	assert.Nil(t, validateClaims(token, "", ""))
+
+	// Missing claims
+	assert.NotNil(t, validateClaims(token, "uchiwa", ""))
+	assert.NotNil(t, validateClaims(token, "", "https://sso.example.com"))
+
+	// Matching claims
+	token.Claims["aud"] = "uchiwa"
+	token.Claims["iss"] = "https://sso.example.com"
+	assert.Nil(t, validateClaims(token, "uchiwa", "https://sso.example.com"))
+
+	// Audience as an array
+	token.Claims["aud"] = []interface{}{"grafana", "uchiwa"}
+	assert.Nil(t, validateClaims(token, "uchiwa", ""))
+
+	// Mismatches
+	assert.NotNil(t, validateClaims(token, "kibana", ""))
+	assert.NotNil(t, validateClaims(token, "", "https://other.example.com"))
+}
+
+func TestGetTokenAudienceIssuer(t *testing.T) {
+	privateKey, publicKey = generateToken()
+	expectedAudience = "uchiwa"
+	expectedIssuer = "https://uchiwa.example.com"
+
+	tokenString, err := GetToken(&User{Username: "foo"}, "xsrf")
+	assert.Nil(t, err)
+
+	token, err := verifyJWT(tokenString)
+	assert.Nil(t, err)
+	assert.Equal(t, "uchiwa", token.Claims["aud"])
+	assert.Equal(t, "https://uchiwa.example.com", token.Claims["iss"])
+
+	// A token issued for another audience is rejected
+	expectedAudience = "grafana"
+	defer func() {
+		expectedAudience = ""
+		expectedIssuer = ""
+	}()
+
+	_, err = verifyJWT(tokenString)
+	assert.NotNil(t, err)
+}
@@ -20,6 +20,7 @@ type Config struct {
 // Role contains the attributes of a role
 type Role struct {
 	AccessToken   string
+	Admin         bool
 	Datacenters   []string
 	Fallback      bool
 	Members       []string
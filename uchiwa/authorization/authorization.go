@@ -2,6 +2,7 @@ package authorization
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/sensu/uchiwa/uchiwa/authentication"
 	"github.com/sensu/uchiwa/uchiwa/logger"
@@ -10,18 +11,37 @@ import (
 // Authorization contains the different methods used for authorizing
 // requests made to the API
 type Authorization interface {
+	Authorized(*http.Request) bool
 	Handler(http.Handler) http.Handler
 }
 
+// exemptResources contains the session and meta endpoints, which are needed
+// by every user to load the dashboard or log out, whatever their resource
+// types. The configuration is deliberately not exempted
+var exemptResources = []string{"datacenters", "logout", "user"}
+
 // Uchiwa represents an instance of the Authorization interface for the community
-type Uchiwa struct{}
+type Uchiwa struct {
+	// Resources contains, for the role names listed, the only resource types
+	// their members have access to, e.g. events. The other roles have access
+	// to every resource type
+	Resources map[string][]string
+}
+
+// Authorized verifies if the user may perform the provided request
+func (u *Uchiwa) Authorized(r *http.Request) bool {
+	readonly := isReadOnly(r)
+	if !isAuthorized(readonly, r.Method) {
+		return false
+	}
+
+	return u.isResourceAllowed(r)
+}
 
 // Handler verifies if the user has access to the requested resource
 func (u *Uchiwa) Handler(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		readonly := isReadOnly(r)
-		authorized := isAuthorized(readonly, r.Method)
-		if !authorized {
+		if !u.Authorized(r) {
 			http.Error(w, "Request forbidden", http.StatusForbidden)
 			return
 		}
@@ -37,6 +57,46 @@ func isAuthorized(isReadOnly bool, method string) bool {
 	return true
 }
 
+// isResourceAllowed verifies if the role of the user has access to the type
+// of the requested resource, which is the first segment of the path. The
+// exempted resources are always allowed
+func (u *Uchiwa) isResourceAllowed(r *http.Request) bool {
+	if u == nil || len(u.Resources) == 0 {
+		return true
+	}
+
+	token := authentication.GetJWTFromContext(r)
+	if token == nil { // authentication is not enabled
+		return true
+	}
+
+	role, err := authentication.GetRoleFromToken(token)
+	if err != nil {
+		logger.Debugf("Invalid token: %s", err.Error())
+		return false
+	}
+
+	resources, ok := u.Resources[role.Name]
+	if !ok {
+		return true
+	}
+
+	resource := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)[0]
+	for _, exempt := range exemptResources {
+		if exempt == resource {
+			return true
+		}
+	}
+
+	for _, allowed := range resources {
+		if allowed == resource {
+			return true
+		}
+	}
+
+	return false
+}
+
 // hasReadOnly verifies if the user only has read-only access.
 // Returns true if the user only have read-only access
 func isReadOnly(r *http.Request) bool {
@@ -99,3 +99,45 @@ func TestIsReadOnly(t *testing.T) {
 	readonly = isReadOnly(r)
 	assert.False(t, readonly)
 }
+
+func TestIsResourceAllowed(t *testing.T) {
+	a := &Uchiwa{Resources: map[string][]string{"auditor": {"clients", "events"}}}
+
+	request := func(path string, role *authentication.Role) *http.Request {
+		r, _ := http.NewRequest("GET", path, nil)
+		if role != nil {
+			setJWTInContext(r, generateToken(*role))
+		}
+		return r
+	}
+
+	auditor := &authentication.Role{Name: "auditor"}
+	assert.True(t, a.isResourceAllowed(request("/events", auditor)))
+	assert.True(t, a.isResourceAllowed(request("/clients/foo/history", auditor)))
+	assert.False(t, a.isResourceAllowed(request("/stashes", auditor)))
+	assert.False(t, a.isResourceAllowed(request("/silenced", auditor)))
+
+	// The session and meta endpoints are exempted
+	assert.True(t, a.isResourceAllowed(request("/user", auditor)))
+	assert.True(t, a.isResourceAllowed(request("/logout", auditor)))
+	assert.True(t, a.isResourceAllowed(request("/datacenters", auditor)))
+
+	// But not the configuration
+	assert.False(t, a.isResourceAllowed(request("/config", auditor)))
+
+	// Unlisted roles & disabled authentication
+	assert.True(t, a.isResourceAllowed(request("/stashes", &authentication.Role{Name: "operator"})))
+	assert.True(t, a.isResourceAllowed(request("/stashes", nil)))
+
+	// No restriction configured
+	assert.True(t, u.isResourceAllowed(request("/stashes", auditor)))
+
+	// The handler rejects the disallowed resource types
+	w := httptest.NewRecorder()
+	a.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(w, request("/stashes", auditor))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = httptest.NewRecorder()
+	a.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(w, request("/config", auditor))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
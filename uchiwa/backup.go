@@ -0,0 +1,114 @@
+package uchiwa
+
+import (
+	"fmt"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// backupEntry holds the outcome of the restoration of a single entry
+type backupEntry struct {
+	Dc    string `json:"dc"`
+	ID    string `json:"id"`
+	Error string `json:"error,omitempty"`
+}
+
+// backupReport holds the outcome of a restoration
+type backupReport struct {
+	Conflicts []backupEntry `json:"conflicts"`
+	Created   []backupEntry `json:"created"`
+	Errors    []backupEntry `json:"errors"`
+}
+
+// failed returns whether at least one entry was not restored
+func (r backupReport) failed() bool {
+	return len(r.Conflicts) > 0 || len(r.Errors) > 0
+}
+
+func newBackupReport() backupReport {
+	return backupReport{
+		Conflicts: []backupEntry{},
+		Created:   []backupEntry{},
+		Errors:    []backupEntry{},
+	}
+}
+
+// silenceID returns the identifier Sensu gives to a silence entry
+func silenceID(s silence) string {
+	subscription, check := s.Subscription, s.Check
+	if subscription == "" {
+		subscription = "*"
+	}
+	if check == "" {
+		check = "*"
+	}
+	return fmt.Sprintf("%s:%s", subscription, check)
+}
+
+// restoreSilences re-creates the provided silence entries with the post
+// function, skipping the entries that already exist
+func restoreSilences(entries []silence, existing []interface{}, post func(silence) error) backupReport {
+	ids := make(map[string]bool)
+	for _, e := range existing {
+		var s silence
+		if err := mapstructure.Decode(e, &s); err != nil {
+			continue
+		}
+		ids[s.Dc+"/"+silenceID(s)] = true
+	}
+
+	report := newBackupReport()
+	for _, entry := range entries {
+		result := backupEntry{Dc: entry.Dc, ID: silenceID(entry)}
+
+		if ids[entry.Dc+"/"+result.ID] {
+			report.Conflicts = append(report.Conflicts, result)
+			continue
+		}
+
+		if err := post(entry); err != nil {
+			result.Error = err.Error()
+			report.Errors = append(report.Errors, result)
+			continue
+		}
+
+		ids[entry.Dc+"/"+result.ID] = true
+		report.Created = append(report.Created, result)
+	}
+
+	return report
+}
+
+// restoreStashes re-creates the provided stashes with the post function,
+// skipping the stashes that already exist
+func restoreStashes(entries []stash, existing []interface{}, post func(stash) error) backupReport {
+	paths := make(map[string]bool)
+	for _, e := range existing {
+		var s stash
+		if err := mapstructure.Decode(e, &s); err != nil {
+			continue
+		}
+		paths[s.Dc+"/"+s.Path] = true
+	}
+
+	report := newBackupReport()
+	for _, entry := range entries {
+		result := backupEntry{Dc: entry.Dc, ID: entry.Path}
+
+		if paths[entry.Dc+"/"+entry.Path] {
+			report.Conflicts = append(report.Conflicts, result)
+			continue
+		}
+
+		if err := post(entry); err != nil {
+			result.Error = err.Error()
+			report.Errors = append(report.Errors, result)
+			continue
+		}
+
+		paths[entry.Dc+"/"+entry.Path] = true
+		report.Created = append(report.Created, result)
+	}
+
+	return report
+}
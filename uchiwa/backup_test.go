@@ -0,0 +1,105 @@
+package uchiwa
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sensu/uchiwa/uchiwa/audit"
+	"github.com/sensu/uchiwa/uchiwa/config"
+	"github.com/sensu/uchiwa/uchiwa/sensu"
+	"github.com/sensu/uchiwa/uchiwa/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestoreSilences(t *testing.T) {
+	existing := []interface{}{
+		map[string]interface{}{"dc": "us-east-1", "id": "linux:*", "subscription": "linux"},
+	}
+	entries := []silence{
+		silence{Dc: "us-east-1", Subscription: "linux"},
+		silence{Dc: "us-west-1", Subscription: "linux"},
+		silence{Dc: "us-east-1", Check: "cpu"},
+		silence{Dc: "us-east-1", Check: "disk"},
+		silence{Dc: "us-east-1", Check: "cpu"},
+	}
+
+	var posted []silence
+	post := func(s silence) error {
+		if s.Check == "disk" {
+			return errors.New("Connection refused")
+		}
+		posted = append(posted, s)
+		return nil
+	}
+
+	report := restoreSilences(entries, existing, post)
+	assert.Equal(t, 2, len(posted))
+	assert.Equal(t, []backupEntry{{Dc: "us-west-1", ID: "linux:*"}, {Dc: "us-east-1", ID: "*:cpu"}}, report.Created)
+	assert.Equal(t, []backupEntry{{Dc: "us-east-1", ID: "linux:*"}, {Dc: "us-east-1", ID: "*:cpu"}}, report.Conflicts)
+	assert.Equal(t, []backupEntry{{Dc: "us-east-1", ID: "*:disk", Error: "Connection refused"}}, report.Errors)
+	assert.True(t, report.failed())
+}
+
+func TestRestoreStashes(t *testing.T) {
+	existing := []interface{}{
+		map[string]interface{}{"dc": "us-east-1", "path": "silence/foo"},
+	}
+	entries := []stash{
+		stash{Dc: "us-east-1", Path: "silence/foo"},
+		stash{Dc: "us-east-1", Path: "silence/bar"},
+	}
+
+	post := func(s stash) error { return nil }
+
+	report := restoreStashes(entries, existing, post)
+	assert.Equal(t, []backupEntry{{Dc: "us-east-1", ID: "silence/bar"}}, report.Created)
+	assert.Equal(t, []backupEntry{{Dc: "us-east-1", ID: "silence/foo"}}, report.Conflicts)
+	assert.Equal(t, 0, len(report.Errors))
+
+	report = restoreStashes(entries[1:], nil, post)
+	assert.False(t, report.failed())
+}
+
+func TestBackupHandlerRestore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	var logs []structs.AuditLog
+	audit.Log = func(log structs.AuditLog) error {
+		logs = append(logs, log)
+		return nil
+	}
+	defer func() { audit.Log = nil }()
+
+	api := sensu.API{URL: server.URL, Timeout: 1}
+	api.Init()
+	u := &Uchiwa{
+		Config:      &config.Config{},
+		Data:        &structs.Data{},
+		Datacenters: &[]sensu.Sensu{{Name: "us-east-1", APIs: []sensu.API{api}}},
+		Mu:          &sync.Mutex{},
+	}
+
+	r, _ := http.NewRequest("POST", "/backup/stashes", strings.NewReader(`[{"dc":"us-east-1","path":"silence/foo"}]`))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	u.backupHandler(w, r)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var report backupReport
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, 1, len(report.Created))
+
+	// Every restored entry is audited
+	assert.Equal(t, 1, len(logs))
+	assert.Equal(t, "restore", logs[0].Action)
+	assert.Equal(t, "/stashes/silence/foo?dc=us-east-1", logs[0].URL)
+	assert.Equal(t, []structs.AuditResource{{Type: "stash", Name: "silence/foo", Dc: "us-east-1"}}, logs[0].Resources)
+}
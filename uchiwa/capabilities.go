@@ -0,0 +1,48 @@
+package uchiwa
+
+import (
+	"net/http"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gorilla/context"
+	"github.com/sensu/uchiwa/uchiwa/authentication"
+)
+
+// capabilities holds the actions a user may perform, so the frontend can hide
+// the ones that would be forbidden
+type capabilities struct {
+	CanCreateSilences bool `json:"canCreateSilences"`
+	CanDeleteClients  bool `json:"canDeleteClients"`
+	CanDeleteStashes  bool `json:"canDeleteStashes"`
+	CanExecuteChecks  bool `json:"canExecuteChecks"`
+	CanResolveEvents  bool `json:"canResolveEvents"`
+}
+
+// isRequestAuthorized reports whether the authorization middleware would let
+// the user of the provided token perform a request with the provided method
+// and path
+func isRequestAuthorized(token *jwt.Token, method, path string) bool {
+	r, err := http.NewRequest(method, path, nil)
+	if err != nil {
+		return false
+	}
+
+	if token != nil {
+		context.Set(r, authentication.JWTToken, token)
+		defer context.Clear(r)
+	}
+
+	return Authorization.Authorized(r)
+}
+
+// buildCapabilities returns the actions the user of the provided token may
+// perform, according to the authorization rules
+func buildCapabilities(token *jwt.Token) capabilities {
+	return capabilities{
+		CanCreateSilences: isRequestAuthorized(token, http.MethodPost, "/silenced"),
+		CanDeleteClients:  isRequestAuthorized(token, http.MethodDelete, "/clients/client"),
+		CanDeleteStashes:  isRequestAuthorized(token, http.MethodDelete, "/stashes/path"),
+		CanExecuteChecks:  isRequestAuthorized(token, http.MethodPost, "/request"),
+		CanResolveEvents:  isRequestAuthorized(token, http.MethodDelete, "/events/client/check"),
+	}
+}
@@ -0,0 +1,34 @@
+package uchiwa
+
+import (
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/sensu/uchiwa/uchiwa/authentication"
+	"github.com/sensu/uchiwa/uchiwa/authorization"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildCapabilities(t *testing.T) {
+	Authorization = &authorization.Uchiwa{Resources: map[string][]string{"auditor": {"events", "silenced"}}}
+	defer func() { Authorization = &authorization.Uchiwa{} }()
+
+	token := func(role authentication.Role) *jwt.Token {
+		token := jwt.New(jwt.GetSigningMethod("RS256"))
+		token.Claims["role"] = role
+		return token
+	}
+
+	// Authentication disabled
+	all := capabilities{true, true, true, true, true}
+	assert.Equal(t, all, buildCapabilities(nil))
+
+	// Read-write user
+	assert.Equal(t, all, buildCapabilities(token(authentication.Role{Name: "operator"})))
+
+	// Read-only user
+	assert.Equal(t, capabilities{}, buildCapabilities(token(authentication.Role{Name: "operator", Readonly: true})))
+
+	// Role restricted to some resource types
+	assert.Equal(t, capabilities{CanCreateSilences: true, CanResolveEvents: true}, buildCapabilities(token(authentication.Role{Name: "auditor"})))
+}
@@ -2,12 +2,192 @@ package uchiwa
 
 import (
 	"fmt"
+	"math"
+	"path"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/mitchellh/mapstructure"
 	"github.com/sensu/uchiwa/uchiwa/helpers"
 	"github.com/sensu/uchiwa/uchiwa/logger"
 	"github.com/sensu/uchiwa/uchiwa/structs"
 )
 
+// checkStats holds the statistics of the recent executions of a check
+type checkStats struct {
+	Check           string         `json:"check"`
+	Dc              string         `json:"dc"`
+	Window          int            `json:"window"`
+	Samples         int            `json:"samples"`
+	AverageDuration float64        `json:"average_duration"`
+	P95Duration     float64        `json:"p95_duration"`
+	Statuses        map[string]int `json:"statuses"`
+}
+
+// checkResult holds the current result of a check on a client
+type checkResult struct {
+	Client string `json:"client"`
+	Dc     string `json:"dc"`
+	Status int    `json:"status"`
+	Output string `json:"output,omitempty"`
+}
+
+// checkStatuses contains the names of the check statuses
+var checkStatuses = []string{"ok", "warning", "critical", "unknown"}
+
+// checkStatusName returns the name of the provided check status. Every status
+// above 2 is unknown
+func checkStatusName(status int) string {
+	if status >= 0 && status < len(checkStatuses) {
+		return checkStatuses[status]
+	}
+	return "unknown"
+}
+
+// parseCheckStatuses parses the provided comma-separated status names
+func parseCheckStatuses(s string) (map[string]bool, error) {
+	statuses := make(map[string]bool)
+	for _, status := range strings.Split(s, ",") {
+		status = strings.TrimSpace(status)
+		if !helpers.IsStringInArray(status, checkStatuses) {
+			return nil, fmt.Errorf("Invalid status '%s'", status)
+		}
+		statuses[status] = true
+	}
+
+	return statuses, nil
+}
+
+// buildCheckResults returns the current result of the provided check on the
+// clients of the datacenter, sorted by client name. The clients subscribed to
+// the check are ok unless they have an event for it. Only the results matching
+// any of the provided statuses are returned, or every result without any
+func buildCheckResults(name, dc string, checks, clients, events []interface{}, statuses map[string]bool) []checkResult {
+	var subscribers []string
+	for _, c := range checks {
+		check, ok := c.(map[string]interface{})
+		if !ok || check["name"] != name || check["dc"] != dc {
+			continue
+		}
+
+		var definition structs.GenericCheck
+		if err := mapstructure.Decode(check, &definition); err == nil {
+			subscribers = append(subscribers, definition.Subscribers...)
+		}
+	}
+
+	results := make(map[string]checkResult)
+	for _, c := range clients {
+		var client structs.GenericClient
+		if err := mapstructure.Decode(c, &client); err != nil || client.Dc != dc {
+			continue
+		}
+
+		subscriptions := append([]string{"client:" + client.Name}, client.Subscriptions...)
+		for _, subscription := range subscriptions {
+			if helpers.IsStringInArray(subscription, subscribers) {
+				results[client.Name] = checkResult{Client: client.Name, Dc: dc}
+				break
+			}
+		}
+	}
+
+	// The events also cover the standalone checks and the proxy clients
+	for _, e := range events {
+		var event structs.GenericEvent
+		if err := mapstructure.Decode(e, &event); err != nil || event.Dc != dc {
+			continue
+		}
+
+		m, _ := e.(map[string]interface{})
+		check, _ := m["check"].(map[string]interface{})
+		if check["name"] != name {
+			continue
+		}
+
+		results[event.Client.Name] = checkResult{
+			Client: event.Client.Name,
+			Dc:     dc,
+			Status: event.Check.Status,
+			Output: event.Check.Output,
+		}
+	}
+
+	filtered := []checkResult{}
+	for _, result := range results {
+		if len(statuses) > 0 && !statuses[checkStatusName(result.Status)] {
+			continue
+		}
+		filtered = append(filtered, result)
+	}
+
+	sort.Slice(filtered, func(i, j int) bool {
+		return filtered[i].Client < filtered[j].Client
+	})
+
+	return filtered
+}
+
+// buildCheckStats computes the average and 95th percentile durations, and
+// the number of executions by status, of the samples executed within the
+// last window seconds
+func buildCheckStats(samples []structs.CheckSample, window int, now time.Time) checkStats {
+	stats := checkStats{
+		Window:   window,
+		Statuses: map[string]int{"critical": 0, "ok": 0, "unknown": 0, "warning": 0},
+	}
+
+	since := now.Unix() - int64(window)
+	var durations []float64
+	var total float64
+	for _, sample := range samples {
+		if window > 0 && sample.Executed < since {
+			continue
+		}
+
+		durations = append(durations, sample.Duration)
+		total += sample.Duration
+
+		switch sample.Status {
+		case 0:
+			stats.Statuses["ok"]++
+		case 1:
+			stats.Statuses["warning"]++
+		case 2:
+			stats.Statuses["critical"]++
+		default:
+			stats.Statuses["unknown"]++
+		}
+	}
+
+	stats.Samples = len(durations)
+	if stats.Samples == 0 {
+		return stats
+	}
+
+	sort.Float64s(durations)
+	stats.P95Duration = percentile(durations, 0.95)
+	stats.AverageDuration = total / float64(len(durations))
+
+	return stats
+}
+
+// percentile returns the provided percentile, between 0 and 1, of the sorted
+// values, using the nearest-rank method
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+
+	return sorted[rank]
+}
+
 // GetCheck retrieves a specific check
 func (u *Uchiwa) GetCheck(dc, name string) (map[string]interface{}, error) {
 	api, err := getAPI(u.Datacenters, dc)
@@ -23,7 +203,7 @@ func (u *Uchiwa) GetCheck(dc, name string) (map[string]interface{}, error) {
 	}
 
 	// lock results
-	u.Mu.Lock()
+	u.lockData()
 	defer u.Mu.Unlock()
 	check["dc"] = dc
 	check["silenced"], check["silenced_by"] = helpers.IsCheckSilenced(check, nil, dc, u.Data.Silenced)
@@ -48,6 +228,23 @@ func (u *Uchiwa) IssueCheckExecution(data structs.CheckExecution) error {
 	return nil
 }
 
+// isAdhocCheckAllowed returns true if the provided check can be executed on
+// demand, i.e. if it matches one of the allowed glob patterns. Every check is
+// allowed when no pattern is provided
+func isAdhocCheckAllowed(check string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	for _, pattern := range allowed {
+		if matched, err := path.Match(pattern, check); err == nil && matched {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (u *Uchiwa) findCheck(name string) ([]interface{}, error) {
 	var checks []interface{}
 	for _, c := range u.Data.Checks {
@@ -2,6 +2,7 @@ package uchiwa
 
 import (
 	"testing"
+	"time"
 
 	"github.com/sensu/uchiwa/uchiwa/structs"
 	"github.com/stretchr/testify/assert"
@@ -29,3 +30,99 @@ func TestFindCheck(t *testing.T) {
 	_, err = u.findCheck("qux")
 	assert.NotNil(t, err)
 }
+
+func TestBuildCheckStats(t *testing.T) {
+	now := time.Unix(10000, 0)
+
+	// No samples
+	stats := buildCheckStats(nil, 3600, now)
+	assert.Equal(t, 0, stats.Samples)
+	assert.Equal(t, 0.0, stats.AverageDuration)
+	assert.Equal(t, 0, stats.Statuses["ok"])
+
+	var samples []structs.CheckSample
+	for i := 1; i <= 20; i++ {
+		samples = append(samples, structs.CheckSample{Client: "foo", Duration: float64(i), Executed: int64(9000 + i), Status: 0})
+	}
+	samples[18].Status = 1
+	samples[19].Status = 2
+	// Outside of the window
+	samples = append(samples, structs.CheckSample{Client: "foo", Duration: 100, Executed: 1000, Status: 3})
+
+	stats = buildCheckStats(samples, 3600, now)
+	assert.Equal(t, 3600, stats.Window)
+	assert.Equal(t, 20, stats.Samples)
+	assert.Equal(t, 10.5, stats.AverageDuration)
+	assert.Equal(t, 19.0, stats.P95Duration)
+	assert.Equal(t, 18, stats.Statuses["ok"])
+	assert.Equal(t, 1, stats.Statuses["warning"])
+	assert.Equal(t, 1, stats.Statuses["critical"])
+	assert.Equal(t, 0, stats.Statuses["unknown"])
+
+	// Without a window, every sample is used
+	stats = buildCheckStats(samples, 0, now)
+	assert.Equal(t, 21, stats.Samples)
+	assert.Equal(t, 1, stats.Statuses["unknown"])
+}
+
+func TestIsAdhocCheckAllowed(t *testing.T) {
+	// Every check is allowed without any pattern
+	assert.True(t, isAdhocCheckAllowed("cpu", nil))
+
+	allowed := []string{"disk_*", "memory"}
+	assert.True(t, isAdhocCheckAllowed("disk_usage", allowed))
+	assert.True(t, isAdhocCheckAllowed("memory", allowed))
+	assert.False(t, isAdhocCheckAllowed("cpu", allowed))
+	assert.False(t, isAdhocCheckAllowed("memory_swap", allowed))
+
+	// An invalid pattern never matches
+	assert.False(t, isAdhocCheckAllowed("[", []string{"["}))
+}
+
+func TestParseCheckStatuses(t *testing.T) {
+	statuses, err := parseCheckStatuses("critical, warning")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]bool{"critical": true, "warning": true}, statuses)
+
+	_, err = parseCheckStatuses("critical,foo")
+	assert.NotNil(t, err)
+}
+
+func TestBuildCheckResults(t *testing.T) {
+	checks := []interface{}{
+		map[string]interface{}{"name": "disk", "dc": "us-east-1", "subscribers": []interface{}{"linux"}},
+		map[string]interface{}{"name": "disk", "dc": "us-west-1", "subscribers": []interface{}{"windows"}},
+	}
+	clients := []interface{}{
+		map[string]interface{}{"name": "foo", "dc": "us-east-1", "subscriptions": []interface{}{"linux"}},
+		map[string]interface{}{"name": "bar", "dc": "us-east-1", "subscriptions": []interface{}{"linux"}},
+		map[string]interface{}{"name": "baz", "dc": "us-east-1", "subscriptions": []interface{}{"windows"}},
+		map[string]interface{}{"name": "qux", "dc": "us-east-1", "subscriptions": []interface{}{"linux"}},
+		map[string]interface{}{"name": "foo", "dc": "us-west-1", "subscriptions": []interface{}{"windows"}},
+	}
+	events := []interface{}{
+		map[string]interface{}{"dc": "us-east-1", "client": map[string]interface{}{"name": "foo"}, "check": map[string]interface{}{"name": "disk", "status": 2.0, "output": "disk full"}},
+		map[string]interface{}{"dc": "us-east-1", "client": map[string]interface{}{"name": "qux"}, "check": map[string]interface{}{"name": "disk", "status": 1.0}},
+		map[string]interface{}{"dc": "us-east-1", "client": map[string]interface{}{"name": "proxy"}, "check": map[string]interface{}{"name": "disk", "status": 2.0}},
+		map[string]interface{}{"dc": "us-east-1", "client": map[string]interface{}{"name": "bar"}, "check": map[string]interface{}{"name": "cpu", "status": 2.0}},
+		map[string]interface{}{"dc": "us-west-1", "client": map[string]interface{}{"name": "foo"}, "check": map[string]interface{}{"name": "disk", "status": 2.0}},
+	}
+
+	results := buildCheckResults("disk", "us-east-1", checks, clients, events, nil)
+	assert.Equal(t, []checkResult{
+		{Client: "bar", Dc: "us-east-1", Status: 0},
+		{Client: "foo", Dc: "us-east-1", Status: 2, Output: "disk full"},
+		{Client: "proxy", Dc: "us-east-1", Status: 2},
+		{Client: "qux", Dc: "us-east-1", Status: 1},
+	}, results)
+
+	// Any of the statuses
+	results = buildCheckResults("disk", "us-east-1", checks, clients, events, map[string]bool{"critical": true, "ok": true})
+	assert.Equal(t, 3, len(results))
+	assert.Equal(t, "bar", results[0].Client)
+	assert.Equal(t, "proxy", results[2].Client)
+
+	// No result
+	results = buildCheckResults("memory", "us-east-1", checks, clients, events, nil)
+	assert.Equal(t, []checkResult{}, results)
+}
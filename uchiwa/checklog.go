@@ -0,0 +1,152 @@
+package uchiwa
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/sensu/uchiwa/uchiwa/structs"
+)
+
+// checkChangesLimit is the number of check definition changes remembered
+const checkChangesLimit = 1000
+
+// Kinds of check definition changes
+const (
+	checkAdded   = "added"
+	checkChanged = "changed"
+	checkRemoved = "removed"
+)
+
+// checkDefinition holds a check definition along with its hash
+type checkDefinition struct {
+	hash       string
+	definition map[string]interface{}
+}
+
+// checkChange holds a change of a check definition, with the definition
+// before and after the change
+type checkChange struct {
+	Check   string                 `json:"check"`
+	Dc      string                 `json:"dc"`
+	Change  string                 `json:"change"`
+	Time    int64                  `json:"time"`
+	OldHash string                 `json:"old_hash,omitempty"`
+	NewHash string                 `json:"new_hash,omitempty"`
+	Old     map[string]interface{} `json:"old,omitempty"`
+	New     map[string]interface{} `json:"new,omitempty"`
+}
+
+// checkLog keeps track of the changes of the check definitions across the
+// polls. It must be accessed with the mutex held
+type checkLog struct {
+	definitions map[string]checkDefinition
+	changes     []checkChange
+}
+
+// hashCheck returns the hash of a check definition. The keys of the
+// definition are sorted when encoded, so the hash is stable
+func hashCheck(check map[string]interface{}) string {
+	b, err := json.Marshal(check)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// diffCheckDefinitions compares the provided checks to the previous
+// definitions, keyed by datacenter and check name. The previous definitions of
+// the datacenters that could not be polled are kept as is. It returns the
+// current definitions and the changes, sorted by datacenter and check
+func diffCheckDefinitions(previous map[string]checkDefinition, checks []interface{}, polled map[string]bool, now time.Time) (map[string]checkDefinition, []checkChange) {
+	current := make(map[string]checkDefinition, len(checks))
+	changes := []checkChange{}
+
+	for _, c := range checks {
+		check, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name, _ := check["name"].(string)
+		dc, _ := check["dc"].(string)
+		if name == "" {
+			continue
+		}
+
+		key := dc + "/" + name
+		definition := checkDefinition{hash: hashCheck(check), definition: check}
+		current[key] = definition
+
+		old, ok := previous[key]
+		if !ok {
+			changes = append(changes, checkChange{Check: name, Dc: dc, Change: checkAdded, Time: now.Unix(), NewHash: definition.hash, New: check})
+		} else if old.hash != definition.hash {
+			changes = append(changes, checkChange{Check: name, Dc: dc, Change: checkChanged, Time: now.Unix(), OldHash: old.hash, NewHash: definition.hash, Old: old.definition, New: check})
+		}
+	}
+
+	for key, old := range previous {
+		if _, ok := current[key]; ok {
+			continue
+		}
+
+		name, _ := old.definition["name"].(string)
+		dc, _ := old.definition["dc"].(string)
+		if !polled[dc] {
+			current[key] = old
+			continue
+		}
+
+		changes = append(changes, checkChange{Check: name, Dc: dc, Change: checkRemoved, Time: now.Unix(), OldHash: old.hash, Old: old.definition})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Dc != changes[j].Dc {
+			return changes[i].Dc < changes[j].Dc
+		}
+		return changes[i].Check < changes[j].Check
+	})
+
+	return current, changes
+}
+
+// update records the check definitions of the provided data. The definitions
+// found on the first successful poll are not reported as added
+func (l *checkLog) update(data *structs.Data, now time.Time) {
+	if len(data.Dc) == 0 {
+		return
+	}
+
+	polled := make(map[string]bool, len(data.Dc))
+	for _, dc := range data.Dc {
+		polled[dc.Name] = true
+	}
+
+	current, changes := diffCheckDefinitions(l.definitions, data.Checks, polled, now)
+
+	if l.definitions != nil {
+		l.changes = append(l.changes, changes...)
+		if extra := len(l.changes) - checkChangesLimit; extra > 0 {
+			l.changes = append([]checkChange(nil), l.changes[extra:]...)
+		}
+	}
+
+	l.definitions = current
+}
+
+// since returns the changes recorded since the provided time
+func (l *checkLog) since(t time.Time) []checkChange {
+	changes := []checkChange{}
+	for _, change := range l.changes {
+		if change.Time >= t.Unix() {
+			changes = append(changes, change)
+		}
+	}
+
+	return changes
+}
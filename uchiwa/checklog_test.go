@@ -0,0 +1,77 @@
+package uchiwa
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sensu/uchiwa/uchiwa/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffCheckDefinitions(t *testing.T) {
+	now := time.Unix(1000, 0)
+	cpu := map[string]interface{}{"name": "cpu", "dc": "us-east-1", "command": "cpu.rb", "interval": float64(60)}
+	disk := map[string]interface{}{"name": "disk", "dc": "us-east-1", "command": "disk.rb"}
+	polled := map[string]bool{"us-east-1": true, "us-west-1": true}
+
+	previous, changes := diffCheckDefinitions(nil, []interface{}{cpu, disk, "foo", map[string]interface{}{"dc": "us-east-1"}}, polled, now)
+	assert.Equal(t, 2, len(previous))
+	assert.Equal(t, 2, len(changes))
+	assert.Equal(t, checkAdded, changes[0].Change)
+	assert.Equal(t, "cpu", changes[0].Check)
+
+	// Same definitions, with the keys in another order
+	cpu2 := map[string]interface{}{"interval": float64(60), "command": "cpu.rb", "dc": "us-east-1", "name": "cpu"}
+	current, changes := diffCheckDefinitions(previous, []interface{}{cpu2, disk}, polled, now)
+	assert.Equal(t, []checkChange{}, changes)
+
+	// cpu changed, disk removed, mem added
+	cpu3 := map[string]interface{}{"name": "cpu", "dc": "us-east-1", "command": "cpu.rb", "interval": float64(30)}
+	mem := map[string]interface{}{"name": "mem", "dc": "us-west-1", "command": "mem.rb"}
+	next, changes := diffCheckDefinitions(current, []interface{}{cpu3, mem}, polled, now)
+	assert.Equal(t, 3, len(changes))
+
+	assert.Equal(t, checkChanged, changes[0].Change)
+	assert.Equal(t, cpu2, changes[0].Old)
+	assert.Equal(t, cpu3, changes[0].New)
+	assert.Equal(t, hashCheck(cpu), changes[0].OldHash)
+	assert.NotEqual(t, changes[0].OldHash, changes[0].NewHash)
+
+	assert.Equal(t, checkRemoved, changes[1].Change)
+	assert.Equal(t, "disk", changes[1].Check)
+	assert.Nil(t, changes[1].New)
+
+	assert.Equal(t, checkAdded, changes[2].Change)
+	assert.Equal(t, "us-west-1", changes[2].Dc)
+
+	// The checks of the datacenters that could not be polled are kept
+	current, changes = diffCheckDefinitions(next, []interface{}{cpu3}, map[string]bool{"us-east-1": true}, now)
+	assert.Equal(t, []checkChange{}, changes)
+	assert.Equal(t, 2, len(current))
+}
+
+func TestCheckLog(t *testing.T) {
+	l := &checkLog{}
+	dc := []*structs.Datacenter{{Name: "us-east-1"}}
+	cpu := map[string]interface{}{"name": "cpu", "dc": "us-east-1", "interval": float64(60)}
+
+	// The initial definitions are not reported
+	l.update(&structs.Data{Dc: dc, Checks: []interface{}{cpu}}, time.Unix(100, 0))
+	assert.Equal(t, []checkChange{}, l.since(time.Unix(0, 0)))
+
+	l.update(&structs.Data{Dc: dc, Checks: []interface{}{map[string]interface{}{"name": "cpu", "dc": "us-east-1", "interval": float64(30)}}}, time.Unix(200, 0))
+	l.update(&structs.Data{Dc: dc}, time.Unix(300, 0))
+
+	assert.Equal(t, 2, len(l.since(time.Unix(0, 0))))
+	changes := l.since(time.Unix(300, 0))
+	assert.Equal(t, 1, len(changes))
+	assert.Equal(t, checkRemoved, changes[0].Change)
+
+	// The retention is bounded
+	for i := 0; i < checkChangesLimit; i++ {
+		l.update(&structs.Data{Dc: dc, Checks: []interface{}{map[string]interface{}{"name": fmt.Sprintf("check-%d", i), "dc": "us-east-1"}}}, time.Unix(400, 0))
+	}
+	assert.Equal(t, checkChangesLimit, len(l.changes))
+	assert.Equal(t, 0, len(l.since(time.Unix(0, 0)))-len(l.since(time.Unix(400, 0))))
+}
@@ -0,0 +1,115 @@
+package uchiwa
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sensu/uchiwa/uchiwa/structs"
+)
+
+// checkRequestsLimit is the number of check execution requests remembered
+const checkRequestsLimit = 1000
+
+// Statuses of a check execution request
+const (
+	checkRequestCompleted = "completed"
+	checkRequestFailed    = "failed"
+	checkRequestPending   = "pending"
+)
+
+// checkRequest holds a check execution request issued through the /request
+// endpoint and its status
+type checkRequest struct {
+	ID          string   `json:"id"`
+	Check       string   `json:"check"`
+	Dc          string   `json:"dc"`
+	Subscribers []string `json:"subscribers"`
+	Issued      int64    `json:"issued"`
+	Status      string   `json:"status"`
+}
+
+// checkRequestLog keeps track of the check execution requests until they
+// expire. It must be accessed with the mutex held
+type checkRequestLog struct {
+	count    uint64
+	requests []checkRequest
+}
+
+// add records a check execution request, as failed if it could not be issued
+func (l *checkRequestLog) add(data structs.CheckExecution, err error, now time.Time) checkRequest {
+	l.count++
+
+	request := checkRequest{
+		ID:          fmt.Sprintf("%d", l.count),
+		Check:       data.Check,
+		Dc:          data.Dc,
+		Subscribers: data.Subscribers,
+		Issued:      now.Unix(),
+		Status:      checkRequestPending,
+	}
+	if err != nil {
+		request.Status = checkRequestFailed
+	}
+
+	l.requests = append(l.requests, request)
+	if extra := len(l.requests) - checkRequestsLimit; extra > 0 {
+		l.requests = append([]checkRequest(nil), l.requests[extra:]...)
+	}
+
+	return request
+}
+
+// checkSamples returns the number of executions of each check to sample. The
+// completion of the pending requests is derived from the samples, so the most
+// recent execution of each check is sampled when the pending requests feature
+// is enabled, even without the check statistics
+func (u *Uchiwa) checkSamples() int {
+	if u.Config.Uchiwa.CheckStats.Samples <= 0 && u.features()["pendingRequests"] {
+		return 1
+	}
+
+	return u.Config.Uchiwa.CheckStats.Samples
+}
+
+// update marks as completed the pending requests for which an execution of the
+// check was recorded since they were issued, and drops the requests issued
+// more than ttl seconds ago
+func (l *checkRequestLog) update(samples map[string][]structs.CheckSample, now time.Time, ttl int) {
+	requests := l.requests[:0]
+	for _, request := range l.requests {
+		if ttl > 0 && now.Unix()-request.Issued > int64(ttl) {
+			continue
+		}
+
+		if request.Status == checkRequestPending {
+			for _, sample := range samples[fmt.Sprintf("%s/%s", request.Dc, request.Check)] {
+				if sample.Executed >= request.Issued {
+					request.Status = checkRequestCompleted
+					break
+				}
+			}
+		}
+
+		requests = append(requests, request)
+	}
+
+	l.requests = requests
+}
+
+// pending returns the requests that have not completed and were issued less
+// than ttl seconds ago
+func (l *checkRequestLog) pending(now time.Time, ttl int) []checkRequest {
+	requests := []checkRequest{}
+	for _, request := range l.requests {
+		if request.Status != checkRequestPending {
+			continue
+		}
+		if ttl > 0 && now.Unix()-request.Issued > int64(ttl) {
+			continue
+		}
+
+		requests = append(requests, request)
+	}
+
+	return requests
+}
@@ -0,0 +1,55 @@
+package uchiwa
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sensu/uchiwa/uchiwa/config"
+	"github.com/sensu/uchiwa/uchiwa/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckRequestLog(t *testing.T) {
+	l := &checkRequestLog{}
+	now := time.Unix(1000, 0)
+
+	foo := l.add(structs.CheckExecution{Check: "foo", Dc: "us-east-1"}, nil, now)
+	l.add(structs.CheckExecution{Check: "bar", Dc: "us-east-1"}, nil, now)
+	failed := l.add(structs.CheckExecution{Check: "baz", Dc: "us-east-1"}, errors.New("unreachable"), now)
+	assert.Equal(t, "1", foo.ID)
+	assert.Equal(t, checkRequestFailed, failed.Status)
+	assert.Equal(t, 2, len(l.pending(now, 600)))
+
+	// An execution of foo prior to the request does not complete it
+	samples := map[string][]structs.CheckSample{
+		"us-east-1/foo": {{Executed: 990}},
+	}
+	l.update(samples, now.Add(5*time.Second), 600)
+	assert.Equal(t, 2, len(l.pending(now, 600)))
+
+	samples["us-east-1/foo"] = append(samples["us-east-1/foo"], structs.CheckSample{Executed: 1004})
+	l.update(samples, now.Add(5*time.Second), 600)
+	pending := l.pending(now.Add(5*time.Second), 600)
+	assert.Equal(t, 1, len(pending))
+	assert.Equal(t, "bar", pending[0].Check)
+
+	// The requests age out after the TTL
+	assert.Equal(t, 0, len(l.pending(now.Add(601*time.Second), 600)))
+	l.update(samples, now.Add(601*time.Second), 600)
+	assert.Equal(t, 0, len(l.requests))
+}
+
+func TestCheckSamples(t *testing.T) {
+	u := &Uchiwa{Config: &config.Config{}}
+
+	// The pending requests are enabled by default
+	assert.Equal(t, 1, u.checkSamples())
+
+	u.Config.Uchiwa.CheckStats.Samples = 20
+	assert.Equal(t, 20, u.checkSamples())
+
+	u.Config.Uchiwa.CheckStats.Samples = 0
+	u.Config.Uchiwa.Features = map[string]bool{"pendingRequests": false}
+	assert.Equal(t, 0, u.checkSamples())
+}
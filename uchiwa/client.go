@@ -2,11 +2,170 @@ package uchiwa
 
 import (
 	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/sensu/uchiwa/uchiwa/helpers"
 	"github.com/sensu/uchiwa/uchiwa/logger"
 )
 
+// Default keepalive thresholds, in seconds, used by Sensu when a client does
+// not define its own
+const (
+	defaultKeepaliveCritical = 180
+	defaultKeepaliveWarning  = 120
+)
+
+// keepaliveThresholds holds the number of seconds without keepalive after
+// which a client is considered in a warning or critical state
+type keepaliveThresholds struct {
+	Critical int64 `json:"critical"`
+	Warning  int64 `json:"warning"`
+}
+
+// clientKeepalive holds the keepalive configuration and status of a client
+type clientKeepalive struct {
+	Client     string              `json:"client"`
+	Dc         string              `json:"dc"`
+	Enabled    bool                `json:"enabled"`
+	Handlers   []string            `json:"handlers"`
+	Last       int64               `json:"last"`
+	Age        int64               `json:"age"`
+	Status     int                 `json:"status"`
+	Thresholds keepaliveThresholds `json:"thresholds"`
+}
+
+// buildClientKeepalive computes the keepalive status of a client, based on
+// the number of seconds elapsed since its last keepalive and its thresholds
+func buildClientKeepalive(client map[string]interface{}, dc string, now time.Time) clientKeepalive {
+	keepalive := clientKeepalive{
+		Dc:       dc,
+		Enabled:  true,
+		Handlers: []string{"keepalive"},
+		Thresholds: keepaliveThresholds{
+			Critical: defaultKeepaliveCritical,
+			Warning:  defaultKeepaliveWarning,
+		},
+	}
+
+	keepalive.Client, _ = client["name"].(string)
+
+	if enabled, ok := client["keepalives"].(bool); ok {
+		keepalive.Enabled = enabled
+	}
+
+	if config, ok := client["keepalive"].(map[string]interface{}); ok {
+		if thresholds, ok := config["thresholds"].(map[string]interface{}); ok {
+			if critical, ok := thresholds["critical"].(float64); ok {
+				keepalive.Thresholds.Critical = int64(critical)
+			}
+			if warning, ok := thresholds["warning"].(float64); ok {
+				keepalive.Thresholds.Warning = int64(warning)
+			}
+		}
+
+		if handlers, ok := config["handlers"].([]interface{}); ok {
+			keepalive.Handlers = helpers.InterfaceToString(handlers)
+		} else if handler, ok := config["handler"].(string); ok {
+			keepalive.Handlers = []string{handler}
+		}
+	}
+
+	if timestamp, ok := client["timestamp"].(float64); ok {
+		keepalive.Last = int64(timestamp)
+		keepalive.Age = now.Unix() - keepalive.Last
+	}
+
+	if !keepalive.Enabled {
+		return keepalive
+	}
+
+	if keepalive.Age >= keepalive.Thresholds.Critical {
+		keepalive.Status = 2
+	} else if keepalive.Age >= keepalive.Thresholds.Warning {
+		keepalive.Status = 1
+	}
+
+	return keepalive
+}
+
+// attributeFilterPrefix is the prefix of the query parameters filtering the
+// clients on the value of one of their attributes, e.g. attribute:env=prod
+const attributeFilterPrefix = "attribute:"
+
+// staleFilter matches the clients that did, or did not, miss their keepalive
+// warning threshold
+func staleFilter(stale bool, now time.Time) elementFilter {
+	return func(client map[string]interface{}) bool {
+		dc, _ := client["dc"].(string)
+		keepalive := buildClientKeepalive(client, dc, now)
+		return (keepalive.Status != 0) == stale
+	}
+}
+
+// attributeFilter matches the clients for which the provided attribute is
+// equal to the value
+func attributeFilter(key, value string) elementFilter {
+	return func(client map[string]interface{}) bool {
+		attribute, ok := client[key]
+		if !ok || attribute == nil {
+			return false
+		}
+		return fmt.Sprint(attribute) == value
+	}
+}
+
+// nameFilter matches the clients whose name contains the term, ignoring case
+func nameFilter(term string) elementFilter {
+	term = strings.ToLower(term)
+	return func(client map[string]interface{}) bool {
+		name, _ := client["name"].(string)
+		return strings.Contains(strings.ToLower(name), term)
+	}
+}
+
+// parseClientFilters builds the client filters requested by the provided
+// query parameters, which are dc, subscription, status, stale, q and any
+// number of attribute:key=value. The values of the dc, subscription and
+// status parameters can be repeated, in order to match any of them
+func parseClientFilters(query url.Values, now time.Time) ([]elementFilter, error) {
+	filters, err := parseCommonFilters(query, []string{"subscriptions"}, []string{"status"})
+	if err != nil {
+		return nil, err
+	}
+
+	if s := query.Get("stale"); s != "" {
+		stale, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid stale parameter '%s'", s)
+		}
+		filters = append(filters, staleFilter(stale, now))
+	}
+
+	for param, values := range query {
+		if !strings.HasPrefix(param, attributeFilterPrefix) {
+			continue
+		}
+
+		key := strings.TrimPrefix(param, attributeFilterPrefix)
+		if key == "" {
+			return nil, fmt.Errorf("Invalid attribute parameter '%s'", param)
+		}
+
+		for _, value := range values {
+			filters = append(filters, attributeFilter(key, value))
+		}
+	}
+
+	if q := query.Get("q"); q != "" {
+		filters = append(filters, nameFilter(q))
+	}
+
+	return filters, nil
+}
+
 func (u *Uchiwa) buildClientHistory(client map[string]interface{}, dc string, history []interface{}) []interface{} {
 	for _, h := range history {
 		m, ok := h.(map[string]interface{})
@@ -82,7 +241,7 @@ func (u *Uchiwa) GetClient(dc, name string) (map[string]interface{}, error) {
 	}
 
 	// lock results
-	u.Mu.Lock()
+	u.lockData()
 	defer u.Mu.Unlock()
 
 	client["_id"] = fmt.Sprintf("%s/%s", dc, name)
@@ -114,7 +273,7 @@ func (u *Uchiwa) GetClientHistory(dc, name string) ([]interface{}, error) {
 	}
 
 	// lock results
-	u.Mu.Lock()
+	u.lockData()
 	defer u.Mu.Unlock()
 
 	history := u.buildClientHistory(client, dc, h)
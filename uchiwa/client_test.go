@@ -1,8 +1,19 @@
 package uchiwa
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/sensu/uchiwa/uchiwa/audit"
+	"github.com/sensu/uchiwa/uchiwa/config"
+	"github.com/sensu/uchiwa/uchiwa/filters"
+	"github.com/sensu/uchiwa/uchiwa/sensu"
 	"github.com/sensu/uchiwa/uchiwa/structs"
 	"github.com/stretchr/testify/assert"
 )
@@ -56,3 +67,159 @@ func TestFindClient(t *testing.T) {
 	_, err = u.findClient("qux")
 	assert.NotNil(t, err)
 }
+
+func TestBuildClientKeepalive(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	// Default thresholds
+	client := map[string]interface{}{"name": "foo", "timestamp": float64(950)}
+	keepalive := buildClientKeepalive(client, "us-east-1", now)
+	assert.Equal(t, "foo", keepalive.Client)
+	assert.Equal(t, "us-east-1", keepalive.Dc)
+	assert.Equal(t, true, keepalive.Enabled)
+	assert.Equal(t, []string{"keepalive"}, keepalive.Handlers)
+	assert.Equal(t, int64(950), keepalive.Last)
+	assert.Equal(t, int64(50), keepalive.Age)
+	assert.Equal(t, 0, keepalive.Status)
+	assert.Equal(t, int64(120), keepalive.Thresholds.Warning)
+	assert.Equal(t, int64(180), keepalive.Thresholds.Critical)
+
+	// Custom thresholds and handlers
+	client = map[string]interface{}{
+		"name":      "foo",
+		"timestamp": float64(950),
+		"keepalive": map[string]interface{}{
+			"handlers":   []interface{}{"pagerduty", "slack"},
+			"thresholds": map[string]interface{}{"warning": float64(30), "critical": float64(60)},
+		},
+	}
+	keepalive = buildClientKeepalive(client, "us-east-1", now)
+	assert.Equal(t, []string{"pagerduty", "slack"}, keepalive.Handlers)
+	assert.Equal(t, 1, keepalive.Status)
+
+	keepalive = buildClientKeepalive(client, "us-east-1", time.Unix(1010, 0))
+	assert.Equal(t, 2, keepalive.Status)
+
+	// Keepalives disabled
+	client["keepalives"] = false
+	keepalive = buildClientKeepalive(client, "us-east-1", time.Unix(1010, 0))
+	assert.Equal(t, false, keepalive.Enabled)
+	assert.Equal(t, 0, keepalive.Status)
+}
+
+func TestFilterClients(t *testing.T) {
+	now := time.Unix(1000, 0)
+	clients := []interface{}{
+		map[string]interface{}{"name": "web-01", "dc": "us-east-1", "env": "prod", "status": 0, "subscriptions": []interface{}{"web", "linux"}, "timestamp": float64(990)},
+		map[string]interface{}{"name": "web-02", "dc": "us-east-1", "env": "dev", "status": 2, "subscriptions": []interface{}{"web"}, "timestamp": float64(500)},
+		map[string]interface{}{"name": "db-01", "dc": "us-west-1", "env": "prod", "status": 2, "subscriptions": []interface{}{"db", "linux"}, "timestamp": float64(995)},
+		"foo",
+	}
+
+	filters, err := parseClientFilters(url.Values{}, now)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(filterElements(clients, filters)))
+
+	filters, err = parseClientFilters(url.Values{"subscription": {"linux"}}, now)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(filterElements(clients, filters)))
+
+	filters, err = parseClientFilters(url.Values{"subscription": {"linux"}, "status": {"2"}}, now)
+	assert.Nil(t, err)
+	result := filterElements(clients, filters)
+	assert.Equal(t, 1, len(result))
+	assert.Equal(t, "db-01", result[0].(map[string]interface{})["name"])
+
+	filters, err = parseClientFilters(url.Values{"stale": {"true"}}, now)
+	assert.Nil(t, err)
+	result = filterElements(clients, filters)
+	assert.Equal(t, 1, len(result))
+	assert.Equal(t, "web-02", result[0].(map[string]interface{})["name"])
+
+	filters, err = parseClientFilters(url.Values{"attribute:env": {"prod"}, "q": {"WEB"}}, now)
+	assert.Nil(t, err)
+	result = filterElements(clients, filters)
+	assert.Equal(t, 1, len(result))
+	assert.Equal(t, "web-01", result[0].(map[string]interface{})["name"])
+
+	filters, err = parseClientFilters(url.Values{"attribute:env": {"qa"}}, now)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(filterElements(clients, filters)))
+
+	// Several values of a parameter
+	filters, err = parseClientFilters(url.Values{"status": {"ok", "critical"}, "dc": {"us-east-1", "us-west-1"}}, now)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(filterElements(clients, filters)))
+
+	_, err = parseClientFilters(url.Values{"status": {"foo"}}, now)
+	assert.NotNil(t, err)
+
+	_, err = parseClientFilters(url.Values{"stale": {"foo"}}, now)
+	assert.NotNil(t, err)
+
+	_, err = parseClientFilters(url.Values{"attribute:": {"foo"}}, now)
+	assert.NotNil(t, err)
+}
+
+func TestClientHandlerDeleteReturn(t *testing.T) {
+	deleted := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			fmt.Fprint(w, `{"name":"foo","address":"10.0.0.1"}`)
+		case http.MethodDelete:
+			deleted = true
+			w.WriteHeader(http.StatusAccepted)
+		}
+	}))
+	defer server.Close()
+
+	var logs []structs.AuditLog
+	audit.Log = func(log structs.AuditLog) error {
+		logs = append(logs, log)
+		return nil
+	}
+	defer func() { audit.Log = nil }()
+
+	api := sensu.API{URL: server.URL, Timeout: 1}
+	api.Init()
+	Filters = &filters.Uchiwa{}
+	u := &Uchiwa{
+		Config:      &config.Config{},
+		Data:        &structs.Data{},
+		Datacenters: &[]sensu.Sensu{{Name: "us-east-1", APIs: []sensu.API{api}}},
+		Mu:          &sync.Mutex{},
+	}
+
+	// Empty body by default
+	r, _ := http.NewRequest("DELETE", "/clients/foo?dc=us-east-1", nil)
+	w := httptest.NewRecorder()
+	u.clientHandler(w, r)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "", w.Body.String())
+	assert.True(t, deleted)
+
+	// The deletion is audited
+	assert.Equal(t, 1, len(logs))
+	assert.Equal(t, "deleteclient", logs[0].Action)
+	assert.Equal(t, "/clients/foo?dc=us-east-1", logs[0].URL)
+	assert.Equal(t, "Unknown", logs[0].User)
+
+	// The deleted client is returned
+	r, _ = http.NewRequest("DELETE", "/clients/foo?dc=us-east-1&return=true", nil)
+	w = httptest.NewRecorder()
+	u.clientHandler(w, r)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	var client map[string]interface{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &client))
+	assert.Equal(t, "10.0.0.1", client["address"])
+	assert.Equal(t, "us-east-1", client["dc"])
+
+	// Invalid parameter
+	r, _ = http.NewRequest("DELETE", "/clients/foo?dc=us-east-1&return=foo", nil)
+	w = httptest.NewRecorder()
+	u.clientHandler(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, 2, len(logs))
+}
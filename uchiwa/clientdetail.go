@@ -0,0 +1,132 @@
+package uchiwa
+
+import (
+	"sort"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/sensu/uchiwa/uchiwa/helpers"
+	"github.com/sensu/uchiwa/uchiwa/structs"
+)
+
+// clientDetail holds a client along with its events, the silence entries
+// applying to it, the aggregates it is a member of and the checks it is
+// expected to run but does not
+type clientDetail struct {
+	Client       interface{}   `json:"client"`
+	Events       []interface{} `json:"events"`
+	Silenced     []interface{} `json:"silenced"`
+	Aggregates   []string      `json:"aggregates"`
+	CoverageGaps []string      `json:"coverage_gaps"`
+}
+
+// findClientInDc returns the client of the provided datacenter with the
+// provided name, or nil
+func findClientInDc(name, dc string, clients []interface{}) map[string]interface{} {
+	for _, c := range clients {
+		client, ok := c.(map[string]interface{})
+		if ok && client["name"] == name && client["dc"] == dc {
+			return client
+		}
+	}
+
+	return nil
+}
+
+// clientEvents returns the events of the provided client
+func clientEvents(client structs.GenericClient, events []interface{}) []interface{} {
+	result := []interface{}{}
+	for _, e := range events {
+		var event structs.GenericEvent
+		if err := mapstructure.Decode(e, &event); err != nil {
+			continue
+		}
+
+		if event.Dc == client.Dc && event.Client.Name == client.Name {
+			result = append(result, e)
+		}
+	}
+
+	return result
+}
+
+// clientSilences returns the silence entries applying to the provided
+// client, either through one of its subscriptions or to every client
+func clientSilences(client structs.GenericClient, silenced []interface{}) []interface{} {
+	result := []interface{}{}
+	for _, s := range silenced {
+		entry, ok := s.(map[string]interface{})
+		if !ok || entry["dc"] != client.Dc {
+			continue
+		}
+
+		subscription, _ := entry["subscription"].(string)
+		if subscription == "" || subscription == "client:"+client.Name || helpers.IsStringInArray(subscription, client.Subscriptions) {
+			result = append(result, s)
+		}
+	}
+
+	return result
+}
+
+// clientChecks returns the definitions of the checks subscribed to by the
+// provided client
+func clientChecks(client structs.GenericClient, checks []interface{}) []map[string]interface{} {
+	var result []map[string]interface{}
+	for _, c := range checks {
+		check, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		var generic structs.GenericCheck
+		if err := mapstructure.Decode(check, &generic); err != nil || generic.Dc != client.Dc {
+			continue
+		}
+
+		if SliceIntersection(client.Subscriptions, generic.Subscribers) {
+			result = append(result, check)
+		}
+	}
+
+	return result
+}
+
+// buildClientDetail assembles the relationships of the provided client. The
+// aggregates are derived from the aggregate attributes of its checks, and the
+// coverage gaps from the check names of its history, which are left out if
+// the history is nil
+func buildClientDetail(c map[string]interface{}, checks, events, silenced, history []interface{}) clientDetail {
+	var client structs.GenericClient
+	mapstructure.Decode(c, &client)
+
+	detail := clientDetail{
+		Client:     c,
+		Events:     clientEvents(client, events),
+		Silenced:   clientSilences(client, silenced),
+		Aggregates: []string{},
+	}
+
+	running := historyChecks(history)
+	if history != nil {
+		detail.CoverageGaps = []string{}
+	}
+
+	for _, check := range clientChecks(client, checks) {
+		if aggregate, ok := check["aggregate"].(string); ok && aggregate != "" {
+			detail.Aggregates = MergeStringSlices(detail.Aggregates, []string{aggregate})
+		}
+		if aggregates, ok := check["aggregates"].([]interface{}); ok {
+			detail.Aggregates = MergeStringSlices(detail.Aggregates, helpers.InterfaceToString(aggregates))
+		}
+
+		name, _ := check["name"].(string)
+		if history != nil && !helpers.IsStringInArray(name, running) {
+			detail.CoverageGaps = append(detail.CoverageGaps, name)
+		}
+	}
+
+	sort.Strings(detail.Aggregates)
+	sort.Strings(detail.CoverageGaps)
+
+	return detail
+}
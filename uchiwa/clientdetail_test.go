@@ -0,0 +1,44 @@
+package uchiwa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildClientDetail(t *testing.T) {
+	client := map[string]interface{}{"name": "foo", "dc": "us-east-1", "subscriptions": []interface{}{"linux", "client:foo"}}
+	checks := []interface{}{
+		map[string]interface{}{"name": "cpu", "dc": "us-east-1", "subscribers": []interface{}{"linux"}, "aggregate": "linux-cpu"},
+		map[string]interface{}{"name": "disk", "dc": "us-east-1", "subscribers": []interface{}{"linux"}, "aggregates": []interface{}{"linux-disk", "linux-cpu"}},
+		map[string]interface{}{"name": "iis", "dc": "us-east-1", "subscribers": []interface{}{"windows"}, "aggregate": "windows"},
+		map[string]interface{}{"name": "mem", "dc": "us-west-1", "subscribers": []interface{}{"linux"}},
+	}
+	events := []interface{}{
+		map[string]interface{}{"dc": "us-east-1", "client": map[string]interface{}{"name": "foo"}, "check": map[string]interface{}{"name": "cpu"}},
+		map[string]interface{}{"dc": "us-west-1", "client": map[string]interface{}{"name": "foo"}, "check": map[string]interface{}{"name": "cpu"}},
+		map[string]interface{}{"dc": "us-east-1", "client": map[string]interface{}{"name": "bar"}, "check": map[string]interface{}{"name": "cpu"}},
+	}
+	silenced := []interface{}{
+		map[string]interface{}{"id": "linux:*", "dc": "us-east-1", "subscription": "linux"},
+		map[string]interface{}{"id": "client:foo:*", "dc": "us-east-1", "subscription": "client:foo"},
+		map[string]interface{}{"id": "*:cpu", "dc": "us-east-1", "check": "cpu"},
+		map[string]interface{}{"id": "windows:*", "dc": "us-east-1", "subscription": "windows"},
+		map[string]interface{}{"id": "linux:*", "dc": "us-west-1", "subscription": "linux"},
+	}
+	history := []interface{}{
+		map[string]interface{}{"check": "cpu"},
+		map[string]interface{}{"check": "keepalive"},
+	}
+
+	detail := buildClientDetail(client, checks, events, silenced, history)
+	assert.Equal(t, client, detail.Client)
+	assert.Equal(t, []interface{}{events[0]}, detail.Events)
+	assert.Equal(t, silenced[:3], detail.Silenced)
+	assert.Equal(t, []string{"linux-cpu", "linux-disk"}, detail.Aggregates)
+	assert.Equal(t, []string{"disk"}, detail.CoverageGaps)
+
+	// Unknown history
+	detail = buildClientDetail(client, checks, events, silenced, nil)
+	assert.Nil(t, detail.CoverageGaps)
+}
@@ -0,0 +1,195 @@
+package uchiwa
+
+import (
+	"sort"
+	"time"
+
+	"github.com/sensu/uchiwa/uchiwa/structs"
+)
+
+// clientSnapshotsLimit is the number of state snapshots remembered per client
+const clientSnapshotsLimit = 100
+
+// Kinds of check status changes between two points of a client history
+const (
+	checkStatusChanged   = "changed"
+	checkStatusFailing   = "failing"
+	checkStatusRecovered = "recovered"
+)
+
+// clientSnapshot holds the statuses of the failing checks of a client, keyed
+// by check name, from a given time until the next snapshot
+type clientSnapshot struct {
+	time     int64
+	statuses map[string]int
+}
+
+// clientStatusChange holds the change of the status of a check between two
+// points of a client history
+type clientStatusChange struct {
+	Check  string `json:"check"`
+	From   int    `json:"from"`
+	To     int    `json:"to"`
+	Change string `json:"change"`
+}
+
+// clientHistoryDiff is the response of the /clients/:client/history/diff
+// endpoint
+type clientHistoryDiff struct {
+	Client  string               `json:"client"`
+	Dc      string               `json:"dc"`
+	From    int64                `json:"from"`
+	To      int64                `json:"to"`
+	Changes []clientStatusChange `json:"changes"`
+}
+
+// clientHistoryLog keeps track of the state of the clients across the polls,
+// as snapshots keyed by datacenter and client name. A snapshot is only
+// recorded when the state of the client changes. It must be accessed with the
+// mutex held
+type clientHistoryLog struct {
+	snapshots map[string][]clientSnapshot
+}
+
+// clientStatuses returns the statuses of the failing checks of every client
+// of the polled datacenters, keyed by datacenter and client name
+func clientStatuses(data *structs.Data, polled map[string]bool) map[string]map[string]int {
+	statuses := make(map[string]map[string]int, len(data.Clients))
+	for _, c := range data.Clients {
+		client, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name, _ := client["name"].(string)
+		dc, _ := client["dc"].(string)
+		if name != "" && polled[dc] {
+			statuses[dc+"/"+name] = make(map[string]int)
+		}
+	}
+
+	for _, e := range data.Events {
+		event, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		check, _ := event["check"].(map[string]interface{})
+		client, _ := event["client"].(map[string]interface{})
+		checkName, _ := check["name"].(string)
+		clientName, _ := client["name"].(string)
+		dc, _ := event["dc"].(string)
+		status, _ := check["status"].(float64)
+
+		key := dc + "/" + clientName
+		if _, ok := statuses[key]; !ok || checkName == "" {
+			continue
+		}
+		statuses[key][checkName] = int(status)
+	}
+
+	return statuses
+}
+
+// sameStatuses returns true if both sets of check statuses are identical
+func sameStatuses(a, b map[string]int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for check, status := range a {
+		if other, ok := b[check]; !ok || other != status {
+			return false
+		}
+	}
+
+	return true
+}
+
+// update records a snapshot of the clients of the provided data whose state
+// changed. The history of the datacenters that could not be polled is left as
+// is, while the history of the clients that no longer exist is dropped
+func (l *clientHistoryLog) update(data *structs.Data, now time.Time) {
+	if len(data.Dc) == 0 {
+		return
+	}
+
+	polled := make(map[string]bool, len(data.Dc))
+	for _, dc := range data.Dc {
+		polled[dc.Name] = true
+	}
+
+	if l.snapshots == nil {
+		l.snapshots = make(map[string][]clientSnapshot)
+	}
+
+	current := clientStatuses(data, polled)
+
+	for key := range l.snapshots {
+		if _, ok := current[key]; !ok && polled[eventDc(key)] {
+			delete(l.snapshots, key)
+		}
+	}
+
+	for key, statuses := range current {
+		snapshots := l.snapshots[key]
+		if len(snapshots) > 0 && sameStatuses(snapshots[len(snapshots)-1].statuses, statuses) {
+			continue
+		}
+
+		snapshots = append(snapshots, clientSnapshot{time: now.Unix(), statuses: statuses})
+		if extra := len(snapshots) - clientSnapshotsLimit; extra > 0 {
+			snapshots = append([]clientSnapshot(nil), snapshots[extra:]...)
+		}
+		l.snapshots[key] = snapshots
+	}
+}
+
+// at returns the snapshot describing the state of the provided client at the
+// provided time, or false if no history is retained for that time
+func (l *clientHistoryLog) at(dc, client string, t int64) (clientSnapshot, bool) {
+	snapshots := l.snapshots[dc+"/"+client]
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		if snapshots[i].time <= t {
+			return snapshots[i], true
+		}
+	}
+
+	return clientSnapshot{}, false
+}
+
+// diffClientSnapshots returns the checks whose status differs between the
+// provided snapshots, sorted by check name. The checks missing from a
+// snapshot are considered OK
+func diffClientSnapshots(from, to clientSnapshot) []clientStatusChange {
+	changes := []clientStatusChange{}
+
+	checks := make(map[string]bool, len(from.statuses)+len(to.statuses))
+	for check := range from.statuses {
+		checks[check] = true
+	}
+	for check := range to.statuses {
+		checks[check] = true
+	}
+
+	for check := range checks {
+		before, after := from.statuses[check], to.statuses[check]
+		if before == after {
+			continue
+		}
+
+		change := clientStatusChange{Check: check, From: before, To: after, Change: checkStatusChanged}
+		if before == 0 {
+			change.Change = checkStatusFailing
+		} else if after == 0 {
+			change.Change = checkStatusRecovered
+		}
+		changes = append(changes, change)
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Check < changes[j].Check
+	})
+
+	return changes
+}
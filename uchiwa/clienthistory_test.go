@@ -0,0 +1,59 @@
+package uchiwa
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sensu/uchiwa/uchiwa/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientHistoryLog(t *testing.T) {
+	l := &clientHistoryLog{}
+	now := time.Unix(1000, 0)
+
+	event := func(client, check string, status float64) interface{} {
+		return map[string]interface{}{
+			"dc":     "us-east-1",
+			"check":  map[string]interface{}{"name": check, "status": status},
+			"client": map[string]interface{}{"name": client},
+		}
+	}
+	dcs := []*structs.Datacenter{{Name: "us-east-1"}}
+	clients := []interface{}{map[string]interface{}{"name": "foo", "dc": "us-east-1"}}
+
+	l.update(&structs.Data{Dc: dcs, Clients: clients}, now)
+	l.update(&structs.Data{Dc: dcs, Clients: clients, Events: []interface{}{event("foo", "cpu", 2)}}, now.Add(10*time.Second))
+	// The state did not change
+	l.update(&structs.Data{Dc: dcs, Clients: clients, Events: []interface{}{event("foo", "cpu", 2)}}, now.Add(20*time.Second))
+	assert.Equal(t, 2, len(l.snapshots["us-east-1/foo"]))
+
+	snapshot, ok := l.at("us-east-1", "foo", 1015)
+	assert.True(t, ok)
+	assert.Equal(t, map[string]int{"cpu": 2}, snapshot.statuses)
+
+	_, ok = l.at("us-east-1", "foo", 999)
+	assert.False(t, ok)
+
+	// The history of an unreachable datacenter is kept
+	l.update(&structs.Data{Dc: []*structs.Datacenter{{Name: "us-west-1"}}}, now.Add(30*time.Second))
+	assert.Equal(t, 2, len(l.snapshots["us-east-1/foo"]))
+
+	// The history of a removed client is dropped
+	l.update(&structs.Data{Dc: dcs}, now.Add(40*time.Second))
+	_, ok = l.snapshots["us-east-1/foo"]
+	assert.False(t, ok)
+}
+
+func TestDiffClientSnapshots(t *testing.T) {
+	from := clientSnapshot{statuses: map[string]int{"cpu": 2, "disk": 1, "ram": 1}}
+	to := clientSnapshot{statuses: map[string]int{"disk": 2, "ram": 1, "swap": 1}}
+
+	expected := []clientStatusChange{
+		{Check: "cpu", From: 2, To: 0, Change: checkStatusRecovered},
+		{Check: "disk", From: 1, To: 2, Change: checkStatusChanged},
+		{Check: "swap", From: 0, To: 1, Change: checkStatusFailing},
+	}
+	assert.Equal(t, expected, diffClientSnapshots(from, to))
+	assert.Equal(t, []clientStatusChange{}, diffClientSnapshots(to, to))
+}
@@ -7,8 +7,9 @@ import (
 	"math/rand"
 	"os"
 	"path/filepath"
-	"strings"
+	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/palourde/mergo"
 	"github.com/sensu/uchiwa/uchiwa/authentication"
@@ -17,11 +18,35 @@ import (
 
 const obfuscatedValue = "*****"
 
+// DefaultFeatures lists the known feature flags, which enable optional
+// endpoints, along with their state when they are not configured
+var DefaultFeatures = map[string]bool{
+	"bulkEvents":       true,
+	"bulkSilences":     true,
+	"eventsStream":     true,
+	"flappingEvents":   true,
+	"orphanedSilences": true,
+	"pendingRequests":  true,
+	"userCapabilities": true,
+}
+
 var (
 	defaultGlobalConfig = GlobalConfig{
 		Audit: Audit{
-			Level:   "default",
-			Logfile: "/var/log/sensu/sensu-enterprise-dashboard-audit.log",
+			Level: "default",
+		},
+		CheckRequests: CheckRequests{
+			TTL: 600,
+		},
+		CheckStats: CheckStats{
+			Window: 3600,
+		},
+		EventRate: EventRate{
+			Interval:  60,
+			Retention: 86400,
+		},
+		Flapping: Flapping{
+			Window: 3600,
 		},
 		Host: "0.0.0.0",
 		Ldap: Ldap{
@@ -34,12 +59,24 @@ var (
 				GroupObjectClass:     "groupOfNames",
 			},
 		},
-		LogLevel: "info",
-		Port:     3000,
-		Refresh:  10,
+		LogBuffer: LogBuffer{
+			Size: 1000,
+		},
+		LogLevel:        "info",
+		MaxPathLength:   4096,
+		MaxPathSegments: 128,
+		Port:            3000,
+		RateLimit: RateLimit{
+			Max: 6000,
+		},
+		Refresh: 10,
 		SSL: SSL{
 			TLSMinVersion: "tls10",
 		},
+		Startup: Startup{
+			Retries:    3,
+			RetryDelay: 1,
+		},
 		UsersOptions: UsersOptions{
 			DateFormat:             "YYYY-MM-DD HH:mm:ss",
 			DefaultTheme:           "uchiwa-default",
@@ -194,6 +231,14 @@ func initSensu(apis []SensuConfig) []SensuConfig {
 
 		// Set the API URL
 		apis[i].URL = fmt.Sprintf("%s://%s:%d%s", prot, api.Host, api.Port, api.Path)
+
+		// The schema is detected from the Sensu version unless specified
+		switch api.Schema {
+		case "", "auto", "1.x", "2.x":
+		default:
+			logger.Warningf("Sensu API %q has an invalid schema %q, it will be detected instead", api.Name, api.Schema)
+			apis[i].Schema = "auto"
+		}
 	}
 	return apis
 }
@@ -267,9 +312,14 @@ func initUchiwa(global GlobalConfig) GlobalConfig {
 	// Set the logger level
 	logger.SetLogLevel(global.LogLevel)
 
+	// Retain the most recent log entries if required
+	if global.LogBuffer.Enabled {
+		logger.EnableBuffer(global.LogBuffer.Size)
+	}
+
 	// Set the port from the environment if available
 	port, ok := os.LookupEnv("PORT")
-	
+
 	if ok {
 		p, err := strconv.Atoi(port)
 
@@ -284,6 +334,53 @@ func initUchiwa(global GlobalConfig) GlobalConfig {
 	// Set the refresh rate for frontend
 	global.UsersOptions.Refresh = global.Refresh * 1000
 
+	// Silence entries targeting unknown resources are either rejected or
+	// reported in a warning
+	switch global.UsersOptions.ValidateSilenceTargets {
+	case "", "reject", "warn":
+	default:
+		logger.Warningf("Invalid value '%s' for validatesilencetargets, falling back to 'warn'", global.UsersOptions.ValidateSilenceTargets)
+		global.UsersOptions.ValidateSilenceTargets = "warn"
+	}
+
+	// The silence reasons are only validated against a valid pattern
+	if global.UsersOptions.SilenceReasonPattern != "" {
+		if _, err := regexp.Compile(global.UsersOptions.SilenceReasonPattern); err != nil {
+			logger.Warningf("Invalid silencereasonpattern '%s', ignoring it: %s", global.UsersOptions.SilenceReasonPattern, err)
+			global.UsersOptions.SilenceReasonPattern = ""
+		}
+	}
+
+	// The default expiration, in seconds, of the silence entries is disabled
+	// when not positive
+	if global.UsersOptions.DefaultSilenceExpiration < 0 {
+		logger.Warningf("Invalid value '%d' for defaultsilenceexpiration, disabling it", global.UsersOptions.DefaultSilenceExpiration)
+		global.UsersOptions.DefaultSilenceExpiration = 0
+	}
+
+	// The keys of the resource objects are either normalized to snake_case or
+	// camelCase, or left untouched
+	switch global.NormalizeKeys {
+	case "", "camel", "snake":
+	default:
+		logger.Warningf("Invalid value '%s' for normalizekeys, leaving the keys untouched", global.NormalizeKeys)
+		global.NormalizeKeys = ""
+	}
+
+	// Resolve the state of every known feature flag and ignore the unknown ones
+	features := make(map[string]bool, len(DefaultFeatures))
+	for name, enabled := range DefaultFeatures {
+		features[name] = enabled
+	}
+	for name, enabled := range global.Features {
+		if _, ok := DefaultFeatures[name]; !ok {
+			logger.Warningf("Unknown feature flag '%s', ignoring it", name)
+			continue
+		}
+		features[name] = enabled
+	}
+	global.Features = features
+
 	return global
 }
 
@@ -357,6 +454,14 @@ func (c *Config) GetPublic() *Config {
 		p.Sensu[i] = c.Sensu[i]
 		p.Sensu[i].User = obfuscatedValue
 		p.Sensu[i].Pass = obfuscatedValue
+
+		// The headers usually contain API keys
+		if len(c.Sensu[i].Headers) > 0 {
+			p.Sensu[i].Headers = make(map[string]string, len(c.Sensu[i].Headers))
+			for name := range c.Sensu[i].Headers {
+				p.Sensu[i].Headers[name] = obfuscatedValue
+			}
+		}
 	}
 
 	return p
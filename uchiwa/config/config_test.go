@@ -1,8 +1,8 @@
 package config
 
 import (
-	"testing"
 	"os"
+	"testing"
 
 	"github.com/sensu/uchiwa/uchiwa/authentication"
 	"github.com/stretchr/testify/assert"
@@ -22,6 +22,12 @@ func TestLoad(t *testing.T) {
 	assert.Equal(t, 389, conf.Uchiwa.Ldap.Port)
 	assert.Equal(t, "person", conf.Uchiwa.Ldap.UserObjectClass)
 	assert.Equal(t, "default", conf.Uchiwa.Audit.Level)
+	// The audit log file is only written if explicitly configured
+	assert.Equal(t, "", conf.Uchiwa.Audit.Logfile)
+	assert.Equal(t, 0, conf.Uchiwa.RateLimit.Requests)
+	assert.Equal(t, 6000, conf.Uchiwa.RateLimit.Max)
+	assert.Equal(t, 0, conf.Uchiwa.CheckStats.Samples)
+	assert.Equal(t, 3600, conf.Uchiwa.CheckStats.Window)
 
 	conf = Load("../../fixtures/config_test.json", "../../fixtures/conf.d")
 	assert.Equal(t, 5, len(conf.Sensu))
@@ -156,14 +162,22 @@ func TestInitUchiwa(t *testing.T) {
 	uchiwa = initUchiwa(conf)
 	os.Unsetenv("PORT")
 	assert.Equal(t, 8080, uchiwa.Port)
+
+	conf = GlobalConfig{Features: map[string]bool{"pendingRequests": false, "foo": true}}
+	uchiwa = initUchiwa(conf)
+	assert.False(t, uchiwa.Features["pendingRequests"])
+	assert.True(t, uchiwa.Features["flappingEvents"])
+	_, ok := uchiwa.Features["foo"]
+	assert.False(t, ok)
 }
 
 func TestGetPublic(t *testing.T) {
 	conf := Config{
 		Sensu: []SensuConfig{
 			SensuConfig{
-				User: "foo",
-				Pass: "secret",
+				Headers: map[string]string{"X-Api-Key": "secret"},
+				User:    "foo",
+				Pass:    "secret",
 			},
 		},
 		Uchiwa: GlobalConfig{
@@ -194,6 +208,8 @@ func TestGetPublic(t *testing.T) {
 
 	assert.Equal(t, "*****", pubConf.Sensu[0].User)
 	assert.Equal(t, "*****", pubConf.Sensu[0].Pass)
+	assert.Equal(t, map[string]string{"X-Api-Key": "*****"}, pubConf.Sensu[0].Headers)
+	assert.Equal(t, "secret", conf.Sensu[0].Headers["X-Api-Key"])
 	assert.Equal(t, "*****", pubConf.Uchiwa.User)
 	assert.Equal(t, "*****", pubConf.Uchiwa.Pass)
 	assert.Equal(t, []authentication.User{}, pubConf.Uchiwa.Users)
@@ -17,11 +17,15 @@ type Config struct {
 // SensuConfig struct contains conf about a Sensu API
 type SensuConfig struct {
 	Advanced Advanced
+	Alias    string
+	Headers  map[string]string
 	Name     string
 	Host     string
 	Port     int
+	Schema   string
 	Ssl      bool
 	Insecure bool
+	Interval int
 	URL      string
 	User     string
 	Path     string
@@ -31,29 +35,75 @@ type SensuConfig struct {
 
 // GlobalConfig struct contains conf about Uchiwa
 type GlobalConfig struct {
-	Host         string
-	Port         int
-	LogLevel     string
-	Refresh      int
-	Pass         string
-	User         string
-	Users        []authentication.User
-	Audit        Audit
-	Auth         structs.Auth
-	Db           Db
-	Enterprise   bool
-	Github       Github
-	Gitlab       Gitlab
-	Ldap         Ldap
-	OIDC         OIDC
-	SSL          SSL
-	UsersOptions UsersOptions
-}
-
-// Audit struct contains the config of the Audit logger
+	Host                 string
+	Port                 int
+	LogBuffer            LogBuffer
+	MaxConnectionsPerIP  int
+	MaxInFlightRequests  int
+	MaxPathLength        int
+	MaxPathSegments      int
+	MaxResponseBytes     int
+	MaxStreamConnections int
+	NormalizeKeys        string
+	NormalizePaths       bool
+	LogLevel             string
+	Refresh              int
+	Pass                 string
+	User                 string
+	Users                []authentication.User
+	Audit                Audit
+	Auth                 structs.Auth
+	Authorization        Authorization
+	CheckRequests        CheckRequests
+	CheckStats           CheckStats
+	CORS                 CORS
+	DatacenterPriority   []string
+	Db                   Db
+	Debug                bool
+	Enterprise           bool
+	EventRate            EventRate
+	Features             map[string]bool
+	Flapping             Flapping
+	ForceGzipUserAgents  []string
+	Github               Github
+	Gitlab               Gitlab
+	Ldap                 Ldap
+	OIDC                 OIDC
+	RateLimit            RateLimit
+	Redact               Redact
+	SSL                  SSL
+	ServeBeforeReady     bool
+	StaleData            StaleData
+	Startup              Startup
+	StrictDatacenters    bool
+	StrictJSON           bool
+	TrustedProxies       []string
+	UsersOptions         UsersOptions
+}
+
+// Audit struct contains the config of the Audit logger, the rotation of its
+// log file, only written if explicitly configured, and the number of most
+// recent entries retained in memory
 type Audit struct {
-	Level   string
-	Logfile string
+	Compress   bool
+	Level      string
+	Logfile    string
+	MaxBackups int
+	MaxSizeMB  int
+	Retain     int
+}
+
+// Authorization struct contains the authorization settings of each role,
+// keyed by role name
+type Authorization struct {
+	Roles map[string]AuthorizationRole
+}
+
+// AuthorizationRole struct contains the resource types, e.g. events, that the
+// members of a role are restricted to. Every resource type is allowed when
+// empty
+type AuthorizationRole struct {
+	Resources []string
 }
 
 // Advanced contains advanced configuration for Sensu datacenters HTTP client
@@ -63,12 +113,45 @@ type Advanced struct {
 	Tracing           bool
 }
 
+// CheckRequests struct contains the number of seconds during which the check
+// execution requests are tracked
+type CheckRequests struct {
+	TTL int
+}
+
+// CheckStats struct contains the number of check executions retained for
+// each check, and the window, in seconds, over which the statistics of the
+// executions are computed
+type CheckStats struct {
+	Samples int
+	Window  int
+}
+
+// CORS struct contains the origins allowed to consume the API from another
+// origin, or * for any origin. CORS is disabled when empty
+type CORS struct {
+	AllowedOrigins []string
+}
+
 // Db struct contains the SQL driver configuration
 type Db struct {
 	Driver string
 	Scheme string
 }
 
+// EventRate struct contains the interval, in seconds, at which the number of
+// events of each datacenter is sampled, and how long the samples are retained
+type EventRate struct {
+	Interval  int
+	Retention int
+}
+
+// Flapping struct contains the window, in seconds, over which the state
+// transitions of the events are counted
+type Flapping struct {
+	Window int
+}
+
 // Github struct contains the GitHub driver configuration
 type Github struct {
 	ClientID     string
@@ -114,6 +197,13 @@ type LdapServer struct {
 	UserObjectClass      string
 }
 
+// LogBuffer struct contains the configuration of the in-memory retention of
+// the most recent log entries
+type LogBuffer struct {
+	Enabled bool
+	Size    int
+}
+
 // OIDC struct contains the OIDC driver configuration
 type OIDC struct {
 	AdditionalScopes []string
@@ -125,6 +215,35 @@ type OIDC struct {
 	Server           string
 }
 
+// RateLimit struct contains the number of requests per minute allowed for
+// each user, and the maximum that can be granted through the JWT claims
+type RateLimit struct {
+	Max      int
+	Requests int
+}
+
+// Redact struct contains the check attributes, e.g. command or output, masked
+// in the checks and events for the members of the roles not exempted
+type Redact struct {
+	CheckFields []string
+	ExemptRoles []string
+}
+
+// StaleData struct contains whether the last known good data is served while
+// every datacenter is unreachable, and for how many seconds at most
+type StaleData struct {
+	Enabled   bool
+	Threshold int
+}
+
+// Startup struct contains the number of times the initial poll of the
+// unreachable datacenters is retried, and the delay, in seconds, before the
+// first retry, which doubles before every other one
+type Startup struct {
+	Retries    int
+	RetryDelay int
+}
+
 // SSL struct contains the path the SSL certificate and key
 type SSL struct {
 	CertFile      string
@@ -136,12 +255,18 @@ type SSL struct {
 
 // UsersOptions struct contains various config tweaks
 type UsersOptions struct {
-	DateFormat             string
-	DefaultTheme           string
-	DisableNoExpiration    bool
-	Favicon                string
-	LogoURL                string
-	Refresh                int
-	RequireSilencingReason bool
-	SilenceDurations       []float32
+	AllowedAdhocChecks       []string
+	DateFormat               string
+	DefaultSilenceExpiration int32
+	DefaultTheme             string
+	DisableNoExpiration      bool
+	Favicon                  string
+	LogoURL                  string
+	MaxSilenceReasonLength   int
+	PreventStashOverwrite    bool
+	Refresh                  int
+	RequireSilencingReason   bool
+	SilenceDurations         []float32
+	SilenceReasonPattern     string
+	ValidateSilenceTargets   string
 }
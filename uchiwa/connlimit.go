@@ -0,0 +1,141 @@
+package uchiwa
+
+import (
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/sensu/uchiwa/uchiwa/logger"
+)
+
+// ipLimitListener wraps a listener in order to limit the number of concurrent
+// connections of each remote IP. The connections beyond the limit are closed
+// as soon as they are accepted, while the trusted proxies are exempted since
+// they forward the connections of many clients
+type ipLimitListener struct {
+	net.Listener
+
+	limit   int
+	trusted []*net.IPNet
+
+	mutex sync.Mutex
+	conns map[string]int
+}
+
+// ipLimitConn is a connection counted by an ipLimitListener until it is closed
+type ipLimitConn struct {
+	net.Conn
+
+	once    sync.Once
+	release func()
+}
+
+func newIPLimitListener(l net.Listener, limit int, trusted []*net.IPNet) *ipLimitListener {
+	return &ipLimitListener{
+		Listener: l,
+		limit:    limit,
+		trusted:  trusted,
+		conns:    make(map[string]int),
+	}
+}
+
+// parseTrustedProxies parses the provided IP addresses and CIDR ranges of the
+// trusted proxies. The invalid entries are ignored
+func parseTrustedProxies(entries []string) []*net.IPNet {
+	var networks []*net.IPNet
+
+	for _, entry := range entries {
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				logger.Warningf("Invalid trusted proxy '%s', ignoring it", entry)
+				continue
+			}
+
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 8 * net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			logger.Warningf("Invalid trusted proxy '%s', ignoring it", entry)
+			continue
+		}
+		networks = append(networks, network)
+	}
+
+	return networks
+}
+
+// isTrusted returns whether the provided IP belongs to a trusted proxy
+func (l *ipLimitListener) isTrusted(ip net.IP) bool {
+	for _, network := range l.trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// acquire records a new connection of the provided IP and returns false if
+// the IP already reached the limit of connections
+func (l *ipLimitListener) acquire(ip string) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.conns[ip] >= l.limit {
+		return false
+	}
+
+	l.conns[ip]++
+	return true
+}
+
+// release records the closing of a connection of the provided IP
+func (l *ipLimitListener) release(ip string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.conns[ip]--
+	if l.conns[ip] <= 0 {
+		delete(l.conns, ip)
+	}
+}
+
+// Accept waits for and returns the next connection whose remote IP did not
+// reach the limit of connections
+func (l *ipLimitListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		host, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+		ip := net.ParseIP(host)
+		if err != nil || ip == nil || l.isTrusted(ip) {
+			return conn, nil
+		}
+
+		key := ip.String()
+		if !l.acquire(key) {
+			logger.Debugf("Refusing the connection from %s, too many open connections", key)
+			conn.Close()
+			continue
+		}
+
+		return &ipLimitConn{Conn: conn, release: func() { l.release(key) }}, nil
+	}
+}
+
+// Close closes the connection and releases it from the limit, only once
+func (c *ipLimitConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.release)
+	return err
+}
@@ -0,0 +1,76 @@
+package uchiwa
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	networks := parseTrustedProxies([]string{"10.0.0.1", "192.168.0.0/16", "::1", "foo", "10.0.0.0/33"})
+	assert.Equal(t, 3, len(networks))
+
+	l := newIPLimitListener(nil, 1, networks)
+	assert.True(t, l.isTrusted(net.ParseIP("10.0.0.1")))
+	assert.False(t, l.isTrusted(net.ParseIP("10.0.0.2")))
+	assert.True(t, l.isTrusted(net.ParseIP("192.168.1.10")))
+	assert.True(t, l.isTrusted(net.ParseIP("::1")))
+}
+
+func TestIPLimitListener(t *testing.T) {
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	l := newIPLimitListener(inner, 1, nil)
+	defer l.Close()
+
+	accepted := make(chan net.Conn)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				close(accepted)
+				return
+			}
+			accepted <- conn
+		}
+	}()
+
+	first, err := net.Dial("tcp", inner.Addr().String())
+	assert.Nil(t, err)
+	defer first.Close()
+	conn := <-accepted
+
+	// The connections beyond the limit are refused
+	second, err := net.Dial("tcp", inner.Addr().String())
+	assert.Nil(t, err)
+	defer second.Close()
+	second.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, err = second.Read(make([]byte, 1))
+	assert.NotNil(t, err)
+	assert.False(t, isTimeout(err))
+
+	// A connection can be opened once another one is closed. Accept may still
+	// be running, so the count is read under the lock
+	assert.Nil(t, conn.Close())
+	conn.Close()
+	l.mutex.Lock()
+	open := len(l.conns)
+	l.mutex.Unlock()
+	assert.Equal(t, 0, open)
+	third, err := net.Dial("tcp", inner.Addr().String())
+	assert.Nil(t, err)
+	defer third.Close()
+	select {
+	case conn := <-accepted:
+		conn.Close()
+	case <-time.After(5 * time.Second):
+		t.Fatal("The connection was not accepted")
+	}
+}
+
+func isTimeout(err error) bool {
+	netErr, ok := err.(net.Error)
+	return ok && netErr.Timeout()
+}
@@ -0,0 +1,119 @@
+package uchiwa
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sensu/uchiwa/uchiwa/logger"
+)
+
+// csvColumn is a column of a CSV export, filled with the field found at the
+// provided path of nested objects
+type csvColumn struct {
+	header string
+	path   []string
+}
+
+// clientColumns contains the columns of the clients CSV export
+var clientColumns = []csvColumn{
+	{"name", []string{"name"}},
+	{"dc", []string{"dc"}},
+	{"address", []string{"address"}},
+	{"subscriptions", []string{"subscriptions"}},
+	{"timestamp", []string{"timestamp"}},
+}
+
+// eventColumns contains the columns of the events CSV export
+var eventColumns = []csvColumn{
+	{"client", []string{"client", "name"}},
+	{"check", []string{"check", "name"}},
+	{"dc", []string{"dc"}},
+	{"status", []string{"check", "status"}},
+	{"output", []string{"check", "output"}},
+	{"occurrences", []string{"occurrences"}},
+}
+
+// csvValue formats a field as a CSV cell. The numbers are never written in
+// the exponent format, the arrays are joined with a semicolon and the objects
+// are encoded as JSON
+func csvValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		values := make([]string, len(v))
+		for i, element := range v {
+			values[i] = csvValue(element)
+		}
+		return strings.Join(values, ";")
+	case map[string]interface{}:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// encodeCSV encodes the provided elements as CSV, with a header row followed
+// by a row per element. The missing fields are left empty
+func encodeCSV(list []interface{}, columns []csvColumn) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	writer := csv.NewWriter(buf)
+
+	row := make([]string, len(columns))
+	for i, column := range columns {
+		row[i] = column.header
+	}
+	if err := writer.Write(row); err != nil {
+		return nil, err
+	}
+
+	for _, e := range list {
+		element, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			value, _ := nestedField(element, column.path)
+			row[i] = csvValue(value)
+		}
+		if err := writer.Write(row); err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+// writeCSV writes the provided elements as a CSV attachment with the provided
+// file name, compressed with gzip or deflate if supported by the client
+func (u *Uchiwa) writeCSV(w http.ResponseWriter, r *http.Request, list []interface{}, columns []csvColumn, filename string) {
+	b, err := encodeCSV(list, columns)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// Create header
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+
+	if err := u.writeEncoded(w, r, http.StatusOK, b); err != nil {
+		logger.Warningf("Cannot write response data: %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package uchiwa
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sensu/uchiwa/uchiwa/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSVValue(t *testing.T) {
+	assert.Equal(t, "", csvValue(nil))
+	assert.Equal(t, "foo", csvValue("foo"))
+	assert.Equal(t, "1700000000", csvValue(float64(1700000000)))
+	assert.Equal(t, "1.5", csvValue(1.5))
+	assert.Equal(t, "3", csvValue(3))
+	assert.Equal(t, "true", csvValue(true))
+	assert.Equal(t, "linux;web", csvValue([]interface{}{"linux", "web"}))
+	assert.Equal(t, `{"foo":"bar"}`, csvValue(map[string]interface{}{"foo": "bar"}))
+}
+
+func TestEncodeCSV(t *testing.T) {
+	events := []interface{}{
+		map[string]interface{}{
+			"dc":          "us-east-1",
+			"occurrences": float64(3),
+			"client":      map[string]interface{}{"name": "foo"},
+			"check":       map[string]interface{}{"name": "disk", "status": float64(2), "output": "Disk \"/\" full,\n95%"},
+		},
+		map[string]interface{}{"dc": "us-west-1"},
+		"foo",
+	}
+
+	b, err := encodeCSV(events, eventColumns)
+	assert.Nil(t, err)
+	assert.Equal(t, "client,check,dc,status,output,occurrences\n"+
+		"foo,disk,us-east-1,2,\"Disk \"\"/\"\" full,\n95%\",3\n"+
+		",,us-west-1,,,\n", string(b))
+}
+
+func TestWriteCSV(t *testing.T) {
+	u := &Uchiwa{Config: &config.Config{}}
+	clients := []interface{}{
+		map[string]interface{}{"name": "foo", "dc": "us-east-1", "address": "10.0.0.1", "subscriptions": []interface{}{"linux", "web"}, "timestamp": float64(1000)},
+	}
+
+	r := httptest.NewRequest("GET", "/clients?format=csv", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	u.writeCSV(w, r, clients, clientColumns, "clients.csv")
+
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "attachment; filename=\"clients.csv\"", w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+
+	gz, err := gzip.NewReader(w.Body)
+	assert.Nil(t, err)
+	b, err := ioutil.ReadAll(gz)
+	assert.Nil(t, err)
+	assert.Equal(t, "name,dc,address,subscriptions,timestamp\nfoo,us-east-1,10.0.0.1,linux;web,1000\n", string(b))
+}
@@ -11,20 +11,39 @@ import (
 
 const datacenterErrorString = "Connection error. Is the Sensu API running?"
 
+// maxStartupRetryDelay bounds the delay between two retries of the initial
+// poll of a datacenter
+const maxStartupRetryDelay = 30 * time.Second
+
 // Daemon structure is used to manage the Uchiwa daemon
 type Daemon struct {
-	Data        *structs.Data
-	Datacenters *[]sensu.Sensu
-	Enterprise  bool
+	CheckSamples int
+	Data         *structs.Data
+	Datacenters  *[]sensu.Sensu
+	Enterprise   bool
+	// StartupRetries is the number of times the initial poll of the
+	// unreachable datacenters is retried, waiting StartupRetryDelay before
+	// the first retry and doubling this delay before every other one
+	StartupRetries    int
+	StartupRetryDelay time.Duration
+
+	// interval is the default polling interval of the datacenters, in seconds
+	interval int
+	// nextPoll holds when each datacenter is due to be polled again
+	nextPoll map[string]time.Time
+	// snapshots holds the data of the last poll of each datacenter, from which
+	// Data is rebuilt whenever any datacenter is polled
+	snapshots map[string]*structs.Data
 }
 
 // DatacenterFetcher is used to manage the fetching of data from a datacenter
 type DatacenterFetcher struct {
-	data       *structs.Data
-	datacenter sensu.Sensu
-	mutex      *sync.Mutex
-	wg         *sync.WaitGroup
-	enterprise bool
+	data         *structs.Data
+	datacenter   sensu.Sensu
+	mutex        *sync.Mutex
+	wg           *sync.WaitGroup
+	checkSamples int
+	enterprise   bool
 }
 
 // DatacenterSnapshotFetcher is used to manage the fetching of data from a datacenter API endpoint
@@ -43,6 +62,7 @@ type DatacenterSnapshot struct {
 	Clients    []interface{}
 	Events     []interface{}
 	Info       *structs.Info
+	Results    []interface{}
 	Silenced   []interface{}
 	Stashes    []interface{}
 	Error      string
@@ -54,11 +74,16 @@ type SensuDatacenter interface {
 	Metric(string) (*structs.SERawMetric, error)
 }
 
-// Start method fetches and builds Sensu data from each datacenter every Refresh seconds
+// Start method fetches and builds Sensu data from each datacenter every Refresh
+// seconds, or at the interval configured for the datacenter
 func (d *Daemon) Start(interval int, data chan *structs.Data) {
+	d.interval = interval
+
 	// immediately fetch the first set of data and send it over the data channel
 	d.fetchData()
+	d.retryFailedDatacenters()
 	d.buildData()
+	d.dueDatacenters(time.Now(), 0)
 
 	select {
 	case data <- d.Data:
@@ -67,11 +92,15 @@ func (d *Daemon) Start(interval int, data chan *structs.Data) {
 		logger.Trace("Could not send initial results on the 'data' channel")
 	}
 
-	// fetch new data every interval
-	duration := time.Duration(interval) * time.Second
-	for _ = range time.Tick(duration) {
-		d.resetData()
-		d.fetchData()
+	// fetch new data from the datacenters as they are due
+	tick := time.Duration(d.Tick(interval)) * time.Second
+	for now := range time.Tick(tick) {
+		due := d.dueDatacenters(now, tick)
+		if len(due) == 0 {
+			continue
+		}
+
+		d.fetchDatacenters(due)
 		d.buildData()
 
 		// send the result over the data channel
@@ -84,6 +113,63 @@ func (d *Daemon) Start(interval int, data chan *structs.Data) {
 	}
 }
 
+// Tick returns the period, in seconds, at which the datacenters must be
+// checked for a poll, i.e. the greatest common divisor of their intervals
+func (d *Daemon) Tick(interval int) int {
+	tick := interval
+	for _, datacenter := range *d.Datacenters {
+		tick = gcd(tick, datacenterInterval(datacenter, interval))
+	}
+
+	if tick < 1 {
+		return 1
+	}
+	return tick
+}
+
+// datacenterInterval returns the polling interval of a datacenter, which
+// defaults to the provided interval
+func datacenterInterval(datacenter sensu.Sensu, interval int) int {
+	if datacenter.Interval > 0 {
+		return datacenter.Interval
+	}
+	return interval
+}
+
+// gcd returns the greatest common divisor of two intervals
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// dueDatacenters returns the datacenters due to be polled at the provided
+// time and schedules their next poll. A datacenter due before the next tick
+// is polled right away, so the delay of the tick does not postpone its poll
+// by a whole tick
+func (d *Daemon) dueDatacenters(now time.Time, tick time.Duration) []sensu.Sensu {
+	if d.nextPoll == nil {
+		d.nextPoll = make(map[string]time.Time, len(*d.Datacenters))
+	}
+
+	var due []sensu.Sensu
+	for _, datacenter := range *d.Datacenters {
+		next, scheduled := d.nextPoll[datacenter.Name]
+		if scheduled && next.Sub(now) >= tick/2 {
+			continue
+		}
+
+		// The datacenters not scheduled yet were just polled on startup
+		if scheduled {
+			due = append(due, datacenter)
+		}
+		d.nextPoll[datacenter.Name] = now.Add(time.Duration(datacenterInterval(datacenter, d.interval)) * time.Second)
+	}
+
+	return due
+}
+
 // buildData method prepares fetched data
 func (d *Daemon) buildData() {
 	d.buildEvents()
@@ -99,18 +185,45 @@ func (d *Daemon) buildData() {
 
 // fetchData retrieves all data from each datacenter
 func (d *Daemon) fetchData() {
-	d.Data.Health.Sensu = make(map[string]structs.SensuHealth, len(*d.Datacenters))
+	if d.Data.LastPoll == nil {
+		d.Data.LastPoll = make(map[string]int64, len(*d.Datacenters))
+	}
+	if d.Data.CheckSamples == nil {
+		d.Data.CheckSamples = make(map[string][]structs.CheckSample)
+	}
 
+	d.fetchDatacenters(*d.Datacenters)
+}
+
+// fetchDatacenters retrieves all data from the provided datacenters, and
+// rebuilds the data with the last poll of every datacenter
+func (d *Daemon) fetchDatacenters(datacenters []sensu.Sensu) {
 	mutex := &sync.Mutex{}
 	wg := &sync.WaitGroup{}
 
-	for _, datacenter := range *d.Datacenters {
+	if d.snapshots == nil {
+		d.snapshots = make(map[string]*structs.Data, len(*d.Datacenters))
+	}
+
+	// The check samples are retained across the polls. They are recorded into
+	// a copy, since the current data is already shared with the handlers
+	checkSamples := copyCheckSamples(d.Data.CheckSamples)
+
+	for _, datacenter := range datacenters {
+		snapshot := &structs.Data{
+			CheckSamples: checkSamples,
+			Health:       structs.Health{Sensu: make(map[string]structs.SensuHealth, 1)},
+			LastPoll:     make(map[string]int64, 1),
+		}
+		d.snapshots[datacenter.Name] = snapshot
+
 		dc := DatacenterFetcher{
-			data:       d.Data,
-			datacenter: datacenter,
-			mutex:      mutex,
-			wg:         wg,
-			enterprise: d.Enterprise,
+			data:         snapshot,
+			datacenter:   datacenter,
+			mutex:        mutex,
+			wg:           wg,
+			checkSamples: d.CheckSamples,
+			enterprise:   d.Enterprise,
 		}
 
 		wg.Add(1)
@@ -118,6 +231,87 @@ func (d *Daemon) fetchData() {
 	}
 
 	wg.Wait()
+
+	d.mergeSnapshots()
+	d.Data.CheckSamples = checkSamples
+}
+
+// mergeSnapshots rebuilds the data from the last poll of every datacenter, in
+// the order of their configuration. The elements are copied, so the snapshots
+// are never modified once the data is built and shared with the handlers
+func (d *Daemon) mergeSnapshots() {
+	d.resetData()
+	d.Data.Health.Sensu = make(map[string]structs.SensuHealth, len(*d.Datacenters))
+
+	for _, datacenter := range *d.Datacenters {
+		snapshot, ok := d.snapshots[datacenter.Name]
+		if !ok {
+			continue
+		}
+
+		health := snapshot.Health.Sensu[datacenter.Name]
+		health.Interval = datacenterInterval(datacenter, d.interval)
+		d.Data.Health.Sensu[datacenter.Name] = health
+		d.Data.Health.Uchiwa = snapshot.Health.Uchiwa
+
+		if lastPoll, ok := snapshot.LastPoll[datacenter.Name]; ok {
+			d.Data.LastPoll[datacenter.Name] = lastPoll
+		}
+
+		d.Data.Dc = append(d.Data.Dc, snapshot.Dc...)
+		d.Data.Stashes = append(d.Data.Stashes, copyElements(snapshot.Stashes)...)
+		d.Data.Silenced = append(d.Data.Silenced, copyElements(snapshot.Silenced)...)
+		d.Data.Checks = append(d.Data.Checks, copyElements(snapshot.Checks)...)
+		d.Data.Clients = append(d.Data.Clients, copyElements(snapshot.Clients)...)
+		d.Data.Events = append(d.Data.Events, copyElements(snapshot.Events)...)
+		d.Data.Aggregates = append(d.Data.Aggregates, copyElements(snapshot.Aggregates)...)
+
+		d.Data.SERawMetrics.Clients = append(d.Data.SERawMetrics.Clients, snapshot.SERawMetrics.Clients...)
+		d.Data.SERawMetrics.Events = append(d.Data.SERawMetrics.Events, snapshot.SERawMetrics.Events...)
+		d.Data.SERawMetrics.KeepalivesAVG60 = append(d.Data.SERawMetrics.KeepalivesAVG60, snapshot.SERawMetrics.KeepalivesAVG60...)
+		d.Data.SERawMetrics.Requests = append(d.Data.SERawMetrics.Requests, snapshot.SERawMetrics.Requests...)
+		d.Data.SERawMetrics.Results = append(d.Data.SERawMetrics.Results, snapshot.SERawMetrics.Results...)
+	}
+}
+
+// failedDatacenters returns the datacenters that could not be polled
+func (d *Daemon) failedDatacenters() []sensu.Sensu {
+	polled := make(map[string]bool, len(d.Data.Dc))
+	for _, dc := range d.Data.Dc {
+		polled[dc.Name] = true
+	}
+
+	var failed []sensu.Sensu
+	for _, datacenter := range *d.Datacenters {
+		if !polled[datacenter.Name] {
+			failed = append(failed, datacenter)
+		}
+	}
+
+	return failed
+}
+
+// retryFailedDatacenters polls again, with an exponential backoff, the
+// datacenters that could not be polled, until they are all reachable or the
+// retries are exhausted
+func (d *Daemon) retryFailedDatacenters() {
+	delay := d.StartupRetryDelay
+
+	for retry := 1; retry <= d.StartupRetries; retry++ {
+		failed := d.failedDatacenters()
+		if len(failed) == 0 {
+			return
+		}
+
+		logger.Warningf("Retrying the initial poll of %d datacenter(s) in %s (%d/%d)", len(failed), delay, retry, d.StartupRetries)
+		time.Sleep(delay)
+		d.fetchDatacenters(failed)
+
+		delay *= 2
+		if delay > maxStartupRetryDelay {
+			delay = maxStartupRetryDelay
+		}
+	}
 }
 
 // fetch retrieves all data for a given datacenter
@@ -157,6 +351,11 @@ func (f *DatacenterFetcher) Fetch() {
 		go d.fetchEnterpriseMetrics()
 	}
 
+	if f.checkSamples > 0 {
+		wg.Add(1)
+		go d.fetchResults()
+	}
+
 	wg.Wait()
 
 	// update health
@@ -169,6 +368,13 @@ func (f *DatacenterFetcher) Fetch() {
 		return
 	}
 
+	// canonicalize the data of the datacenters using another schema
+	version := ""
+	if d.snapshot.Info != nil {
+		version = d.snapshot.Info.Sensu.Version
+	}
+	normalizeSnapshot(d.snapshot, resolveSchema(f.datacenter.Schema, version))
+
 	// build datacenter
 	dc := f.buildDatacenter(&d.datacenter.Name, d.snapshot.Info)
 	dc.Metrics["aggregates"] = len(d.snapshot.Aggregates)
@@ -180,6 +386,7 @@ func (f *DatacenterFetcher) Fetch() {
 
 	// update datacenter in the Daemon scope
 	f.mutex.Lock()
+	f.data.LastPoll[f.datacenter.Name] = time.Now().Unix()
 	f.data.Dc = append(f.data.Dc, dc)
 	f.data.Stashes = append(f.data.Stashes, d.snapshot.Stashes...)
 	f.data.Silenced = append(f.data.Silenced, d.snapshot.Silenced...)
@@ -188,6 +395,10 @@ func (f *DatacenterFetcher) Fetch() {
 	f.data.Events = append(f.data.Events, d.snapshot.Events...)
 	f.data.Aggregates = append(f.data.Aggregates, d.snapshot.Aggregates...)
 
+	if f.checkSamples > 0 {
+		recordCheckSamples(f.data.CheckSamples, f.datacenter.Name, d.snapshot.Results, f.checkSamples)
+	}
+
 	if f.enterprise {
 		f.data.SERawMetrics.Clients = append(f.data.SERawMetrics.Clients, d.metrics.Clients...)
 		f.data.SERawMetrics.Events = append(f.data.SERawMetrics.Events, d.metrics.Events...)
@@ -347,6 +558,20 @@ func (d *DatacenterSnapshotFetcher) fetchAggregates() {
 	d.mutex.Unlock()
 }
 
+func (d *DatacenterSnapshotFetcher) fetchResults() {
+	defer d.wg.Done()
+
+	results, err := d.datacenter.GetResults()
+	d.mutex.Lock()
+	if err != nil {
+		logger.Debug(err)
+		logger.Warningf("Impossible to retrieve the check results from the datacenter %s", d.datacenter.Name)
+	}
+
+	d.snapshot.Results = results
+	d.mutex.Unlock()
+}
+
 func (d *DatacenterSnapshotFetcher) fetchEnterpriseMetrics() {
 	defer d.wg.Done()
 
@@ -355,8 +580,27 @@ func (d *DatacenterSnapshotFetcher) fetchEnterpriseMetrics() {
 	d.mutex.Unlock()
 }
 
+// resetData replaces the data with an empty structure, while carrying over
+// the timestamps of the last successful poll of each datacenter and the
+// retained check samples
 func (d *Daemon) resetData() {
-	d.Data = &structs.Data{}
+	lastPoll := make(map[string]int64, len(d.Data.LastPoll))
+	for name, timestamp := range d.Data.LastPoll {
+		lastPoll[name] = timestamp
+	}
+
+	d.Data = &structs.Data{CheckSamples: copyCheckSamples(d.Data.CheckSamples), LastPoll: lastPoll}
+}
+
+// copyCheckSamples returns a copy of the provided check samples. The slices
+// are shared, since they are never modified once recorded
+func copyCheckSamples(samples map[string][]structs.CheckSample) map[string][]structs.CheckSample {
+	checkSamples := make(map[string][]structs.CheckSample, len(samples))
+	for key, s := range samples {
+		checkSamples[key] = s
+	}
+
+	return checkSamples
 }
 
 // getEnterpriseMetrics retrieves Sensu Enterprise metrics
@@ -2,8 +2,14 @@ package daemon
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/sensu/uchiwa/uchiwa/sensu"
 	"github.com/sensu/uchiwa/uchiwa/structs"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -44,3 +50,169 @@ func TestGetEnterpriseMetrics(t *testing.T) {
 
 	datacenter.AssertExpectations(t)
 }
+
+func TestResetData(t *testing.T) {
+	d := Daemon{Data: &structs.Data{LastPoll: map[string]int64{"us-east-1": 1500000000}}}
+	previous := d.Data
+
+	d.resetData()
+	assert.Equal(t, int64(1500000000), d.Data.LastPoll["us-east-1"])
+
+	// The new structure must not share its map with the previous one
+	d.Data.LastPoll["us-west-1"] = 1500000010
+	_, ok := previous.LastPoll["us-west-1"]
+	assert.False(t, ok)
+}
+
+func TestRetryFailedDatacenters(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first poll of the datacenter fails
+		if atomic.AddInt32(&requests, 1) <= 7 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		if r.URL.Path == "/info" {
+			fmt.Fprint(w, `{"sensu":{"version":"1.4.0"},"redis":{"connected":true},"transport":{"connected":true}}`)
+			return
+		}
+		fmt.Fprint(w, "[]")
+	}))
+	defer server.Close()
+
+	api := sensu.API{URL: server.URL, Timeout: 1}
+	api.Init()
+	d := Daemon{
+		Data:              &structs.Data{},
+		Datacenters:       &[]sensu.Sensu{{Name: "us-east-1", APIs: []sensu.API{api}}},
+		StartupRetries:    2,
+		StartupRetryDelay: time.Millisecond,
+	}
+
+	d.fetchData()
+	assert.Equal(t, 1, len(d.failedDatacenters()))
+
+	d.retryFailedDatacenters()
+	assert.Equal(t, 0, len(d.failedDatacenters()))
+	assert.Equal(t, "ok", d.Data.Health.Sensu["us-east-1"].Output)
+}
+
+func TestFetchDatacentersConcurrentRead(t *testing.T) {
+	var executed int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/info":
+			fmt.Fprint(w, `{"sensu":{"version":"1.4.0"},"redis":{"connected":true},"transport":{"connected":true}}`)
+		case "/results":
+			fmt.Fprintf(w, `[{"client":"foo","check":{"name":"cpu","executed":%d,"status":0}}]`, atomic.AddInt64(&executed, 1))
+		default:
+			fmt.Fprint(w, "[]")
+		}
+	}))
+	defer server.Close()
+
+	api := sensu.API{URL: server.URL, Timeout: 1}
+	api.Init()
+	d := Daemon{
+		CheckSamples: 10,
+		Data:         &structs.Data{},
+		Datacenters:  &[]sensu.Sensu{{Name: "us-east-1", APIs: []sensu.API{api}}},
+	}
+
+	d.fetchData()
+	shared := d.Data
+	assert.Equal(t, 1, len(shared.CheckSamples["us-east-1/cpu"]))
+
+	// The data shared with the handlers is read while the next polls run
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 3; i++ {
+			d.fetchDatacenters(*d.Datacenters)
+		}
+	}()
+
+	reading := true
+	for reading {
+		select {
+		case <-done:
+			reading = false
+		default:
+			for _, samples := range shared.CheckSamples {
+				_ = len(samples)
+			}
+		}
+	}
+
+	assert.Equal(t, 1, len(shared.CheckSamples["us-east-1/cpu"]))
+	assert.Equal(t, 4, len(d.Data.CheckSamples["us-east-1/cpu"]))
+}
+
+func TestTick(t *testing.T) {
+	d := Daemon{Datacenters: &[]sensu.Sensu{{Name: "us-east-1"}, {Name: "us-west-1", Interval: 15}}}
+	assert.Equal(t, 5, d.Tick(10))
+	assert.Equal(t, 15, d.Tick(0))
+
+	d.Datacenters = &[]sensu.Sensu{{Name: "us-east-1"}}
+	assert.Equal(t, 10, d.Tick(10))
+}
+
+func TestDueDatacenters(t *testing.T) {
+	d := Daemon{
+		Datacenters: &[]sensu.Sensu{{Name: "us-east-1"}, {Name: "us-west-1", Interval: 30}},
+		interval:    10,
+	}
+	now := time.Unix(1000, 0)
+	tick := 10 * time.Second
+
+	// The datacenters polled on startup are scheduled
+	assert.Equal(t, 0, len(d.dueDatacenters(now, 0)))
+
+	due := d.dueDatacenters(now.Add(10*time.Second), tick)
+	assert.Equal(t, 1, len(due))
+	assert.Equal(t, "us-east-1", due[0].Name)
+
+	// A tick slightly early still polls the due datacenters
+	due = d.dueDatacenters(now.Add(29*time.Second), tick)
+	assert.Equal(t, 2, len(due))
+
+	assert.Equal(t, 0, len(d.dueDatacenters(now.Add(30*time.Second), tick)))
+}
+
+func TestMergeSnapshots(t *testing.T) {
+	d := Daemon{
+		Data:        &structs.Data{},
+		Datacenters: &[]sensu.Sensu{{Name: "us-east-1", Interval: 60}, {Name: "us-west-1"}},
+		interval:    10,
+		snapshots: map[string]*structs.Data{
+			"us-west-1": {
+				Clients:  []interface{}{map[string]interface{}{"name": "bar", "dc": "us-west-1"}},
+				Dc:       []*structs.Datacenter{{Name: "us-west-1"}},
+				Health:   structs.Health{Sensu: map[string]structs.SensuHealth{"us-west-1": {Output: "ok"}}, Uchiwa: "ok"},
+				LastPoll: map[string]int64{"us-west-1": 1010},
+			},
+			"us-east-1": {
+				Clients:  []interface{}{map[string]interface{}{"name": "foo", "dc": "us-east-1"}},
+				Dc:       []*structs.Datacenter{{Name: "us-east-1"}},
+				Health:   structs.Health{Sensu: map[string]structs.SensuHealth{"us-east-1": {Output: "ok"}}, Uchiwa: "ok"},
+				LastPoll: map[string]int64{"us-east-1": 1000},
+			},
+		},
+	}
+
+	d.mergeSnapshots()
+
+	// The datacenters are merged in the order of the configuration
+	assert.Equal(t, 2, len(d.Data.Dc))
+	assert.Equal(t, "us-east-1", d.Data.Dc[0].Name)
+	assert.Equal(t, "foo", d.Data.Clients[0].(map[string]interface{})["name"])
+	assert.Equal(t, map[string]int64{"us-east-1": 1000, "us-west-1": 1010}, d.Data.LastPoll)
+	assert.Equal(t, 60, d.Data.Health.Sensu["us-east-1"].Interval)
+	assert.Equal(t, 10, d.Data.Health.Sensu["us-west-1"].Interval)
+
+	// The snapshots are not modified along with the data
+	d.Data.Clients[0].(map[string]interface{})["status"] = 2
+	_, ok := d.snapshots["us-east-1"].Clients[0].(map[string]interface{})["status"]
+	assert.False(t, ok)
+}
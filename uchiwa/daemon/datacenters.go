@@ -5,6 +5,7 @@ import "github.com/sensu/uchiwa/uchiwa/structs"
 func (f *DatacenterFetcher) buildDatacenter(name *string, info *structs.Info) *structs.Datacenter {
 	datacenter := structs.Datacenter{
 		Name:    *name,
+		Alias:   f.datacenter.Alias,
 		Info:    *info,
 		Metrics: make(map[string]int, 5),
 	}
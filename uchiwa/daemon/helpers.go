@@ -18,7 +18,7 @@ func FindDcFromInterface(data interface{}, datacenters *[]sensu.Sensu) (*sensu.S
 
 	id := m["dc"].(string)
 	if id == "" {
-		logger.Warningf("The received interface does not contain any datacenter information: ", data)
+		logger.Warningf("The received interface does not contain any datacenter information: %+v", data)
 		return nil, nil, errors.New("Could not determine the datacenter.")
 	}
 
@@ -70,3 +70,34 @@ func setDc(v interface{}, dc string) {
 		m["dc"] = dc
 	}
 }
+
+// copyElements returns a deep copy of the provided elements, as decoded from
+// JSON
+func copyElements(elements []interface{}) []interface{} {
+	if elements == nil {
+		return nil
+	}
+
+	copied := make([]interface{}, len(elements))
+	for i, element := range elements {
+		copied[i] = copyElement(element)
+	}
+
+	return copied
+}
+
+// copyElement returns a deep copy of the provided value, as decoded from JSON
+func copyElement(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(value))
+		for key, element := range value {
+			copied[key] = copyElement(element)
+		}
+		return copied
+	case []interface{}:
+		return copyElements(value)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,72 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/sensu/uchiwa/uchiwa/structs"
+)
+
+// recordCheckSamples appends the check executions found in the results of a
+// datacenter to the samples of each check, keyed by datacenter and check name.
+// Executions already recorded are ignored and only the most recent limit
+// samples of each check are retained
+func recordCheckSamples(samples map[string][]structs.CheckSample, dc string, results []interface{}, limit int) {
+	for _, r := range results {
+		result, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		client, ok := result["client"].(string)
+		if !ok {
+			continue
+		}
+
+		check, ok := result["check"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name, ok := check["name"].(string)
+		if !ok {
+			continue
+		}
+
+		executed, ok := check["executed"].(float64)
+		if !ok {
+			continue
+		}
+
+		sample := structs.CheckSample{Client: client, Executed: int64(executed)}
+		if duration, ok := check["duration"].(float64); ok {
+			sample.Duration = duration
+		}
+		if status, ok := check["status"].(float64); ok {
+			sample.Status = int(status)
+		}
+
+		key := fmt.Sprintf("%s/%s", dc, name)
+		if isSampleRecorded(sample, samples[key]) {
+			continue
+		}
+
+		// Always build a new slice so the samples of the previous data, which
+		// might still be read, are left untouched
+		existing := samples[key]
+		if len(existing) >= limit {
+			existing = existing[len(existing)-limit+1:]
+		}
+		recorded := make([]structs.CheckSample, 0, len(existing)+1)
+		recorded = append(recorded, existing...)
+		samples[key] = append(recorded, sample)
+	}
+}
+
+func isSampleRecorded(sample structs.CheckSample, samples []structs.CheckSample) bool {
+	for _, s := range samples {
+		if s.Client == sample.Client && s.Executed == sample.Executed {
+			return true
+		}
+	}
+	return false
+}
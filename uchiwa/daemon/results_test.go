@@ -0,0 +1,44 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/sensu/uchiwa/uchiwa/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordCheckSamples(t *testing.T) {
+	samples := make(map[string][]structs.CheckSample)
+	results := []interface{}{
+		map[string]interface{}{"client": "foo", "check": map[string]interface{}{"name": "cpu", "duration": 0.5, "executed": float64(100), "status": float64(0)}},
+		map[string]interface{}{"client": "bar", "check": map[string]interface{}{"name": "cpu", "duration": 1.5, "executed": float64(105), "status": float64(2)}},
+		map[string]interface{}{"client": "bar", "check": map[string]interface{}{"name": "disk"}},
+		"foo",
+	}
+
+	recordCheckSamples(samples, "us-east-1", results, 3)
+	assert.Equal(t, 1, len(samples))
+	assert.Equal(t, []structs.CheckSample{
+		{Client: "foo", Duration: 0.5, Executed: 100, Status: 0},
+		{Client: "bar", Duration: 1.5, Executed: 105, Status: 2},
+	}, samples["us-east-1/cpu"])
+
+	// The same executions are not recorded twice
+	recordCheckSamples(samples, "us-east-1", results, 3)
+	assert.Equal(t, 2, len(samples["us-east-1/cpu"]))
+
+	// Only the most recent samples are retained
+	previous := samples["us-east-1/cpu"]
+	results = []interface{}{
+		map[string]interface{}{"client": "foo", "check": map[string]interface{}{"name": "cpu", "duration": 0.7, "executed": float64(110), "status": float64(0)}},
+		map[string]interface{}{"client": "bar", "check": map[string]interface{}{"name": "cpu", "duration": 1.7, "executed": float64(115), "status": float64(1)}},
+	}
+	recordCheckSamples(samples, "us-east-1", results, 3)
+	assert.Equal(t, 3, len(samples["us-east-1/cpu"]))
+	assert.Equal(t, int64(105), samples["us-east-1/cpu"][0].Executed)
+	assert.Equal(t, int64(115), samples["us-east-1/cpu"][2].Executed)
+
+	// The previous slice is left untouched
+	assert.Equal(t, 2, len(previous))
+	assert.Equal(t, int64(100), previous[0].Executed)
+}
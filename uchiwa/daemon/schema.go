@@ -0,0 +1,136 @@
+package daemon
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Schemas of the JSON documents returned by the Sensu APIs. The classic
+// schema, used by Sensu 1.x, is the canonical one, expected by the filters and
+// the frontend
+const (
+	SchemaAuto    = "auto"
+	SchemaClassic = "1.x"
+	SchemaV2      = "2.x"
+)
+
+// detectSchema returns the schema used by the provided Sensu version. Unknown
+// versions are assumed to use the classic schema
+func detectSchema(version string) string {
+	major, err := strconv.Atoi(strings.SplitN(version, ".", 2)[0])
+	if err != nil || major < 2 {
+		return SchemaClassic
+	}
+
+	return SchemaV2
+}
+
+// resolveSchema returns the schema of a datacenter, either as configured or
+// as detected from the reported version
+func resolveSchema(configured, version string) string {
+	if configured == SchemaClassic || configured == SchemaV2 {
+		return configured
+	}
+
+	return detectSchema(version)
+}
+
+// normalizeSnapshot canonicalizes, in place, the checks, clients and events of
+// a datacenter snapshot that uses the provided schema
+func normalizeSnapshot(snapshot *DatacenterSnapshot, schema string) {
+	if schema != SchemaV2 {
+		return
+	}
+
+	for _, c := range snapshot.Checks {
+		if check, ok := c.(map[string]interface{}); ok {
+			normalizeCheck(check)
+		}
+	}
+
+	for _, c := range snapshot.Clients {
+		if client, ok := c.(map[string]interface{}); ok {
+			normalizeClient(client)
+		}
+	}
+
+	for _, e := range snapshot.Events {
+		if event, ok := e.(map[string]interface{}); ok {
+			normalizeEvent(event)
+		}
+	}
+}
+
+// normalizeName sets the name attribute of a 2.x object from its metadata,
+// or from the id attribute used by the earliest 2.x releases
+func normalizeName(m map[string]interface{}) {
+	if _, ok := m["name"].(string); ok {
+		return
+	}
+
+	if metadata, ok := m["metadata"].(map[string]interface{}); ok {
+		if name, ok := metadata["name"].(string); ok {
+			m["name"] = name
+			return
+		}
+	}
+
+	if id, ok := m["id"].(string); ok {
+		m["name"] = id
+	}
+}
+
+// renameKey moves the value of the key from to the key to, unless the latter
+// is already set
+func renameKey(m map[string]interface{}, from, to string) {
+	value, ok := m[from]
+	if !ok {
+		return
+	}
+
+	if _, ok := m[to]; !ok {
+		m[to] = value
+	}
+	delete(m, from)
+}
+
+// normalizeCheck canonicalizes a 2.x check, where the subscribers are named
+// subscriptions
+func normalizeCheck(check map[string]interface{}) {
+	normalizeName(check)
+	renameKey(check, "subscriptions", "subscribers")
+}
+
+// normalizeClient canonicalizes a 2.x entity, where the time of the last
+// keepalive is named last_seen
+func normalizeClient(client map[string]interface{}) {
+	normalizeName(client)
+	renameKey(client, "last_seen", "timestamp")
+}
+
+// normalizeEvent canonicalizes a 2.x event, where the client is named entity
+// and the status & output of the check may be found on the event itself
+func normalizeEvent(event map[string]interface{}) {
+	renameKey(event, "entity", "client")
+	if client, ok := event["client"].(map[string]interface{}); ok {
+		normalizeClient(client)
+	}
+
+	check, ok := event["check"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	normalizeCheck(check)
+
+	for _, key := range []string{"status", "output"} {
+		value, ok := event[key]
+		if !ok {
+			continue
+		}
+
+		if _, ok := check[key]; !ok {
+			check[key] = value
+		}
+		delete(event, key)
+	}
+}
@@ -0,0 +1,66 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveSchema(t *testing.T) {
+	assert.Equal(t, SchemaClassic, resolveSchema("", "1.2.0"))
+	assert.Equal(t, SchemaClassic, resolveSchema(SchemaAuto, "0.29.0"))
+	assert.Equal(t, SchemaClassic, resolveSchema("", ""))
+	assert.Equal(t, SchemaV2, resolveSchema("", "2.0.0-beta"))
+	assert.Equal(t, SchemaV2, resolveSchema(SchemaAuto, "5.1.0"))
+	assert.Equal(t, SchemaV2, resolveSchema(SchemaV2, "1.2.0"))
+	assert.Equal(t, SchemaClassic, resolveSchema(SchemaClassic, "2.0.0"))
+}
+
+func TestNormalizeSnapshot(t *testing.T) {
+	snapshot := &DatacenterSnapshot{
+		Checks: []interface{}{
+			map[string]interface{}{"metadata": map[string]interface{}{"name": "cpu"}, "subscriptions": []interface{}{"linux"}, "dc": "us-east-1"},
+		},
+		Clients: []interface{}{
+			map[string]interface{}{"id": "foo", "last_seen": float64(1000), "subscriptions": []interface{}{"linux"}, "dc": "us-east-1"},
+		},
+		Events: []interface{}{
+			map[string]interface{}{
+				"entity": map[string]interface{}{"metadata": map[string]interface{}{"name": "foo"}},
+				"check":  map[string]interface{}{"metadata": map[string]interface{}{"name": "cpu"}},
+				"status": float64(2),
+				"output": "CRITICAL",
+				"dc":     "us-east-1",
+			},
+			"foo",
+		},
+	}
+
+	// The classic schema is left untouched
+	normalizeSnapshot(snapshot, SchemaClassic)
+	assert.Nil(t, snapshot.Checks[0].(map[string]interface{})["name"])
+
+	normalizeSnapshot(snapshot, SchemaV2)
+
+	check := snapshot.Checks[0].(map[string]interface{})
+	assert.Equal(t, "cpu", check["name"])
+	assert.Equal(t, []interface{}{"linux"}, check["subscribers"])
+	assert.Nil(t, check["subscriptions"])
+
+	client := snapshot.Clients[0].(map[string]interface{})
+	assert.Equal(t, "foo", client["name"])
+	assert.Equal(t, float64(1000), client["timestamp"])
+	assert.Equal(t, []interface{}{"linux"}, client["subscriptions"])
+
+	event := snapshot.Events[0].(map[string]interface{})
+	assert.Nil(t, event["entity"])
+	assert.Nil(t, event["status"])
+	assert.Equal(t, "us-east-1", event["dc"])
+	assert.Equal(t, "foo", event["client"].(map[string]interface{})["name"])
+	assert.Equal(t, map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "cpu"},
+		"name":     "cpu",
+		"status":   float64(2),
+		"output":   "CRITICAL",
+	}, event["check"])
+}
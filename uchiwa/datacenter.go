@@ -2,10 +2,27 @@ package uchiwa
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/sensu/uchiwa/uchiwa/structs"
 )
 
+// rankedDatacenter holds the problem counts and health of a datacenter
+type rankedDatacenter struct {
+	Name     string              `json:"name"`
+	Critical int                 `json:"critical"`
+	Warning  int                 `json:"warning"`
+	Health   structs.SensuHealth `json:"health"`
+}
+
+// datacenterProblem holds the problems of a datacenter, i.e. whether it is
+// unreachable and its number of critical events
+type datacenterProblem struct {
+	Name        string `json:"name"`
+	Critical    int    `json:"critical"`
+	Unreachable bool   `json:"unreachable"`
+}
+
 func (u *Uchiwa) Datacenter(name string) (*structs.Datacenter, error) {
 	for _, dc := range u.Data.Dc {
 		if dc.Name == name {
@@ -15,3 +32,141 @@ func (u *Uchiwa) Datacenter(name string) (*structs.Datacenter, error) {
 
 	return nil, fmt.Errorf("")
 }
+
+// prioritizeDatacenters sorts the provided elements by the position of their
+// datacenter in the priority list. The elements of the datacenters missing
+// from the list come last, in their original order
+func prioritizeDatacenters(elements []interface{}, priority []string) []interface{} {
+	if len(priority) == 0 {
+		return elements
+	}
+
+	rank := func(e interface{}) int {
+		m, ok := e.(map[string]interface{})
+		if !ok {
+			return len(priority)
+		}
+
+		for i, dc := range priority {
+			if m["dc"] == dc {
+				return i
+			}
+		}
+		return len(priority)
+	}
+
+	sorted := make([]interface{}, len(elements))
+	copy(sorted, elements)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return rank(sorted[i]) < rank(sorted[j])
+	})
+
+	return sorted
+}
+
+// rankDatacenters sorts the provided datacenters by their number of critical
+// and warning events, worst first. Silenced events are ignored, like in the
+// events metrics
+func rankDatacenters(datacenters []*structs.Datacenter, events []interface{}, health map[string]structs.SensuHealth) []rankedDatacenter {
+	ranked := make([]rankedDatacenter, 0, len(datacenters))
+	index := make(map[string]int, len(datacenters))
+	for _, dc := range datacenters {
+		index[dc.Name] = len(ranked)
+		ranked = append(ranked, rankedDatacenter{Name: dc.Name, Health: health[dc.Name]})
+	}
+
+	for _, e := range events {
+		event, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if silenced, ok := event["silenced"].(bool); ok && silenced {
+			continue
+		}
+
+		dc, ok := event["dc"].(string)
+		if !ok {
+			continue
+		}
+
+		i, ok := index[dc]
+		if !ok {
+			continue
+		}
+
+		check, ok := event["check"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		switch check["status"] {
+		case 2.0:
+			ranked[i].Critical++
+		case 1.0:
+			ranked[i].Warning++
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a := ranked[i].Critical + ranked[i].Warning
+		b := ranked[j].Critical + ranked[j].Warning
+		if a != b {
+			return a > b
+		}
+		if ranked[i].Critical != ranked[j].Critical {
+			return ranked[i].Critical > ranked[j].Critical
+		}
+		return ranked[i].Name < ranked[j].Name
+	})
+
+	return ranked
+}
+
+// datacenterProblems returns the provided datacenters that are either
+// unreachable, according to their health, or have critical events, sorted by
+// name. Silenced events are ignored, like in the events metrics
+func datacenterProblems(datacenters []string, events []interface{}, health map[string]structs.SensuHealth) []datacenterProblem {
+	critical := make(map[string]int, len(datacenters))
+	for _, e := range events {
+		event, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if silenced, ok := event["silenced"].(bool); ok && silenced {
+			continue
+		}
+
+		dc, ok := event["dc"].(string)
+		if !ok {
+			continue
+		}
+
+		check, ok := event["check"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if check["status"] == 2.0 {
+			critical[dc]++
+		}
+	}
+
+	problems := make([]datacenterProblem, 0)
+	for _, name := range datacenters {
+		// The datacenters that could not be reached have a health status of 2
+		unreachable := health[name].Status == 2
+		if !unreachable && critical[name] == 0 {
+			continue
+		}
+
+		problems = append(problems, datacenterProblem{Name: name, Critical: critical[name], Unreachable: unreachable})
+	}
+
+	sort.Slice(problems, func(i, j int) bool {
+		return problems[i].Name < problems[j].Name
+	})
+
+	return problems
+}
@@ -0,0 +1,83 @@
+package uchiwa
+
+import (
+	"testing"
+
+	"github.com/sensu/uchiwa/uchiwa/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRankDatacenters(t *testing.T) {
+	datacenters := []*structs.Datacenter{
+		&structs.Datacenter{Name: "us-east-1"},
+		&structs.Datacenter{Name: "us-west-1"},
+		&structs.Datacenter{Name: "eu-west-1"},
+	}
+	events := []interface{}{
+		map[string]interface{}{"dc": "us-west-1", "check": map[string]interface{}{"status": 2.0}},
+		map[string]interface{}{"dc": "us-west-1", "check": map[string]interface{}{"status": 1.0}},
+		map[string]interface{}{"dc": "eu-west-1", "check": map[string]interface{}{"status": 1.0}},
+		map[string]interface{}{"dc": "eu-west-1", "check": map[string]interface{}{"status": 1.0}},
+		map[string]interface{}{"dc": "us-east-1", "check": map[string]interface{}{"status": 2.0}, "silenced": true},
+		map[string]interface{}{"dc": "ap-south-1", "check": map[string]interface{}{"status": 2.0}},
+	}
+	health := map[string]structs.SensuHealth{
+		"us-east-1": structs.SensuHealth{Output: "ok", Status: 0},
+	}
+
+	ranked := rankDatacenters(datacenters, events, health)
+	assert.Equal(t, 3, len(ranked))
+
+	assert.Equal(t, "us-west-1", ranked[0].Name)
+	assert.Equal(t, 1, ranked[0].Critical)
+	assert.Equal(t, 1, ranked[0].Warning)
+
+	assert.Equal(t, "eu-west-1", ranked[1].Name)
+	assert.Equal(t, 0, ranked[1].Critical)
+	assert.Equal(t, 2, ranked[1].Warning)
+
+	assert.Equal(t, "us-east-1", ranked[2].Name)
+	assert.Equal(t, 0, ranked[2].Critical)
+	assert.Equal(t, "ok", ranked[2].Health.Output)
+}
+
+func TestPrioritizeDatacenters(t *testing.T) {
+	elements := []interface{}{
+		map[string]interface{}{"name": "foo", "dc": "us-west-1"},
+		map[string]interface{}{"name": "foo", "dc": "eu-west-1"},
+		map[string]interface{}{"name": "foo", "dc": "us-east-1"},
+		map[string]interface{}{"name": "foo", "dc": "ap-south-1"},
+	}
+
+	// No priority configured
+	assert.Equal(t, elements, prioritizeDatacenters(elements, nil))
+
+	sorted := prioritizeDatacenters(elements, []string{"us-east-1", "eu-west-1"})
+	assert.Equal(t, []interface{}{elements[2], elements[1], elements[0], elements[3]}, sorted)
+	assert.Equal(t, "us-west-1", elements[0].(map[string]interface{})["dc"])
+}
+
+func TestDatacenterProblems(t *testing.T) {
+	datacenters := []string{"us-west-1", "us-east-1", "eu-west-1", "ap-south-1"}
+	events := []interface{}{
+		map[string]interface{}{"dc": "us-west-1", "check": map[string]interface{}{"status": 2.0}},
+		map[string]interface{}{"dc": "us-west-1", "check": map[string]interface{}{"status": 2.0}},
+		map[string]interface{}{"dc": "us-west-1", "check": map[string]interface{}{"status": 1.0}},
+		map[string]interface{}{"dc": "eu-west-1", "check": map[string]interface{}{"status": 1.0}},
+		map[string]interface{}{"dc": "ap-south-1", "check": map[string]interface{}{"status": 2.0}, "silenced": true},
+		map[string]interface{}{"dc": "foo", "check": map[string]interface{}{"status": 2.0}},
+	}
+	health := map[string]structs.SensuHealth{
+		"us-east-1": structs.SensuHealth{Output: "Connection error. Is the Sensu API running?", Status: 2},
+		"eu-west-1": structs.SensuHealth{Output: "ok", Status: 0},
+	}
+
+	problems := datacenterProblems(datacenters, events, health)
+	assert.Equal(t, []datacenterProblem{
+		{Name: "us-east-1", Critical: 0, Unreachable: true},
+		{Name: "us-west-1", Critical: 2, Unreachable: false},
+	}, problems)
+
+	// No problems
+	assert.Equal(t, []datacenterProblem{}, datacenterProblems(datacenters, nil, nil))
+}
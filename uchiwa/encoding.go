@@ -0,0 +1,140 @@
+package uchiwa
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Content codings of the responses
+const (
+	encodingDeflate  = "deflate"
+	encodingGzip     = "gzip"
+	encodingIdentity = "identity"
+)
+
+// acceptedEncoding is a content coding listed in an Accept-Encoding header,
+// along with its quality value
+type acceptedEncoding struct {
+	Coding  string  `json:"coding"`
+	Quality float64 `json:"quality"`
+}
+
+// encodingDiagnostics is the response of the /debug/encoding endpoint, which
+// describes how the encoding of the responses is negotiated for a request
+type encodingDiagnostics struct {
+	AcceptEncoding      string             `json:"accept_encoding"`
+	Accepted            []acceptedEncoding `json:"accepted"`
+	UserAgent           string             `json:"user_agent"`
+	ForcedByUserAgent   bool               `json:"forced_by_user_agent"`
+	Encoding            string             `json:"encoding"`
+	Compressed          bool               `json:"compressed"`
+	ForceGzipUserAgents []string           `json:"force_gzip_user_agents"`
+	MaxResponseBytes    int                `json:"max_response_bytes"`
+}
+
+// parseAcceptEncoding returns the content codings listed in an
+// Accept-Encoding header. The quality value defaults to 1 when it is missing
+// or invalid
+func parseAcceptEncoding(header string) []acceptedEncoding {
+	encodings := []acceptedEncoding{}
+	for _, element := range strings.Split(header, ",") {
+		parts := strings.Split(element, ";")
+		coding := strings.ToLower(strings.TrimSpace(parts[0]))
+		if coding == "" {
+			continue
+		}
+
+		encoding := acceptedEncoding{Coding: coding, Quality: 1}
+		for _, param := range parts[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+			if q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil && q >= 0 && q <= 1 {
+				encoding.Quality = q
+			}
+		}
+
+		encodings = append(encodings, encoding)
+	}
+
+	return encodings
+}
+
+// forcesGzip returns true if the user agent of the request was configured to
+// always receive compressed responses
+func (u *Uchiwa) forcesGzip(r *http.Request) bool {
+	userAgent := r.Header.Get("User-Agent")
+	if userAgent == "" {
+		return false
+	}
+
+	for _, pattern := range u.Config.Uchiwa.ForceGzipUserAgents {
+		if pattern != "" && strings.Contains(userAgent, pattern) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// selectEncoding returns the content coding of the response to the request:
+// the supported coding with the highest quality value in the Accept-Encoding
+// header, gzip being preferred on a tie. A coding with a quality value of 0 is
+// never selected. gzip is also selected for the user agents configured to
+// always receive compressed responses, unless they explicitly refuse it
+func (u *Uchiwa) selectEncoding(r *http.Request) string {
+	qualities := make(map[string]float64)
+	for _, encoding := range parseAcceptEncoding(r.Header.Get("Accept-Encoding")) {
+		qualities[encoding.Coding] = encoding.Quality
+	}
+
+	quality := func(coding string) (float64, bool) {
+		if q, ok := qualities[coding]; ok {
+			return q, true
+		}
+		q, ok := qualities["*"]
+		return q, ok
+	}
+
+	selected, best := encodingIdentity, 0.0
+	for _, coding := range []string{encodingGzip, encodingDeflate} {
+		if q, ok := quality(coding); ok && q > best {
+			selected, best = coding, q
+		}
+	}
+
+	if selected == encodingIdentity && u.forcesGzip(r) {
+		if q, ok := quality(encodingGzip); !ok || q > 0 {
+			selected = encodingGzip
+		}
+	}
+
+	return selected
+}
+
+// negotiateEncoding returns the content coding of the response to the
+// request. Since it depends on the request headers, they are listed in the
+// Vary header so the shared caches do not serve a compressed response to a
+// client that does not support it
+func (u *Uchiwa) negotiateEncoding(w http.ResponseWriter, r *http.Request) string {
+	addVary(w.Header(), "Accept-Encoding")
+	if len(u.Config.Uchiwa.ForceGzipUserAgents) > 0 {
+		addVary(w.Header(), "User-Agent")
+	}
+
+	return u.selectEncoding(r)
+}
+
+// acceptsCoding returns true if the provided coding is listed, directly or
+// through the wildcard, with a positive quality value
+func acceptsCoding(accepted []acceptedEncoding, coding string) bool {
+	for _, encoding := range accepted {
+		if (encoding.Coding == coding || encoding.Coding == "*") && encoding.Quality > 0 {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,108 @@
+package uchiwa
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sensu/uchiwa/uchiwa/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAcceptEncoding(t *testing.T) {
+	assert.Equal(t, []acceptedEncoding{}, parseAcceptEncoding(""))
+
+	expected := []acceptedEncoding{
+		{Coding: "gzip", Quality: 1},
+		{Coding: "deflate", Quality: 0.5},
+		{Coding: "br", Quality: 0},
+		{Coding: "identity", Quality: 1},
+	}
+	assert.Equal(t, expected, parseAcceptEncoding("GZIP, deflate;q=0.5, br; q=0, identity;q=foo"))
+}
+
+func TestDebugEncodingHandler(t *testing.T) {
+	u := &Uchiwa{Config: &config.Config{}}
+	u.Config.Uchiwa.ForceGzipUserAgents = []string{"NOCClient/"}
+
+	r := httptest.NewRequest("GET", "/debug/encoding", nil)
+	r.Header.Set("User-Agent", "NOCClient/1.2")
+	w := httptest.NewRecorder()
+	u.debugEncodingHandler(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The diagnostics are compressed like any other response
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	gz, err := gzip.NewReader(w.Body)
+	assert.Nil(t, err)
+
+	var diagnostics encodingDiagnostics
+	assert.Nil(t, json.NewDecoder(gz).Decode(&diagnostics))
+	assert.True(t, diagnostics.Compressed)
+	assert.True(t, diagnostics.ForcedByUserAgent)
+	assert.Equal(t, "gzip", diagnostics.Encoding)
+
+	r = httptest.NewRequest("GET", "/debug/encoding", nil)
+	w = httptest.NewRecorder()
+	u.debugEncodingHandler(w, r)
+
+	diagnostics = encodingDiagnostics{}
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &diagnostics))
+	assert.False(t, diagnostics.Compressed)
+	assert.Equal(t, "identity", diagnostics.Encoding)
+}
+
+func TestSelectEncoding(t *testing.T) {
+	u := &Uchiwa{Config: &config.Config{}}
+
+	encoding := func(acceptEncoding, userAgent string) string {
+		r := httptest.NewRequest("GET", "/events", nil)
+		if acceptEncoding != "" {
+			r.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		if userAgent != "" {
+			r.Header.Set("User-Agent", userAgent)
+		}
+		return u.selectEncoding(r)
+	}
+
+	assert.Equal(t, encodingIdentity, encoding("", ""))
+	assert.Equal(t, encodingGzip, encoding("gzip", ""))
+	assert.Equal(t, encodingGzip, encoding("gzip, deflate", ""))
+	assert.Equal(t, encodingGzip, encoding("deflate, gzip", ""))
+	assert.Equal(t, encodingDeflate, encoding("deflate", ""))
+	assert.Equal(t, encodingDeflate, encoding("gzip;q=0.5, deflate", ""))
+	assert.Equal(t, encodingDeflate, encoding("gzip;q=0, deflate", ""))
+	assert.Equal(t, encodingIdentity, encoding("gzip;q=0", ""))
+	assert.Equal(t, encodingGzip, encoding("*", ""))
+	assert.Equal(t, encodingIdentity, encoding("*;q=0", ""))
+	assert.Equal(t, encodingIdentity, encoding("br", ""))
+
+	// Forced gzip for a specific user agent, unless explicitly refused
+	u.Config.Uchiwa.ForceGzipUserAgents = []string{"NOCClient/"}
+	assert.Equal(t, encodingIdentity, encoding("", "Mozilla/5.0"))
+	assert.Equal(t, encodingGzip, encoding("", "NOCClient/1.2"))
+	assert.Equal(t, encodingIdentity, encoding("gzip;q=0", "NOCClient/1.2"))
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	u := &Uchiwa{Config: &config.Config{}}
+
+	r, _ := http.NewRequest("GET", "/events", nil)
+	w := httptest.NewRecorder()
+	assert.Equal(t, encodingIdentity, u.negotiateEncoding(w, r))
+	assert.Equal(t, []string{"Accept-Encoding"}, w.Header()["Vary"])
+
+	// The header is not listed twice
+	r.Header.Set("Accept-Encoding", "gzip")
+	assert.Equal(t, encodingGzip, u.negotiateEncoding(w, r))
+	assert.Equal(t, []string{"Accept-Encoding"}, w.Header()["Vary"])
+
+	// The user agent is listed when gzip can be forced
+	u.Config.Uchiwa.ForceGzipUserAgents = []string{"NOCClient/"}
+	w = httptest.NewRecorder()
+	assert.Equal(t, encodingIdentity, u.negotiateEncoding(w, httptest.NewRequest("GET", "/events", nil)))
+	assert.Equal(t, []string{"Accept-Encoding", "User-Agent"}, w.Header()["Vary"])
+}
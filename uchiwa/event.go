@@ -1,6 +1,41 @@
 package uchiwa
 
-import "github.com/sensu/uchiwa/uchiwa/logger"
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sensu/uchiwa/uchiwa/helpers"
+	"github.com/sensu/uchiwa/uchiwa/logger"
+)
+
+// eventResolution contains the result of the resolution of an event
+type eventResolution struct {
+	Check  string `json:"check"`
+	Client string `json:"client"`
+	Dc     string `json:"dc"`
+	Error  string `json:"error,omitempty"`
+}
+
+// eventOutputGroup contains a representative of the events sharing the same
+// check name and output, along with their number and affected clients
+type eventOutputGroup struct {
+	Event   interface{}      `json:"event"`
+	Count   int              `json:"count"`
+	Clients []eventGroupItem `json:"clients"`
+}
+
+// eventGroupItem identifies a client affected by a group of events
+type eventGroupItem struct {
+	Name string `json:"name"`
+	Dc   string `json:"dc"`
+}
+
+// eventStatusCount contains the number of events with a given check status
+type eventStatusCount struct {
+	Status int `json:"status"`
+	Count  int `json:"count"`
+}
 
 // ResolveEvent sends a DELETE request in order to
 // resolve an event for a given check on a given client
@@ -19,3 +54,233 @@ func (u *Uchiwa) ResolveEvent(check, client, dc string) error {
 
 	return nil
 }
+
+// clientEventsChecks returns the name of the checks of every event
+// associated to a given client
+func clientEventsChecks(client, dc string, events []interface{}) []string {
+	var checks []string
+
+	for _, e := range events {
+		event, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if event["dc"] != dc {
+			continue
+		}
+
+		c, ok := event["client"].(map[string]interface{})
+		if !ok || c["name"] != client {
+			continue
+		}
+
+		k, ok := event["check"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		check, ok := k["name"].(string)
+		if !ok || check == "" {
+			continue
+		}
+
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+// isNeverOK determines whether the check of an event has never been in an OK
+// state. The last_ok attribute of the event is authoritative when available,
+// otherwise the check history is used
+func isNeverOK(event map[string]interface{}) bool {
+	if lastOK, ok := event["last_ok"]; ok {
+		timestamp, ok := lastOK.(float64)
+		return !ok || timestamp == 0
+	}
+
+	check, ok := event["check"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	history, ok := check["history"].([]interface{})
+	if !ok || len(history) == 0 {
+		return false
+	}
+
+	for _, status := range history {
+		if fmt.Sprint(status) == "0" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// eventDuration returns, in seconds, for how long the check of an event has
+// been continuously in a problem state, based on its last OK and issued
+// timestamps. False is returned when these timestamps are not available
+func eventDuration(event map[string]interface{}, now time.Time) (int64, bool) {
+	lastOK, ok := event["last_ok"].(float64)
+	if !ok || lastOK <= 0 {
+		return 0, false
+	}
+
+	// The check has been OK since it was last issued
+	if check, ok := event["check"].(map[string]interface{}); ok {
+		if issued, ok := check["issued"].(float64); ok && issued <= lastOK {
+			return 0, true
+		}
+	}
+
+	duration := now.Unix() - int64(lastOK)
+	if duration < 0 {
+		duration = 0
+	}
+
+	return duration, true
+}
+
+// sortEventsByDuration orders the events by how long they have been in a
+// problem state, the longest first unless ascending is true. The events
+// without a known duration are always ordered last
+func sortEventsByDuration(events []interface{}, now time.Time, ascending bool) []interface{} {
+	type eventWithDuration struct {
+		event    interface{}
+		duration int64
+		known    bool
+	}
+
+	sorted := make([]eventWithDuration, 0, len(events))
+	for _, e := range events {
+		element := eventWithDuration{event: e}
+		if event, ok := e.(map[string]interface{}); ok {
+			element.duration, element.known = eventDuration(event, now)
+		}
+		sorted = append(sorted, element)
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].known != sorted[j].known {
+			return sorted[i].known
+		}
+		if ascending {
+			return sorted[i].duration < sorted[j].duration
+		}
+		return sorted[i].duration > sorted[j].duration
+	})
+
+	result := make([]interface{}, 0, len(sorted))
+	for _, element := range sorted {
+		result = append(result, element.event)
+	}
+
+	return result
+}
+
+// groupEventsByOutput groups the events sharing the same check name and
+// output. The first event of each group is its representative, and the
+// groups are returned in the order of their representative
+func groupEventsByOutput(events []interface{}) []interface{} {
+	var groups []*eventOutputGroup
+	index := make(map[string]*eventOutputGroup)
+
+	for _, e := range events {
+		event, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		check, _ := event["check"].(map[string]interface{})
+		client, _ := event["client"].(map[string]interface{})
+		name, _ := check["name"].(string)
+		output, _ := check["output"].(string)
+		clientName, _ := client["name"].(string)
+		dc, _ := event["dc"].(string)
+
+		key := name + "\x00" + output
+		group, ok := index[key]
+		if !ok {
+			group = &eventOutputGroup{Event: event, Clients: []eventGroupItem{}}
+			index[key] = group
+			groups = append(groups, group)
+		}
+
+		group.Count++
+		group.Clients = append(group.Clients, eventGroupItem{Name: clientName, Dc: dc})
+	}
+
+	result := make([]interface{}, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, *group)
+	}
+
+	return result
+}
+
+// countEventStatuses returns the distinct check statuses of the provided
+// events, along with their number of events, ordered by status
+func countEventStatuses(events []interface{}) []eventStatusCount {
+	counts := make(map[int]int)
+	for _, e := range events {
+		event, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		check, ok := event["check"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		status, ok := check["status"].(float64)
+		if !ok {
+			continue
+		}
+
+		counts[int(status)]++
+	}
+
+	statuses := make([]eventStatusCount, 0, len(counts))
+	for status, count := range counts {
+		statuses = append(statuses, eventStatusCount{Status: status, Count: count})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Status < statuses[j].Status
+	})
+
+	return statuses
+}
+
+// filterSilencedEvents returns the events that are, or are not, currently
+// suppressed by one of the provided silence entries
+func filterSilencedEvents(events, silenced []interface{}, isSilenced bool) []interface{} {
+	filtered := make([]interface{}, 0)
+	for _, e := range events {
+		event, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		check, ok := event["check"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		client, ok := event["client"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		dc, _ := event["dc"].(string)
+
+		if s, _ := helpers.IsCheckSilenced(check, client, dc, silenced); s == isSilenced {
+			filtered = append(filtered, event)
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,229 @@
+package uchiwa
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sensu/uchiwa/uchiwa/audit"
+	"github.com/sensu/uchiwa/uchiwa/config"
+	"github.com/sensu/uchiwa/uchiwa/filters"
+	"github.com/sensu/uchiwa/uchiwa/sensu"
+	"github.com/sensu/uchiwa/uchiwa/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientEventsChecks(t *testing.T) {
+	events := []interface{}{
+		map[string]interface{}{"check": map[string]interface{}{"name": "cpu"}, "client": map[string]interface{}{"name": "foo"}, "dc": "us-east-1"},
+		map[string]interface{}{"check": map[string]interface{}{"name": "ram"}, "client": map[string]interface{}{"name": "foo"}, "dc": "us-east-1"},
+		map[string]interface{}{"check": map[string]interface{}{"name": "disk"}, "client": map[string]interface{}{"name": "bar"}, "dc": "us-east-1"},
+		map[string]interface{}{"check": map[string]interface{}{"name": "cpu"}, "client": map[string]interface{}{"name": "foo"}, "dc": "us-west-1"},
+		map[string]interface{}{"check": "cpu", "client": "foo", "dc": "us-east-1"},
+	}
+
+	checks := clientEventsChecks("foo", "us-east-1", events)
+	assert.Equal(t, []string{"cpu", "ram"}, checks)
+
+	checks = clientEventsChecks("foo", "us-west-1", events)
+	assert.Equal(t, []string{"cpu"}, checks)
+
+	checks = clientEventsChecks("qux", "us-east-1", events)
+	assert.Empty(t, checks)
+}
+
+func TestIsNeverOK(t *testing.T) {
+	// last_ok is authoritative
+	event := map[string]interface{}{"last_ok": nil, "check": map[string]interface{}{"history": []interface{}{"0", "2"}}}
+	assert.True(t, isNeverOK(event))
+
+	event = map[string]interface{}{"last_ok": 1500000000.0}
+	assert.False(t, isNeverOK(event))
+
+	event = map[string]interface{}{"last_ok": 0.0}
+	assert.True(t, isNeverOK(event))
+
+	// Fallback on the check history
+	event = map[string]interface{}{"check": map[string]interface{}{"history": []interface{}{"2", "2", "1"}}}
+	assert.True(t, isNeverOK(event))
+
+	event = map[string]interface{}{"check": map[string]interface{}{"history": []interface{}{"2", "0", "2"}}}
+	assert.False(t, isNeverOK(event))
+
+	// Not enough information
+	event = map[string]interface{}{"check": map[string]interface{}{"history": []interface{}{}}}
+	assert.False(t, isNeverOK(event))
+
+	event = map[string]interface{}{"check": "cpu"}
+	assert.False(t, isNeverOK(event))
+}
+
+func TestEventDuration(t *testing.T) {
+	now := time.Unix(1000, 0)
+
+	duration, ok := eventDuration(map[string]interface{}{"last_ok": float64(400), "check": map[string]interface{}{"issued": float64(990)}}, now)
+	assert.True(t, ok)
+	assert.Equal(t, int64(600), duration)
+
+	// OK since it was last issued
+	duration, ok = eventDuration(map[string]interface{}{"last_ok": float64(990), "check": map[string]interface{}{"issued": float64(990)}}, now)
+	assert.True(t, ok)
+	assert.Equal(t, int64(0), duration)
+
+	// Missing timestamp
+	_, ok = eventDuration(map[string]interface{}{"check": map[string]interface{}{"issued": float64(990)}}, now)
+	assert.False(t, ok)
+}
+
+func TestSortEventsByDuration(t *testing.T) {
+	now := time.Unix(1000, 0)
+	events := []interface{}{
+		map[string]interface{}{"id": "a", "last_ok": float64(900)},
+		map[string]interface{}{"id": "b"},
+		map[string]interface{}{"id": "c", "last_ok": float64(100)},
+		map[string]interface{}{"id": "d", "last_ok": float64(500)},
+	}
+
+	ids := func(events []interface{}) []string {
+		var result []string
+		for _, e := range events {
+			result = append(result, e.(map[string]interface{})["id"].(string))
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"c", "d", "a", "b"}, ids(sortEventsByDuration(events, now, false)))
+	assert.Equal(t, []string{"a", "d", "c", "b"}, ids(sortEventsByDuration(events, now, true)))
+}
+
+func TestGroupEventsByOutput(t *testing.T) {
+	event := func(client, dc, check, output string) map[string]interface{} {
+		return map[string]interface{}{
+			"dc":     dc,
+			"check":  map[string]interface{}{"name": check, "output": output},
+			"client": map[string]interface{}{"name": client},
+		}
+	}
+	foo := event("foo", "us-east-1", "disk", "Disk full")
+	bar := event("bar", "us-west-1", "disk", "Disk full")
+	baz := event("baz", "us-east-1", "disk", "Disk almost full")
+	qux := event("qux", "us-east-1", "cpu", "Disk full")
+
+	groups := groupEventsByOutput([]interface{}{foo, baz, bar, qux, "invalid"})
+	assert.Equal(t, 3, len(groups))
+
+	group := groups[0].(eventOutputGroup)
+	assert.Equal(t, foo, group.Event)
+	assert.Equal(t, 2, group.Count)
+	assert.Equal(t, []eventGroupItem{{Name: "foo", Dc: "us-east-1"}, {Name: "bar", Dc: "us-west-1"}}, group.Clients)
+
+	assert.Equal(t, baz, groups[1].(eventOutputGroup).Event)
+	assert.Equal(t, qux, groups[2].(eventOutputGroup).Event)
+	assert.Equal(t, 1, groups[2].(eventOutputGroup).Count)
+}
+
+func TestCountEventStatuses(t *testing.T) {
+	assert.Equal(t, []eventStatusCount{}, countEventStatuses(nil))
+
+	events := []interface{}{
+		map[string]interface{}{"check": map[string]interface{}{"status": 2.0}},
+		map[string]interface{}{"check": map[string]interface{}{"status": 1.0}},
+		map[string]interface{}{"check": map[string]interface{}{"status": 2.0}},
+		map[string]interface{}{"check": map[string]interface{}{"status": 127.0}},
+		map[string]interface{}{"check": map[string]interface{}{}},
+		"foo",
+	}
+
+	expected := []eventStatusCount{
+		{Status: 1, Count: 1},
+		{Status: 2, Count: 2},
+		{Status: 127, Count: 1},
+	}
+	assert.Equal(t, expected, countEventStatuses(events))
+}
+
+func TestFilterSilencedEvents(t *testing.T) {
+	silenced := []interface{}{
+		map[string]interface{}{"dc": "us-east-1", "id": "*:cpu", "check": "cpu"},
+	}
+	events := []interface{}{
+		map[string]interface{}{"dc": "us-east-1", "check": map[string]interface{}{"name": "cpu"}, "client": map[string]interface{}{"name": "foo"}},
+		map[string]interface{}{"dc": "us-east-1", "check": map[string]interface{}{"name": "disk"}, "client": map[string]interface{}{"name": "foo"}},
+		map[string]interface{}{"dc": "us-west-1", "check": map[string]interface{}{"name": "cpu"}, "client": map[string]interface{}{"name": "bar"}},
+	}
+
+	result := filterSilencedEvents(events, silenced, true)
+	assert.Equal(t, []interface{}{events[0]}, result)
+
+	result = filterSilencedEvents(events, silenced, false)
+	assert.Equal(t, []interface{}{events[1], events[2]}, result)
+
+	// No silence entries
+	result = filterSilencedEvents(events, nil, true)
+	assert.Equal(t, []interface{}{}, result)
+}
+
+func TestEventsBulkHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/events/bar/cpu" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	var logs []structs.AuditLog
+	audit.Log = func(log structs.AuditLog) error {
+		logs = append(logs, log)
+		return nil
+	}
+	defer func() { audit.Log = nil }()
+
+	api := sensu.API{URL: server.URL, Timeout: 1}
+	api.Init()
+	Filters = &filters.Uchiwa{}
+	u := &Uchiwa{
+		Config:      &config.Config{},
+		Data:        &structs.Data{},
+		Datacenters: &[]sensu.Sensu{{Name: "us-east-1", APIs: []sensu.API{api}}},
+		Mu:          &sync.Mutex{},
+	}
+
+	body := `[{"client":"foo","check":"cpu","dc":"us-east-1"},{"client":"bar","check":"cpu","dc":"us-east-1"},{"client":"foo","dc":"us-east-1"}]`
+	r, _ := http.NewRequest("POST", "/events/bulk", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	u.eventsBulkHandler(w, r)
+	assert.Equal(t, http.StatusMultiStatus, w.Code)
+
+	var results []eventResolution
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &results))
+	assert.Equal(t, 3, len(results))
+	assert.Equal(t, eventResolution{Check: "cpu", Client: "foo", Dc: "us-east-1"}, results[0])
+	assert.NotEqual(t, "", results[1].Error)
+	assert.Equal(t, "The client, check and dc are required", results[2].Error)
+
+	// Only the successful resolution is audited
+	assert.Equal(t, 1, len(logs))
+	assert.Equal(t, "resolve", logs[0].Action)
+	assert.Equal(t, "/events/foo/cpu?dc=us-east-1", logs[0].URL)
+
+	// Every event resolved
+	r, _ = http.NewRequest("POST", "/events/bulk", strings.NewReader(`[{"client":"foo","check":"cpu","dc":"us-east-1"}]`))
+	r.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	u.eventsBulkHandler(w, r)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	// No event provided
+	r, _ = http.NewRequest("POST", "/events/bulk", strings.NewReader(`[]`))
+	r.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	u.eventsBulkHandler(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,205 @@
+package uchiwa
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// eventTombstonesLimit is the number of removed events remembered in order to
+// report them to the clients. Older cursors require a full resync
+const eventTombstonesLimit = 1000
+
+// versionedEvent holds an event along with the version at which it was last
+// added, changed or removed
+type versionedEvent struct {
+	id      string
+	version uint64
+	event   map[string]interface{}
+}
+
+// eventLog keeps track of the version of each event across the polls, so the
+// changes since a given version can be retrieved. It must be accessed with
+// the mutex held
+type eventLog struct {
+	// epoch identifies this log, so the cursors issued by a previous process
+	// are not mistaken for the current ones
+	epoch   int64
+	version uint64
+	// oldest is the oldest version from which the changes are still known
+	oldest  uint64
+	events  map[string]versionedEvent
+	removed []versionedEvent
+}
+
+// eventChanges holds the events added, changed or removed since a cursor, or
+// every event if the cursor could not be honored and resync is true
+type eventChanges struct {
+	cursor  string
+	resync  bool
+	events  []interface{}
+	removed []interface{}
+}
+
+// eventsDelta is the response of the /events/since endpoint
+type eventsDelta struct {
+	Cursor  string        `json:"cursor"`
+	Resync  bool          `json:"resync"`
+	Events  []interface{} `json:"events"`
+	Removed []string      `json:"removed"`
+}
+
+// newEventLog returns an empty event log identified by the provided epoch
+func newEventLog(epoch int64) *eventLog {
+	return &eventLog{
+		epoch:  epoch,
+		events: make(map[string]versionedEvent),
+	}
+}
+
+// cursor returns the cursor pointing to the current version
+func (l *eventLog) cursor() string {
+	return fmt.Sprintf("%d.%d", l.epoch, l.version)
+}
+
+// parseCursor returns the version pointed to by the provided cursor, or false
+// if the cursor was not issued by this log or is too old
+func (l *eventLog) parseCursor(cursor string) (uint64, bool) {
+	parts := strings.Split(cursor, ".")
+	if len(parts) != 2 {
+		return 0, false
+	}
+
+	epoch, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || epoch != l.epoch {
+		return 0, false
+	}
+
+	version, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil || version < l.oldest || version > l.version {
+		return 0, false
+	}
+
+	return version, true
+}
+
+// update records the provided events as the current ones. A new version is
+// assigned to the events that were added, changed or removed since the last
+// update
+func (l *eventLog) update(events []interface{}) {
+	next := l.version + 1
+	changed := false
+	current := make(map[string]bool, len(events))
+
+	for _, e := range events {
+		event, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		id, ok := event["_id"].(string)
+		if !ok {
+			continue
+		}
+		current[id] = true
+
+		if previous, ok := l.events[id]; ok && reflect.DeepEqual(previous.event, event) {
+			continue
+		}
+
+		l.events[id] = versionedEvent{id: id, version: next, event: event}
+		l.forget(id)
+		changed = true
+	}
+
+	for id, previous := range l.events {
+		if current[id] {
+			continue
+		}
+
+		delete(l.events, id)
+		previous.version = next
+		l.removed = append(l.removed, previous)
+		changed = true
+	}
+
+	if !changed {
+		return
+	}
+	l.version = next
+
+	if extra := len(l.removed) - eventTombstonesLimit; extra > 0 {
+		l.oldest = l.removed[extra-1].version
+		l.removed = append([]versionedEvent(nil), l.removed[extra:]...)
+	}
+}
+
+// forget drops the tombstone of an event that was added back
+func (l *eventLog) forget(id string) {
+	for i, tombstone := range l.removed {
+		if tombstone.id == id {
+			l.removed = append(l.removed[:i], l.removed[i+1:]...)
+			return
+		}
+	}
+}
+
+// since returns the changes since the provided cursor. Every current event is
+// returned, with resync set, if the cursor is invalid or too old
+func (l *eventLog) since(cursor string) eventChanges {
+	changes := eventChanges{cursor: l.cursor()}
+
+	version, ok := l.parseCursor(cursor)
+	if !ok {
+		changes.resync = true
+	}
+
+	var changed []versionedEvent
+	for _, e := range l.events {
+		if changes.resync || e.version > version {
+			changed = append(changed, e)
+		}
+	}
+
+	sort.Slice(changed, func(i, j int) bool {
+		if changed[i].version != changed[j].version {
+			return changed[i].version < changed[j].version
+		}
+		return changed[i].id < changed[j].id
+	})
+
+	for _, e := range changed {
+		changes.events = append(changes.events, e.event)
+	}
+
+	if changes.resync {
+		return changes
+	}
+
+	for _, e := range l.removed {
+		if e.version > version {
+			changes.removed = append(changes.removed, e.event)
+		}
+	}
+
+	return changes
+}
+
+// eventIDs returns the identifier of each provided event
+func eventIDs(events []interface{}) []string {
+	ids := make([]string, 0, len(events))
+	for _, e := range events {
+		event, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if id, ok := event["_id"].(string); ok {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
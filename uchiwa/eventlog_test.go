@@ -0,0 +1,77 @@
+package uchiwa
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventLog(t *testing.T) {
+	l := newEventLog(42)
+	foo := map[string]interface{}{"_id": "us-east-1/foo/cpu", "occurrences": 1}
+	bar := map[string]interface{}{"_id": "us-east-1/bar/cpu", "occurrences": 1}
+
+	// Unknown cursors require a resync
+	changes := l.since("")
+	assert.True(t, changes.resync)
+	assert.Equal(t, "42.0", changes.cursor)
+
+	l.update([]interface{}{foo, bar, "qux"})
+	changes = l.since("42.0")
+	assert.False(t, changes.resync)
+	assert.Equal(t, "42.1", changes.cursor)
+	assert.Equal(t, []interface{}{bar, foo}, changes.events)
+	assert.Nil(t, changes.removed)
+
+	// Nothing changed
+	l.update([]interface{}{
+		map[string]interface{}{"_id": "us-east-1/foo/cpu", "occurrences": 1},
+		map[string]interface{}{"_id": "us-east-1/bar/cpu", "occurrences": 1},
+	})
+	changes = l.since("42.1")
+	assert.Equal(t, "42.1", changes.cursor)
+	assert.Nil(t, changes.events)
+
+	// foo changed and bar was resolved
+	foo2 := map[string]interface{}{"_id": "us-east-1/foo/cpu", "occurrences": 2}
+	l.update([]interface{}{foo2})
+	changes = l.since("42.1")
+	assert.Equal(t, "42.2", changes.cursor)
+	assert.Equal(t, []interface{}{foo2}, changes.events)
+	assert.Equal(t, []string{"us-east-1/bar/cpu"}, eventIDs(changes.removed))
+
+	// Every change since the first version
+	changes = l.since("42.0")
+	assert.Equal(t, []interface{}{foo2}, changes.events)
+	assert.Equal(t, 1, len(changes.removed))
+
+	// bar is back
+	l.update([]interface{}{foo2, bar})
+	changes = l.since("42.2")
+	assert.Equal(t, []interface{}{bar}, changes.events)
+	assert.Nil(t, changes.removed)
+
+	// Cursors from another epoch, from the future or invalid
+	for _, cursor := range []string{"41.1", "42.9", "foo", "42.foo"} {
+		changes = l.since(cursor)
+		assert.True(t, changes.resync, cursor)
+		assert.Equal(t, 2, len(changes.events), cursor)
+		assert.Nil(t, changes.removed, cursor)
+	}
+}
+
+func TestEventLogRetention(t *testing.T) {
+	l := newEventLog(1)
+
+	for i := 0; i <= eventTombstonesLimit; i++ {
+		l.update([]interface{}{map[string]interface{}{"_id": fmt.Sprintf("us-east-1/foo/%d", i)}})
+	}
+	l.update([]interface{}{})
+
+	// The oldest removal was forgotten
+	assert.Equal(t, eventTombstonesLimit, len(l.removed))
+	assert.True(t, l.since("1.1").resync)
+	assert.False(t, l.since("1.2").resync)
+	assert.Equal(t, eventTombstonesLimit, len(l.since("1.2").removed))
+}
@@ -0,0 +1,82 @@
+package uchiwa
+
+import (
+	"time"
+
+	"github.com/sensu/uchiwa/uchiwa/structs"
+)
+
+// eventRatePoint holds the number of events of a datacenter at a given time
+type eventRatePoint struct {
+	Timestamp int64 `json:"timestamp"`
+	Count     int   `json:"count"`
+}
+
+// eventRateLog samples the number of events of each datacenter across the
+// polls. It must be accessed with the mutex held
+type eventRateLog struct {
+	last   time.Time
+	series map[string][]eventRatePoint
+}
+
+// update samples the number of events of each polled datacenter, unless the
+// previous sample was taken less than interval seconds ago. The samples older
+// than retention seconds are dropped
+func (l *eventRateLog) update(data *structs.Data, now time.Time, interval, retention int) {
+	if len(data.Dc) == 0 || now.Sub(l.last) < time.Duration(interval)*time.Second {
+		return
+	}
+	l.last = now
+
+	if l.series == nil {
+		l.series = make(map[string][]eventRatePoint)
+	}
+
+	counts := make(map[string]int, len(data.Dc))
+	for _, dc := range data.Dc {
+		counts[dc.Name] = 0
+	}
+
+	for _, e := range data.Events {
+		event, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		dc, _ := event["dc"].(string)
+		if _, ok := counts[dc]; ok {
+			counts[dc]++
+		}
+	}
+
+	for dc, count := range counts {
+		l.series[dc] = append(l.series[dc], eventRatePoint{Timestamp: now.Unix(), Count: count})
+	}
+
+	oldest := now.Unix() - int64(retention)
+	for dc, points := range l.series {
+		i := 0
+		for i < len(points) && points[i].Timestamp < oldest {
+			i++
+		}
+
+		if i == len(points) {
+			delete(l.series, dc)
+		} else if i > 0 {
+			l.series[dc] = append([]eventRatePoint(nil), points[i:]...)
+		}
+	}
+}
+
+// since returns the samples of the provided datacenter taken since the
+// provided time
+func (l *eventRateLog) since(dc string, t time.Time) []eventRatePoint {
+	points := []eventRatePoint{}
+	for _, point := range l.series[dc] {
+		if point.Timestamp >= t.Unix() {
+			points = append(points, point)
+		}
+	}
+
+	return points
+}
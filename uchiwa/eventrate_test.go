@@ -0,0 +1,44 @@
+package uchiwa
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sensu/uchiwa/uchiwa/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventRateLog(t *testing.T) {
+	l := &eventRateLog{}
+	now := time.Unix(1000, 0)
+
+	data := &structs.Data{
+		Dc: []*structs.Datacenter{{Name: "us-east-1"}, {Name: "us-west-1"}},
+		Events: []interface{}{
+			map[string]interface{}{"dc": "us-east-1"},
+			map[string]interface{}{"dc": "us-east-1"},
+			map[string]interface{}{"dc": "us-west-1"},
+		},
+	}
+
+	l.update(data, now, 60, 300)
+	assert.Equal(t, []eventRatePoint{{Timestamp: 1000, Count: 2}}, l.since("us-east-1", time.Unix(0, 0)))
+	assert.Equal(t, []eventRatePoint{{Timestamp: 1000, Count: 1}}, l.since("us-west-1", time.Unix(0, 0)))
+
+	// Sampled at most once per interval
+	l.update(data, now.Add(30*time.Second), 60, 300)
+	assert.Equal(t, 1, len(l.since("us-east-1", time.Unix(0, 0))))
+
+	// An unreachable datacenter is not sampled
+	data.Dc = data.Dc[:1]
+	data.Events = data.Events[:1]
+	l.update(data, now.Add(60*time.Second), 60, 300)
+	assert.Equal(t, []eventRatePoint{{Timestamp: 1000, Count: 2}, {Timestamp: 1060, Count: 1}}, l.since("us-east-1", time.Unix(0, 0)))
+	assert.Equal(t, []eventRatePoint{{Timestamp: 1060, Count: 1}}, l.since("us-east-1", time.Unix(1030, 0)))
+	assert.Equal(t, 1, len(l.since("us-west-1", time.Unix(0, 0))))
+
+	// The samples are dropped after the retention
+	l.update(data, now.Add(330*time.Second), 60, 300)
+	assert.Equal(t, []eventRatePoint{{Timestamp: 1060, Count: 1}, {Timestamp: 1330, Count: 1}}, l.since("us-east-1", time.Unix(0, 0)))
+	assert.Equal(t, 0, len(l.since("us-west-1", time.Unix(0, 0))))
+}
@@ -0,0 +1,34 @@
+package uchiwa
+
+import (
+	"net/http"
+
+	"github.com/sensu/uchiwa/uchiwa/config"
+)
+
+// features returns the state of every known feature flag, falling back on
+// the default state of the flags missing from the configuration
+func (u *Uchiwa) features() map[string]bool {
+	features := make(map[string]bool, len(config.DefaultFeatures))
+	for name, enabled := range config.DefaultFeatures {
+		features[name] = enabled
+		if configured, ok := u.Config.Uchiwa.Features[name]; ok {
+			features[name] = configured
+		}
+	}
+
+	return features
+}
+
+// featureHandler responds with a 404 when the provided feature is disabled,
+// as if its endpoints did not exist
+func (u *Uchiwa) featureHandler(name string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !u.features()[name] {
+			http.Error(w, "", http.StatusNotFound)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
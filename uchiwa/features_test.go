@@ -0,0 +1,42 @@
+package uchiwa
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sensu/uchiwa/uchiwa/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeatureHandler(t *testing.T) {
+	u := &Uchiwa{Config: &config.Config{}}
+	handler := u.featureHandler("pendingRequests", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	// Enabled by default
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/request/pending", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Disabled
+	u.Config.Uchiwa.Features = map[string]bool{"pendingRequests": false}
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/request/pending", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestConfigHandlerFeatures(t *testing.T) {
+	u := &Uchiwa{Config: &config.Config{}, PublicConfig: &config.Config{}}
+	u.Config.Uchiwa.Features = map[string]bool{"flappingEvents": false}
+
+	w := httptest.NewRecorder()
+	u.configHandler(w, httptest.NewRequest("GET", "/config/features", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var features map[string]bool
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &features))
+	assert.Equal(t, len(config.DefaultFeatures), len(features))
+	assert.False(t, features["flappingEvents"])
+	assert.True(t, features["pendingRequests"])
+}
@@ -0,0 +1,187 @@
+package uchiwa
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/sensu/uchiwa/uchiwa/helpers"
+)
+
+// elementFilter reports whether an element, e.g. a client or an event,
+// matches a criterion
+type elementFilter func(element map[string]interface{}) bool
+
+// nestedField returns the value of the field found at the provided path of
+// nested objects, e.g. check then status
+func nestedField(element map[string]interface{}, path []string) (interface{}, bool) {
+	var value interface{} = element
+	for _, key := range path {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		value, ok = m[key]
+		if !ok || value == nil {
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+// statusCode returns the provided status as an integer, whether it was
+// decoded as a number or provided as a numeric string
+func statusCode(value interface{}) (int, bool) {
+	switch s := value.(type) {
+	case int:
+		return s, true
+	case int64:
+		return int(s), true
+	case float64:
+		return int(s), true
+	case json.Number:
+		i, err := s.Int64()
+		return int(i), err == nil
+	case string:
+		i, err := strconv.Atoi(s)
+		return i, err == nil
+	}
+	return 0, false
+}
+
+// statusFilter matches the elements whose status, found at the provided path,
+// matches any of the provided values. A value is either a status code or the
+// name of a status, in which case every status above 2 is unknown
+func statusFilter(path []string, values []string) (elementFilter, error) {
+	codes := make(map[int]bool)
+	names := make(map[string]bool)
+	for _, value := range values {
+		if code, err := strconv.Atoi(value); err == nil {
+			codes[code] = true
+			continue
+		}
+		if !helpers.IsStringInArray(value, checkStatuses) {
+			return nil, fmt.Errorf("Invalid status parameter '%s'", value)
+		}
+		names[value] = true
+	}
+
+	return func(element map[string]interface{}) bool {
+		value, ok := nestedField(element, path)
+		if !ok {
+			return false
+		}
+		status, ok := statusCode(value)
+		if !ok {
+			return false
+		}
+		return codes[status] || names[checkStatusName(status)]
+	}, nil
+}
+
+// valueFilter matches the elements whose string field, found at the provided
+// path, is equal to any of the provided values
+func valueFilter(path []string, values []string) elementFilter {
+	return func(element map[string]interface{}) bool {
+		value, ok := nestedField(element, path)
+		if !ok {
+			return false
+		}
+		s, ok := value.(string)
+		return ok && helpers.IsStringInArray(s, values)
+	}
+}
+
+// subscriptionFilter matches the elements whose subscriptions, found at the
+// provided path, contain any of the provided subscriptions
+func subscriptionFilter(path []string, subscriptions []string) elementFilter {
+	return func(element map[string]interface{}) bool {
+		value, ok := nestedField(element, path)
+		if !ok {
+			return false
+		}
+		list, ok := value.([]interface{})
+		if !ok {
+			return false
+		}
+		for _, subscription := range helpers.InterfaceToString(list) {
+			if helpers.IsStringInArray(subscription, subscriptions) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// parseCommonFilters builds the filters requested by the dc, subscription and
+// status parameters, shared by the clients and the events. The values of a
+// parameter are combined with OR, and the parameters with AND
+func parseCommonFilters(query url.Values, subscriptionsPath, statusPath []string) ([]elementFilter, error) {
+	var filters []elementFilter
+
+	if values := nonEmptyValues(query["dc"]); len(values) > 0 {
+		filters = append(filters, valueFilter([]string{"dc"}, values))
+	}
+
+	if values := nonEmptyValues(query["subscription"]); len(values) > 0 {
+		filters = append(filters, subscriptionFilter(subscriptionsPath, values))
+	}
+
+	if values := nonEmptyValues(query["status"]); len(values) > 0 {
+		filter, err := statusFilter(statusPath, values)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+
+	return filters, nil
+}
+
+// nonEmptyValues returns the provided parameter values that are not empty
+func nonEmptyValues(values []string) []string {
+	var nonEmpty []string
+	for _, value := range values {
+		if value != "" {
+			nonEmpty = append(nonEmpty, value)
+		}
+	}
+	return nonEmpty
+}
+
+// parseEventFilters builds the event filters requested by the provided query
+// parameters, which are dc, subscription, matching the subscriptions of the
+// client, and status, matching the status of the check
+func parseEventFilters(query url.Values) ([]elementFilter, error) {
+	return parseCommonFilters(query, []string{"client", "subscriptions"}, []string{"check", "status"})
+}
+
+// filterElements returns the elements matching every provided filter. The
+// elements are returned untouched without any filter
+func filterElements(elements []interface{}, filters []elementFilter) []interface{} {
+	if len(filters) == 0 {
+		return elements
+	}
+
+	filtered := make([]interface{}, 0)
+
+next:
+	for _, e := range elements {
+		element, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		for _, filter := range filters {
+			if !filter(element) {
+				continue next
+			}
+		}
+
+		filtered = append(filtered, element)
+	}
+
+	return filtered
+}
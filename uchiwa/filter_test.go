@@ -0,0 +1,79 @@
+package uchiwa
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusCode(t *testing.T) {
+	for _, value := range []interface{}{2, int64(2), 2.0, json.Number("2"), "2"} {
+		status, ok := statusCode(value)
+		assert.True(t, ok)
+		assert.Equal(t, 2, status)
+	}
+
+	_, ok := statusCode("critical")
+	assert.False(t, ok)
+	_, ok = statusCode(nil)
+	assert.False(t, ok)
+}
+
+func TestStatusFilter(t *testing.T) {
+	filter, err := statusFilter([]string{"check", "status"}, []string{"warning", "critical"})
+	assert.Nil(t, err)
+
+	// Integer, float & string status codes
+	assert.True(t, filter(map[string]interface{}{"check": map[string]interface{}{"status": 1}}))
+	assert.True(t, filter(map[string]interface{}{"check": map[string]interface{}{"status": 2.0}}))
+	assert.True(t, filter(map[string]interface{}{"check": map[string]interface{}{"status": "2"}}))
+	assert.False(t, filter(map[string]interface{}{"check": map[string]interface{}{"status": 0}}))
+
+	// Missing fields
+	assert.False(t, filter(map[string]interface{}{}))
+	assert.False(t, filter(map[string]interface{}{"check": "foo"}))
+	assert.False(t, filter(map[string]interface{}{"check": map[string]interface{}{"status": nil}}))
+
+	// Every status above 2 is unknown, unless a code is provided
+	filter, err = statusFilter([]string{"status"}, []string{"unknown"})
+	assert.Nil(t, err)
+	assert.True(t, filter(map[string]interface{}{"status": 3}))
+	assert.True(t, filter(map[string]interface{}{"status": 127.0}))
+
+	filter, err = statusFilter([]string{"status"}, []string{"3"})
+	assert.Nil(t, err)
+	assert.True(t, filter(map[string]interface{}{"status": 3}))
+	assert.False(t, filter(map[string]interface{}{"status": 127.0}))
+
+	_, err = statusFilter([]string{"status"}, []string{"foo"})
+	assert.NotNil(t, err)
+}
+
+func TestParseEventFilters(t *testing.T) {
+	events := []interface{}{
+		map[string]interface{}{"dc": "us-east-1", "client": map[string]interface{}{"subscriptions": []interface{}{"linux"}}, "check": map[string]interface{}{"status": 2.0}},
+		map[string]interface{}{"dc": "us-east-1", "client": map[string]interface{}{"subscriptions": []interface{}{"windows"}}, "check": map[string]interface{}{"status": 1}},
+		map[string]interface{}{"dc": "us-west-1", "client": map[string]interface{}{"subscriptions": []interface{}{"linux"}}, "check": map[string]interface{}{"status": 3.0}},
+		map[string]interface{}{"dc": "us-west-1", "check": map[string]interface{}{"status": 2.0}},
+	}
+
+	// No filter
+	filters, err := parseEventFilters(url.Values{})
+	assert.Nil(t, err)
+	assert.Equal(t, events, filterElements(events, filters))
+
+	// The values of a parameter are combined with OR
+	filters, err = parseEventFilters(url.Values{"status": {"warning", "critical"}})
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{events[0], events[1], events[3]}, filterElements(events, filters))
+
+	// The parameters are combined with AND
+	filters, err = parseEventFilters(url.Values{"status": {"critical", "unknown"}, "dc": {"us-west-1"}, "subscription": {"linux"}})
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{events[2]}, filterElements(events, filters))
+
+	_, err = parseEventFilters(url.Values{"status": {"foo"}})
+	assert.NotNil(t, err)
+}
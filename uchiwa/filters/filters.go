@@ -2,12 +2,18 @@ package filters
 
 import (
 	"github.com/dgrijalva/jwt-go"
+	"github.com/sensu/uchiwa/uchiwa/authentication"
+	"github.com/sensu/uchiwa/uchiwa/helpers"
 	"github.com/sensu/uchiwa/uchiwa/structs"
 )
 
+// redactedValue replaces the value of the redacted check attributes
+const redactedValue = "*****"
+
 // Filters contains the different filtering methods based on the edition
 type Filters interface {
 	Aggregates(*[]interface{}, *jwt.Token) []interface{}
+	AggregateResults(*[]interface{}, *jwt.Token) []interface{}
 	Checks(*[]interface{}, *jwt.Token) []interface{}
 	Client(interface{}, *jwt.Token) bool
 	Clients(*[]interface{}, *jwt.Token) []interface{}
@@ -15,13 +21,76 @@ type Filters interface {
 	Events(*[]interface{}, *jwt.Token) []interface{}
 	// NEED WORK
 	GetRequest(string, *jwt.Token) bool
+	History(*[]interface{}, *jwt.Token) []interface{}
 	Silenced(*[]interface{}, *jwt.Token) []interface{}
 	Stashes(*[]interface{}, *jwt.Token) []interface{}
 	Subscriptions(*[]structs.Subscription, *jwt.Token) []structs.Subscription
 }
 
 // Uchiwa represents an instance of the Filters interface for the community filters
-type Uchiwa struct{}
+type Uchiwa struct {
+	// RedactedCheckFields contains the check attributes, e.g. command, masked
+	// in the checks and events for the members of the roles not listed in
+	// RedactExemptRoles. Nothing is masked when authentication is disabled
+	RedactedCheckFields []string
+	RedactExemptRoles   []string
+}
+
+// redacts verifies if the check attributes must be masked for the user
+func (u *Uchiwa) redacts(token *jwt.Token) bool {
+	if u == nil || len(u.RedactedCheckFields) == 0 || token == nil {
+		return false
+	}
+
+	role, err := authentication.GetRoleFromToken(token)
+	if err != nil {
+		return true
+	}
+
+	return !helpers.IsStringInArray(role.Name, u.RedactExemptRoles)
+}
+
+// redactCheck returns a copy of the provided check with the redacted
+// attributes masked
+func (u *Uchiwa) redactCheck(c interface{}) interface{} {
+	check, ok := c.(map[string]interface{})
+	if !ok {
+		return c
+	}
+
+	redacted := make(map[string]interface{}, len(check))
+	for key, value := range check {
+		redacted[key] = value
+	}
+
+	for _, field := range u.RedactedCheckFields {
+		if _, ok := redacted[field]; ok {
+			redacted[field] = redactedValue
+		}
+	}
+
+	return redacted
+}
+
+// redactNestedCheck returns a copy of the provided element, e.g. an event,
+// with the redacted attributes of the check found under the provided key
+// masked
+func (u *Uchiwa) redactNestedCheck(e interface{}, key string) interface{} {
+	element, ok := e.(map[string]interface{})
+	if !ok {
+		return e
+	}
+
+	redacted := make(map[string]interface{}, len(element))
+	for k, value := range element {
+		redacted[k] = value
+	}
+	if check, ok := element[key]; ok {
+		redacted[key] = u.redactCheck(check)
+	}
+
+	return redacted
+}
 
 // Aggregates filters based on role's datacenters
 func (u *Uchiwa) Aggregates(data *[]interface{}, token *jwt.Token) []interface{} {
@@ -30,10 +99,50 @@ func (u *Uchiwa) Aggregates(data *[]interface{}, token *jwt.Token) []interface{}
 	return aggregates
 }
 
+// AggregateResults masks the redacted check attributes, e.g. output, of the
+// summaries of the provided aggregate results
+func (u *Uchiwa) AggregateResults(data *[]interface{}, token *jwt.Token) []interface{} {
+	results := make([]interface{}, len(*data))
+	copy(results, *data)
+
+	if !u.redacts(token) {
+		return results
+	}
+
+	for i, r := range results {
+		result, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		redacted := make(map[string]interface{}, len(result))
+		for key, value := range result {
+			redacted[key] = value
+		}
+		if summaries, ok := result["summary"].([]interface{}); ok {
+			redactedSummaries := make([]interface{}, len(summaries))
+			for j, summary := range summaries {
+				redactedSummaries[j] = u.redactCheck(summary)
+			}
+			redacted["summary"] = redactedSummaries
+		}
+		results[i] = redacted
+	}
+
+	return results
+}
+
 // Checks filters based on role's datacenters and subscriptions
 func (u *Uchiwa) Checks(data *[]interface{}, token *jwt.Token) []interface{} {
 	checks := make([]interface{}, len(*data))
 	copy(checks, *data)
+
+	if u.redacts(token) {
+		for i, check := range checks {
+			checks[i] = u.redactCheck(check)
+		}
+	}
+
 	return checks
 }
 
@@ -58,6 +167,13 @@ func (u *Uchiwa) Datacenters(data []*structs.Datacenter, token *jwt.Token) []*st
 func (u *Uchiwa) Events(data *[]interface{}, token *jwt.Token) []interface{} {
 	events := make([]interface{}, len(*data))
 	copy(events, *data)
+
+	if u.redacts(token) {
+		for i, event := range events {
+			events[i] = u.redactNestedCheck(event, "check")
+		}
+	}
+
 	return events
 }
 
@@ -82,6 +198,21 @@ func (u *Uchiwa) Subscriptions(data *[]structs.Subscription, token *jwt.Token) [
 	return subscriptions
 }
 
+// History masks the redacted attributes of the last results found in the
+// provided client history
+func (u *Uchiwa) History(data *[]interface{}, token *jwt.Token) []interface{} {
+	history := make([]interface{}, len(*data))
+	copy(history, *data)
+
+	if u.redacts(token) {
+		for i, entry := range history {
+			history[i] = u.redactNestedCheck(entry, "last_result")
+		}
+	}
+
+	return history
+}
+
 // GetRequest is a function that filters GET requests.
 func (u *Uchiwa) GetRequest(dc string, token *jwt.Token) bool {
 	return false
@@ -0,0 +1,80 @@
+package filters
+
+import (
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/sensu/uchiwa/uchiwa/authentication"
+	"github.com/stretchr/testify/assert"
+)
+
+func generateToken(role authentication.Role) *jwt.Token {
+	token := jwt.New(jwt.GetSigningMethod("RS256"))
+	token.Claims["role"] = role
+
+	return token
+}
+
+func TestRedaction(t *testing.T) {
+	u := &Uchiwa{RedactedCheckFields: []string{"command", "output"}, RedactExemptRoles: []string{"admin"}}
+	checks := []interface{}{
+		map[string]interface{}{"name": "cpu", "command": "check-cpu.rb --password foo"},
+	}
+	events := []interface{}{
+		map[string]interface{}{"id": "foo", "check": map[string]interface{}{"name": "cpu", "command": "check-cpu.rb", "output": "CRITICAL"}},
+	}
+
+	// Non-exempted role
+	token := generateToken(authentication.Role{Name: "guest"})
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "cpu", "command": redactedValue},
+	}, u.Checks(&checks, token))
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"id": "foo", "check": map[string]interface{}{"name": "cpu", "command": redactedValue, "output": redactedValue}},
+	}, u.Events(&events, token))
+
+	// The data is not modified
+	assert.Equal(t, "check-cpu.rb --password foo", checks[0].(map[string]interface{})["command"])
+	assert.Equal(t, "CRITICAL", events[0].(map[string]interface{})["check"].(map[string]interface{})["output"])
+
+	// Exempted role & disabled authentication
+	assert.Equal(t, checks, u.Checks(&checks, generateToken(authentication.Role{Name: "admin"})))
+	assert.Equal(t, events, u.Events(&events, nil))
+
+	// Redaction disabled
+	assert.Equal(t, checks, (&Uchiwa{}).Checks(&checks, token))
+}
+
+func TestHistoryRedaction(t *testing.T) {
+	u := &Uchiwa{RedactedCheckFields: []string{"command", "output"}, RedactExemptRoles: []string{"admin"}}
+	history := []interface{}{
+		map[string]interface{}{"check": "cpu", "last_status": 2, "last_result": map[string]interface{}{"command": "check-cpu.rb", "output": "CRITICAL", "status": 2}},
+	}
+
+	token := generateToken(authentication.Role{Name: "guest"})
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"check": "cpu", "last_status": 2, "last_result": map[string]interface{}{"command": redactedValue, "output": redactedValue, "status": 2}},
+	}, u.History(&history, token))
+	assert.Equal(t, "CRITICAL", history[0].(map[string]interface{})["last_result"].(map[string]interface{})["output"])
+
+	assert.Equal(t, history, u.History(&history, generateToken(authentication.Role{Name: "admin"})))
+}
+
+func TestAggregateResultsRedaction(t *testing.T) {
+	u := &Uchiwa{RedactedCheckFields: []string{"output"}}
+	results := []interface{}{
+		map[string]interface{}{"check": "cpu", "summary": []interface{}{
+			map[string]interface{}{"output": "CRITICAL", "total": 1.0, "clients": []interface{}{"foo"}},
+		}},
+	}
+
+	token := generateToken(authentication.Role{Name: "guest"})
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"check": "cpu", "summary": []interface{}{
+			map[string]interface{}{"output": redactedValue, "total": 1.0, "clients": []interface{}{"foo"}},
+		}},
+	}, u.AggregateResults(&results, token))
+	assert.Equal(t, "CRITICAL", results[0].(map[string]interface{})["summary"].([]interface{})[0].(map[string]interface{})["output"])
+
+	assert.Equal(t, results, u.AggregateResults(&results, nil))
+}
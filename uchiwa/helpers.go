@@ -1,11 +1,25 @@
 package uchiwa
 
 import (
+	"compress/gzip"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
 
+	"github.com/dgrijalva/jwt-go"
+	"github.com/sensu/uchiwa/uchiwa/audit"
+	"github.com/sensu/uchiwa/uchiwa/helpers"
 	"github.com/sensu/uchiwa/uchiwa/logger"
 	"github.com/sensu/uchiwa/uchiwa/sensu"
+	"github.com/sensu/uchiwa/uchiwa/structs"
 )
 
 func getAPI(datacenters *[]sensu.Sensu, name string) (*sensu.Sensu, error) {
@@ -17,15 +31,272 @@ func getAPI(datacenters *[]sensu.Sensu, name string) (*sensu.Sensu, error) {
 		return nil, errors.New("The datacenter name can't be empty")
 	}
 
-	for _, datacenter := range *datacenters {
-		if datacenter.Name == name {
-			return &datacenter, nil
+	if dc, ok := normalizeDatacenter(datacenters, name, false); ok {
+		for _, datacenter := range *datacenters {
+			if datacenter.Name == dc {
+				return &datacenter, nil
+			}
 		}
 	}
 
 	return nil, fmt.Errorf("Could not find the datacenter '%s'", name)
 }
 
+// normalizeDatacenter returns the name of the configured datacenter designated
+// by the provided name or alias, ignoring the surrounding spaces and, if
+// foldCase is set, the case. An exact match is preferred over a case-folded
+// one. It returns false if no configured datacenter is designated
+func normalizeDatacenter(datacenters *[]sensu.Sensu, name string, foldCase bool) (string, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" || datacenters == nil {
+		return name, true
+	}
+
+	if dc, ok := findDatacenter(datacenters, name, func(a, b string) bool { return a == b }); ok {
+		return dc, true
+	}
+	if foldCase {
+		return findDatacenter(datacenters, name, strings.EqualFold)
+	}
+
+	return "", false
+}
+
+// findDatacenter returns the name of the datacenter whose name, or otherwise
+// alias, is equal to the provided name according to the provided comparison
+func findDatacenter(datacenters *[]sensu.Sensu, name string, equal func(a, b string) bool) (string, bool) {
+	for _, datacenter := range *datacenters {
+		if equal(datacenter.Name, name) {
+			return datacenter.Name, true
+		}
+	}
+
+	for _, datacenter := range *datacenters {
+		if datacenter.Alias != "" && equal(datacenter.Alias, name) {
+			return datacenter.Name, true
+		}
+	}
+
+	return "", false
+}
+
+// datacenterName normalizes the provided datacenter name against the
+// configured datacenters. The case is only significant with the
+// StrictDatacenters option
+func (u *Uchiwa) datacenterName(name string) (string, bool) {
+	return normalizeDatacenter(u.Datacenters, name, !u.Config.Uchiwa.StrictDatacenters)
+}
+
+// datacenterNotFound writes a 404 mentioning the provided datacenter name
+func datacenterNotFound(w http.ResponseWriter, name string) {
+	http.Error(w, fmt.Sprintf("Could not find the datacenter '%s'", name), http.StatusNotFound)
+}
+
+// datacenterParam returns the name of the datacenter designated by the dc
+// parameter of the request, normalized against the configured datacenters,
+// or an empty string without any dc parameter. A 404 mentioning the provided
+// value is written, and false returned, if it designates no datacenter
+func (u *Uchiwa) datacenterParam(w http.ResponseWriter, r *http.Request) (string, bool) {
+	param := r.URL.Query().Get("dc")
+
+	dc, ok := u.datacenterName(param)
+	if !ok {
+		datacenterNotFound(w, param)
+		return "", false
+	}
+
+	return dc, true
+}
+
+// datacenterParams normalizes, in place, every value of the dc parameter of
+// the provided query, e.g. used to filter a list. A 404 mentioning the first
+// value that designates no datacenter is written, and false returned
+func (u *Uchiwa) datacenterParams(w http.ResponseWriter, query url.Values) bool {
+	for i, value := range query["dc"] {
+		dc, ok := u.datacenterName(value)
+		if !ok {
+			datacenterNotFound(w, value)
+			return false
+		}
+		query["dc"][i] = dc
+	}
+
+	return true
+}
+
+// decodeJSONBody decodes the JSON body of a mutating request into v, which may
+// also be gzip-encoded. Requests with another content type or encoding are
+// rejected with a 415 status, and bodies with unknown fields with a 400 status
+// when StrictJSON is enabled, in which case the body is decoded a second time
+// to look for them. It returns false if the body could not be decoded, in
+// which case an error was already written to the client
+func (u *Uchiwa) decodeJSONBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		writeJSONError(w, http.StatusUnsupportedMediaType, "The request body must be of type application/json")
+		return false
+	}
+
+	var body io.Reader = r.Body
+	switch strings.ToLower(r.Header.Get("Content-Encoding")) {
+	case "", "identity":
+	case "gzip":
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, "Could not decode body", http.StatusInternalServerError)
+			return false
+		}
+		defer gz.Close()
+		body = gz
+	default:
+		writeJSONError(w, http.StatusUnsupportedMediaType, "The request body must be either uncompressed or gzip-encoded")
+		return false
+	}
+
+	raw, err := ioutil.ReadAll(body)
+	if err != nil {
+		http.Error(w, "Could not decode body", http.StatusInternalServerError)
+		return false
+	}
+
+	if err := json.Unmarshal(raw, v); err != nil {
+		http.Error(w, "Could not decode body", http.StatusInternalServerError)
+		return false
+	}
+
+	if u.Config.Uchiwa.StrictJSON {
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			http.Error(w, "Could not decode body", http.StatusInternalServerError)
+			return false
+		}
+
+		if field := unknownField(value, reflect.TypeOf(v), ""); field != "" {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("Unknown field %q", field))
+			return false
+		}
+	}
+
+	return true
+}
+
+// unknownField returns the path, under the provided one, of the first key of
+// the provided decoded JSON value that does not match any field of the
+// provided type, or an empty string. The keys are matched without case, like
+// encoding/json does. This replaces json.Decoder.DisallowUnknownFields, which
+// is not available before Go 1.10 while Uchiwa still supports Go 1.9
+func unknownField(value interface{}, t reflect.Type, path string) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+
+		fields := jsonFields(t)
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			field, ok := fields[strings.ToLower(key)]
+			if !ok {
+				return fieldPath(path, key)
+			}
+			if name := unknownField(object[key], field.Type, fieldPath(path, key)); name != "" {
+				return name
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		list, ok := value.([]interface{})
+		if !ok {
+			return ""
+		}
+		for i, element := range list {
+			if name := unknownField(element, t.Elem(), fmt.Sprintf("%s[%d]", path, i)); name != "" {
+				return name
+			}
+		}
+	case reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		for key, element := range object {
+			if name := unknownField(element, t.Elem(), fieldPath(path, key)); name != "" {
+				return name
+			}
+		}
+	}
+
+	return ""
+}
+
+// fieldPath returns the path of the provided key under the provided path
+func fieldPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// jsonFields returns the fields of the provided struct type decoded by
+// encoding/json, including the ones of the embedded structs, indexed by their
+// lowercased JSON name
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for key, f := range jsonFields(embedded) {
+					if _, ok := fields[key]; !ok {
+						fields[key] = f
+					}
+				}
+				continue
+			}
+		}
+
+		// Unexported fields are ignored
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		fields[strings.ToLower(name)] = field
+	}
+
+	return fields
+}
+
+// writeJSONError writes the provided error message, as a JSON object, with
+// the provided status code
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
 func findModel(id string, dc string, checks []interface{}) map[string]interface{} {
 	for _, k := range checks {
 		m, ok := k.(map[string]interface{})
@@ -40,6 +311,36 @@ func findModel(id string, dc string, checks []interface{}) map[string]interface{
 	return nil
 }
 
+// getUsername returns the username contained in the JWT claims, or Unknown
+// if the username can't be determined
+func getUsername(token *jwt.Token) string {
+	if token == nil {
+		return "Unknown"
+	}
+
+	username, ok := token.Claims["username"].(string)
+	if !ok {
+		return "Unknown"
+	}
+
+	return username
+}
+
+// auditAction adds the provided action, performed by the user of the JWT on
+// the resource identified by the provided URL, to the audit log. The
+// resources targeted by the action are recorded so its trail can be retrieved
+func auditAction(r *http.Request, token *jwt.Token, action, url string, resources ...structs.AuditResource) {
+	log := structs.AuditLog{
+		Action:     action,
+		Level:      "default",
+		RemoteAddr: helpers.GetIP(r),
+		Resources:  resources,
+		URL:        url,
+		User:       getUsername(token),
+	}
+	audit.Log(log)
+}
+
 // MergeStringSlices merges two slices of strings and remove duplicated values
 func MergeStringSlices(a1, a2 []string) []string {
 	if len(a1) == 0 {
@@ -1,8 +1,19 @@
 package uchiwa
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/dgrijalva/jwt-go"
+	"github.com/sensu/uchiwa/uchiwa/config"
+	"github.com/sensu/uchiwa/uchiwa/sensu"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -44,3 +55,197 @@ func TestMergeStringSlice(t *testing.T) {
 	assert.Equal(t, []string{"1", "2", "3"}, slice, "if one slice is empty, it should return the other slice")
 
 }
+
+func TestGetAPIAlias(t *testing.T) {
+	datacenters := &[]sensu.Sensu{
+		{Name: "dc-use1-prod", Alias: "us-east-1"},
+		{Name: "dc-usw1-prod"},
+	}
+
+	api, err := getAPI(datacenters, "us-east-1")
+	assert.Nil(t, err)
+	assert.Equal(t, "dc-use1-prod", api.Name)
+
+	_, err = getAPI(datacenters, "foo")
+	assert.NotNil(t, err)
+}
+
+func TestNormalizeDatacenter(t *testing.T) {
+	datacenters := &[]sensu.Sensu{
+		{Name: "dc1", Alias: "us-east-1"},
+		{Name: "DC1"},
+		{Name: "dc2", Alias: "US-West-1"},
+	}
+
+	tests := []struct {
+		name     string
+		foldCase bool
+		expected string
+		found    bool
+	}{
+		{"", true, "", true},
+		{"dc1", true, "dc1", true},
+		{" dc2 ", true, "dc2", true},
+		{"us-east-1", true, "dc1", true},
+		// The exact match is preferred
+		{"DC1", true, "DC1", true},
+		// Case mismatches
+		{"Dc2", true, "dc2", true},
+		{"US-EAST-1", true, "dc1", true},
+		{"us-west-1", true, "dc2", true},
+		{"Dc2", false, "", false},
+		{"us-west-1", false, "", false},
+		{"dc3", true, "", false},
+	}
+
+	for _, test := range tests {
+		dc, found := normalizeDatacenter(datacenters, test.name, test.foldCase)
+		assert.Equal(t, test.expected, dc, test.name)
+		assert.Equal(t, test.found, found, test.name)
+	}
+}
+
+func TestDatacenterParam(t *testing.T) {
+	u := &Uchiwa{
+		Config:      &config.Config{},
+		Datacenters: &[]sensu.Sensu{{Name: "dc1", Alias: "us-east-1"}},
+	}
+
+	w := httptest.NewRecorder()
+	dc, ok := u.datacenterParam(w, httptest.NewRequest("GET", "/clients/foo?dc=US-East-1", nil))
+	assert.True(t, ok)
+	assert.Equal(t, "dc1", dc)
+
+	// No dc parameter
+	dc, ok = u.datacenterParam(w, httptest.NewRequest("GET", "/clients/foo", nil))
+	assert.True(t, ok)
+	assert.Equal(t, "", dc)
+
+	// Unknown datacenter
+	dc, ok = u.datacenterParam(w, httptest.NewRequest("GET", "/clients/foo?dc=dc2", nil))
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "Could not find the datacenter 'dc2'\n", w.Body.String())
+
+	// Case-sensitive names
+	u.Config.Uchiwa.StrictDatacenters = true
+	w = httptest.NewRecorder()
+	_, ok = u.datacenterParam(w, httptest.NewRequest("GET", "/clients/foo?dc=DC1", nil))
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDatacenterParams(t *testing.T) {
+	u := &Uchiwa{
+		Config:      &config.Config{},
+		Datacenters: &[]sensu.Sensu{{Name: "dc1", Alias: "us-east-1"}, {Name: "dc2"}},
+	}
+
+	w := httptest.NewRecorder()
+	query := url.Values{"dc": {"US-East-1", " DC2 "}}
+	assert.True(t, u.datacenterParams(w, query))
+	assert.Equal(t, []string{"dc1", "dc2"}, query["dc"])
+
+	query = url.Values{"dc": {"dc1", "dc3"}}
+	assert.False(t, u.datacenterParams(w, query))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "Could not find the datacenter 'dc3'\n", w.Body.String())
+}
+
+func TestGetUsername(t *testing.T) {
+	assert.Equal(t, "Unknown", getUsername(nil))
+
+	token := jwt.New(jwt.GetSigningMethod("RS256"))
+	assert.Equal(t, "Unknown", getUsername(token))
+
+	token.Claims["username"] = 42
+	assert.Equal(t, "Unknown", getUsername(token))
+
+	token.Claims["username"] = "foo"
+	assert.Equal(t, "foo", getUsername(token))
+}
+
+func TestDecodeJSONBody(t *testing.T) {
+	u := &Uchiwa{Config: &config.Config{}}
+	var data map[string]interface{}
+
+	// JSON body
+	r, _ := http.NewRequest("POST", "/stashes", strings.NewReader(`{"path":"foo"}`))
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	w := httptest.NewRecorder()
+	assert.True(t, u.decodeJSONBody(w, r, &data))
+	assert.Equal(t, "foo", data["path"])
+
+	// gzip-encoded JSON body
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte(`{"path":"bar"}`))
+	gz.Close()
+	r, _ = http.NewRequest("POST", "/stashes", &buf)
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Content-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	assert.True(t, u.decodeJSONBody(w, r, &data))
+	assert.Equal(t, "bar", data["path"])
+
+	// Form-encoded body
+	r, _ = http.NewRequest("POST", "/stashes", strings.NewReader("path=foo"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	assert.False(t, u.decodeJSONBody(w, r, &data))
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	// Missing content type
+	r, _ = http.NewRequest("POST", "/stashes", strings.NewReader(`{"path":"foo"}`))
+	w = httptest.NewRecorder()
+	assert.False(t, u.decodeJSONBody(w, r, &data))
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+	// Unsupported content encoding
+	r, _ = http.NewRequest("POST", "/stashes", strings.NewReader(`{"path":"foo"}`))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Content-Encoding", "br")
+	w = httptest.NewRecorder()
+	assert.False(t, u.decodeJSONBody(w, r, &data))
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+	// Unknown fields are ignored by default
+	var entry silence
+	r, _ = http.NewRequest("POST", "/silenced", strings.NewReader(`{"dc":"us-east-1","expires":3600}`))
+	r.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	assert.True(t, u.decodeJSONBody(w, r, &entry))
+	assert.Equal(t, "us-east-1", entry.Dc)
+
+	// Unknown fields are rejected in strict mode
+	u.Config.Uchiwa.StrictJSON = true
+	r, _ = http.NewRequest("POST", "/silenced", strings.NewReader(`{"dc":"us-east-1","expires":3600}`))
+	r.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	assert.False(t, u.decodeJSONBody(w, r, &entry))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `Unknown field \"expires\"`)
+
+	r, _ = http.NewRequest("POST", "/silenced", strings.NewReader(`{"dc":"us-east-1","expire":3600}`))
+	r.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	assert.True(t, u.decodeJSONBody(w, r, &entry))
+	assert.Equal(t, int32(3600), entry.Expire)
+}
+
+func TestUnknownField(t *testing.T) {
+	var value interface{}
+	json.Unmarshal([]byte(`[{"dc":"us-east-1","Check":"disk"},{"dc":"us-east-1","expires":60}]`), &value)
+	assert.Equal(t, "[1].expires", unknownField(value, reflect.TypeOf(&[]silence{}), ""))
+
+	json.Unmarshal([]byte(`{"dc":"us-east-1","warnings":["foo"],"error":""}`), &value)
+	assert.Equal(t, "", unknownField(value, reflect.TypeOf(silenceResult{}), ""))
+
+	// The nested fields are reported with their full path
+	json.Unmarshal([]byte(`{"foo":{"bar":1}}`), &value)
+	assert.Equal(t, "foo.bar", unknownField(value, reflect.TypeOf(struct{ Foo struct{ Baz int } }{}), ""))
+
+	// Any field is accepted by a map or an interface
+	assert.Equal(t, "", unknownField(value, reflect.TypeOf(map[string]interface{}{}), ""))
+}
@@ -0,0 +1,94 @@
+package uchiwa
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+)
+
+// Conventions to which the keys of the resource objects can be normalized
+const (
+	keysCamel = "camel"
+	keysSnake = "snake"
+)
+
+// normalizeKeys returns a copy of the provided value where the top-level keys
+// of every resource object, including the objects of nested lists, follow the
+// provided convention. The values of the objects are left untouched, and the
+// value is returned as is if the convention is unknown
+func normalizeKeys(v interface{}, convention string) interface{} {
+	var convert func(string) string
+	switch convention {
+	case keysCamel:
+		convert = camelCase
+	case keysSnake:
+		convert = snakeCase
+	default:
+		return v
+	}
+
+	return rewriteKeys(v, convert)
+}
+
+func rewriteKeys(v interface{}, convert func(string) string) interface{} {
+	switch value := v.(type) {
+	case []interface{}:
+		list := make([]interface{}, len(value))
+		for i, element := range value {
+			list[i] = rewriteKeys(element, convert)
+		}
+		return list
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for key, element := range value {
+			m[convert(key)] = element
+		}
+		return m
+	default:
+		return v
+	}
+}
+
+// snakeCase converts a camelCase key to snake_case, e.g. lastStateChange to
+// last_state_change
+func snakeCase(key string) string {
+	runes := []rune(key)
+	var b bytes.Buffer
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// Only split before the first letter of an acronym and before the
+			// first lowercase letter following it, e.g. checkID to check_id
+			if i > 0 && runes[i-1] != '_' && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteRune('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// camelCase converts a snake_case key to camelCase, e.g. last_state_change to
+// lastStateChange. Leading underscores, e.g. in _id, are preserved
+func camelCase(key string) string {
+	trimmed := strings.TrimLeft(key, "_")
+	prefix := key[:len(key)-len(trimmed)]
+
+	parts := strings.Split(trimmed, "_")
+	var b bytes.Buffer
+	b.WriteString(prefix)
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		if i > 0 {
+			runes := []rune(part)
+			runes[0] = unicode.ToUpper(runes[0])
+			part = string(runes)
+		}
+		b.WriteString(part)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,47 @@
+package uchiwa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnakeCase(t *testing.T) {
+	assert.Equal(t, "last_state_change", snakeCase("lastStateChange"))
+	assert.Equal(t, "last_state_change", snakeCase("last_state_change"))
+	assert.Equal(t, "check_id", snakeCase("checkID"))
+	assert.Equal(t, "http_status", snakeCase("HTTPStatus"))
+	assert.Equal(t, "_id", snakeCase("_id"))
+}
+
+func TestCamelCase(t *testing.T) {
+	assert.Equal(t, "lastStateChange", camelCase("last_state_change"))
+	assert.Equal(t, "lastStateChange", camelCase("lastStateChange"))
+	assert.Equal(t, "_id", camelCase("_id"))
+	assert.Equal(t, "name", camelCase("name"))
+}
+
+func TestNormalizeKeys(t *testing.T) {
+	list := []interface{}{
+		map[string]interface{}{
+			"lastStateChange": 1,
+			"check":           map[string]interface{}{"totalStateChange": 2},
+		},
+		"foo",
+	}
+
+	expected := []interface{}{
+		map[string]interface{}{
+			"last_state_change": 1,
+			"check":             map[string]interface{}{"totalStateChange": 2},
+		},
+		"foo",
+	}
+	assert.Equal(t, expected, normalizeKeys(list, keysSnake))
+
+	// The provided value is not modified
+	assert.Equal(t, 1, list[0].(map[string]interface{})["lastStateChange"])
+
+	// Unknown convention
+	assert.Equal(t, list, normalizeKeys(list, ""))
+}
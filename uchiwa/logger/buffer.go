@@ -0,0 +1,82 @@
+package logger
+
+import "sync"
+
+// buffer retains the most recent log entries, when enabled
+var buffer *ringBuffer
+
+// ringBuffer is a bounded buffer of log entries, where the oldest entry is
+// overwritten once the buffer is full
+type ringBuffer struct {
+	entries []Logger
+	mutex   sync.Mutex
+	next    int
+	size    int
+}
+
+// add stores a copy of the provided entry into the buffer
+func (b *ringBuffer) add(entry Logger) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if len(b.entries) < b.size {
+		b.entries = append(b.entries, entry)
+		return
+	}
+
+	b.entries[b.next] = entry
+	b.next = (b.next + 1) % b.size
+}
+
+// list returns the entries of the buffer, from the oldest to the most recent
+func (b *ringBuffer) list() []Logger {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	entries := make([]Logger, 0, len(b.entries))
+	entries = append(entries, b.entries[b.next:]...)
+	entries = append(entries, b.entries[:b.next]...)
+
+	return entries
+}
+
+// EnableBuffer retains the provided number of most recent log entries in
+// memory so they can later be retrieved with the Entries function
+func EnableBuffer(size int) {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+
+	if size < 1 {
+		buffer = nil
+		return
+	}
+
+	buffer = &ringBuffer{size: size}
+}
+
+// Entries returns, from the oldest to the most recent, up to limit of the
+// retained log entries whose level is at least as severe as the provided
+// level. An empty level returns the entries of every level
+func Entries(limit int, level string) []Logger {
+	logMutex.Lock()
+	b := buffer
+	logMutex.Unlock()
+
+	entries := []Logger{}
+	if b == nil {
+		return entries
+	}
+
+	for _, entry := range b.list() {
+		if level != "" && entry.Level != nil && getLevelInt(*entry.Level) > getLevelInt(level) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	if limit > 0 && len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+
+	return entries
+}
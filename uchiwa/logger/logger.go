@@ -95,6 +95,10 @@ func (l *Logger) print(level string, format string, args ...interface{}) {
 		fmt.Println(html.EscapeString(err.Error()))
 		return
 	}
+
+	if buffer != nil {
+		buffer.add(*l)
+	}
 	fmt.Println(string(data))
 }
 
@@ -130,3 +130,39 @@ func TestIsDisabledFor(t *testing.T) {
 	enabled = isDisabledFor("fatal")
 	assert.Equal(t, false, enabled)
 }
+
+func TestEntries(t *testing.T) {
+	originalStdout := os.Stdout
+	originalLevel := configuredLevel
+	os.Stdout, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	configuredLevel = INFO
+	defer func() {
+		os.Stdout = originalStdout
+		configuredLevel = originalLevel
+		EnableBuffer(0)
+	}()
+
+	// The buffer is disabled by default
+	Warning("foo")
+	assert.Equal(t, 0, len(Entries(0, "")))
+
+	EnableBuffer(3)
+	Warning("foo")
+	Info("bar")
+	Warning("baz")
+	Info("qux")
+
+	// Only the 3 most recent entries are retained
+	entries := Entries(0, "")
+	assert.Equal(t, 3, len(entries))
+	assert.Equal(t, "bar", *entries[0].Message)
+	assert.Equal(t, "qux", *entries[2].Message)
+
+	entries = Entries(1, "")
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "qux", *entries[0].Message)
+
+	entries = Entries(0, "warn")
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "baz", *entries[0].Message)
+}
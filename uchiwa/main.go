@@ -1,9 +1,12 @@
 package uchiwa
 
 import (
+	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/sensu/uchiwa/uchiwa/authentication"
 	"github.com/sensu/uchiwa/uchiwa/config"
 	"github.com/sensu/uchiwa/uchiwa/daemon"
 	"github.com/sensu/uchiwa/uchiwa/logger"
@@ -13,12 +16,43 @@ import (
 
 // Uchiwa structure is used to manage Uchiwa
 type Uchiwa struct {
+	Auth         authentication.Config
 	Config       *config.Config
 	Daemon       *daemon.Daemon
 	Data         *structs.Data
 	Datacenters  *[]sensu.Sensu
 	Mu           *sync.Mutex
 	PublicConfig *config.Config
+	PublicPath   string
+
+	checkRequests *checkRequestLog
+	checks        *checkLog
+	clientHistory *clientHistoryLog
+	// dataVersion is the refresh generation of the data, incremented on each
+	// successful refresh. It must be accessed atomically
+	dataVersion uint64
+	eventRates  *eventRateLog
+	events      *eventLog
+	// inFlight is the number of requests being processed. It must be accessed
+	// atomically
+	inFlight     int64
+	lastGood     *structs.Data
+	lastGoodTime time.Time
+	// lockWaiters is the number of goroutines waiting to acquire Mu. It must
+	// be accessed atomically
+	lockWaiters int64
+	rateLimiter *rateLimiter
+	readiness   *readiness
+	resolutions *resolutionLog
+	// server is the web server started by WebServer, and shutdown whether it
+	// was stopped. They must be accessed with serverMu held
+	server      *http.Server
+	serverMu    sync.Mutex
+	shutdown    bool
+	stale       bool
+	streams     *streamLimiter
+	subscribers *dataSubscribers
+	transitions *transitionLog
 }
 
 // Init method initializes the Sensu structure with the provided configuration and start the Uchiwa daemon
@@ -31,22 +65,38 @@ func Init(c *config.Config) *Uchiwa {
 		Data:        &structs.Data{},
 		Datacenters: datacenters,
 		Enterprise:  c.Uchiwa.Enterprise,
+
+		StartupRetries:    c.Uchiwa.Startup.Retries,
+		StartupRetryDelay: time.Duration(c.Uchiwa.Startup.RetryDelay) * time.Second,
 	}
 
 	u := &Uchiwa{
-		Config:       c,
-		Daemon:       d,
-		Data:         &structs.Data{},
-		Datacenters:  datacenters,
-		Mu:           &sync.Mutex{},
-		PublicConfig: c.GetPublic(),
+		Config:        c,
+		Daemon:        d,
+		Data:          &structs.Data{},
+		Datacenters:   datacenters,
+		Mu:            &sync.Mutex{},
+		checkRequests: &checkRequestLog{},
+		checks:        &checkLog{},
+		clientHistory: &clientHistoryLog{},
+		eventRates:    &eventRateLog{},
+		events:        newEventLog(time.Now().UnixNano()),
+		PublicConfig:  c.GetPublic(),
+		rateLimiter:   &rateLimiter{},
+		readiness:     newReadiness(),
+		resolutions:   &resolutionLog{},
+		streams:       &streamLimiter{},
+		subscribers:   newDataSubscribers(),
+		transitions:   &transitionLog{},
 	}
 
+	d.CheckSamples = u.checkSamples()
+
 	// start Uchiwa daemon and listen for results over data channel
 	interval := c.Uchiwa.Refresh
 	data := make(chan *structs.Data, 1)
 	go d.Start(interval, data)
-	go u.listener(interval, data)
+	go u.listener(d.Tick(interval), data)
 
 	return u
 }
@@ -63,6 +113,7 @@ OUTER:
 		dc := sensu.API{
 			CloseRequest:      api.Advanced.CloseRequest,
 			DisableKeepAlives: api.Advanced.DisableKeepAlives,
+			Headers:           api.Headers,
 			Insecure:          api.Insecure,
 			Pass:              api.Pass,
 			Path:              api.Path,
@@ -78,6 +129,15 @@ OUTER:
 			if datacenter.Name == api.Name {
 				// Add this API to the corresponding datacenter
 				datacenter.APIs = append(datacenter.APIs, dc)
+				if datacenter.Alias == "" {
+					datacenter.Alias = api.Alias
+				}
+				if datacenter.Schema == "" {
+					datacenter.Schema = api.Schema
+				}
+				if datacenter.Interval == 0 {
+					datacenter.Interval = api.Interval
+				}
 				datacenters[i] = datacenter
 
 				continue OUTER
@@ -85,7 +145,7 @@ OUTER:
 		}
 		// At this point we didn't find any datacenter with the same name
 		// so we will create a new one and add it to the datacenters slice
-		datacenter := sensu.Sensu{Name: api.Name}
+		datacenter := sensu.Sensu{Name: api.Name, Alias: api.Alias, Schema: api.Schema, Interval: api.Interval}
 		datacenter.APIs = append(datacenter.APIs, dc)
 		datacenters = append(datacenters, datacenter)
 	}
@@ -93,6 +153,40 @@ OUTER:
 	return &datacenters
 }
 
+// updateData replaces the data with the latest results. If every datacenter
+// was unreachable and stale data is enabled, the last known good data is kept
+// instead, as long as it is not older than the staleness threshold. It must be
+// called with the mutex held
+func (u *Uchiwa) updateData(result *structs.Data, now time.Time) {
+	u.stale = false
+
+	if len(result.Dc) > 0 {
+		u.Data = result
+		u.lastGood = result
+		u.lastGoodTime = now
+		atomic.AddUint64(&u.dataVersion, 1)
+		return
+	}
+
+	options := u.Config.Uchiwa.StaleData
+	threshold := time.Duration(options.Threshold) * time.Second
+	if !options.Enabled || u.lastGood == nil || (threshold > 0 && now.Sub(u.lastGoodTime) > threshold) {
+		u.Data = result
+		return
+	}
+
+	logger.Warning("Every datacenter is unreachable, serving the last known good data")
+
+	// Keep the current health & polling information
+	stale := *u.lastGood
+	stale.Health = result.Health
+	stale.Health.Stale = true
+	stale.LastPoll = result.LastPoll
+
+	u.Data = &stale
+	u.stale = true
+}
+
 // listener listens on the data channel for messages from the daemon
 // and updates the Data struct with latest results from the Sensu datacenters
 func (u *Uchiwa) listener(interval int, data chan *structs.Data) {
@@ -101,10 +195,21 @@ func (u *Uchiwa) listener(interval int, data chan *structs.Data) {
 		case result := <-data:
 			logger.Trace("Received results on the 'data' channel")
 
-			u.Mu.Lock()
-			u.Data = result
+			u.lockData()
+			u.updateData(result, time.Now())
+			u.checks.update(u.Data, time.Now())
+			u.checkRequests.update(u.Data.CheckSamples, time.Now(), u.Config.Uchiwa.CheckRequests.TTL)
+			u.clientHistory.update(u.Data, time.Now())
+			u.events.update(u.Data.Events)
+			u.eventRates.update(u.Data, time.Now(), u.Config.Uchiwa.EventRate.Interval, u.Config.Uchiwa.EventRate.Retention)
+			u.resolutions.update(u.Data, time.Now())
+			u.transitions.update(u.Data, time.Now(), u.Config.Uchiwa.Flapping.Window)
+			u.readiness.update(u.Data)
 			u.Mu.Unlock()
 
+			// Push the refreshed data to the streams
+			u.subscribers.notify()
+
 			// sleep during the interval
 			timer := time.NewTimer(time.Second * time.Duration(interval))
 			<-timer.C
@@ -2,8 +2,10 @@ package uchiwa
 
 import (
 	"testing"
+	"time"
 
 	"github.com/sensu/uchiwa/uchiwa/config"
+	"github.com/sensu/uchiwa/uchiwa/structs"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -31,6 +33,15 @@ func TestInitDatacenters(t *testing.T) {
 	assert.Equal(t, 1, len((*datacenters)[1].APIs))
 	assert.Equal(t, "bar", (*datacenters)[1].Name)
 
+	// A datacenter with custom headers
+	conf = config.Config{
+		Sensu: []config.SensuConfig{
+			{Name: "foo", URL: "http://10.0.0.1:4567", Headers: map[string]string{"X-Tenant": "ops"}},
+		},
+	}
+	datacenters = initDatacenters(&conf)
+	assert.Equal(t, map[string]string{"X-Tenant": "ops"}, (*datacenters)[0].APIs[0].Headers)
+
 	// One datacenter with three APIs
 	conf = config.Config{
 		Sensu: []config.SensuConfig{
@@ -60,3 +71,46 @@ func TestInitDatacenters(t *testing.T) {
 	assert.Equal(t, "foo", (*datacenters)[0].Name)
 	assert.Equal(t, "bar", (*datacenters)[1].Name)
 }
+
+func TestUpdateData(t *testing.T) {
+	u := &Uchiwa{Config: &config.Config{}}
+	now := time.Unix(1500000000, 0)
+
+	good := &structs.Data{
+		Dc:     []*structs.Datacenter{&structs.Datacenter{Name: "us-east-1"}},
+		Events: []interface{}{"foo"},
+	}
+	down := &structs.Data{
+		Health: structs.Health{Sensu: map[string]structs.SensuHealth{"us-east-1": {Output: "down", Status: 2}}},
+	}
+
+	// Stale data disabled
+	u.updateData(good, now)
+	assert.Equal(t, good, u.Data)
+	u.updateData(down, now.Add(10*time.Second))
+	assert.Equal(t, down, u.Data)
+	assert.False(t, u.stale)
+
+	// Stale data enabled
+	u.Config.Uchiwa.StaleData = config.StaleData{Enabled: true, Threshold: 60}
+	u.updateData(good, now)
+	u.updateData(down, now.Add(10*time.Second))
+	assert.True(t, u.stale)
+	assert.Equal(t, []interface{}{"foo"}, u.Data.Events)
+	assert.True(t, u.Data.Health.Stale)
+	assert.Equal(t, "down", u.Data.Health.Sensu["us-east-1"].Output)
+	assert.False(t, good.Health.Stale)
+
+	// Threshold exceeded
+	u.updateData(down, now.Add(120*time.Second))
+	assert.False(t, u.stale)
+	assert.Equal(t, down, u.Data)
+
+	// A datacenter is back
+	u.updateData(good, now.Add(130*time.Second))
+	assert.False(t, u.stale)
+	assert.Equal(t, good, u.Data)
+
+	// Only the successful refreshes increment the data version
+	assert.Equal(t, uint64(3), u.dataVersion)
+}
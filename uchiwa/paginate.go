@@ -0,0 +1,201 @@
+package uchiwa
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// totalCountHeader contains the number of elements of a paginated list
+const totalCountHeader = "X-Total-Count"
+
+// Sort directions of a list
+const (
+	sortAscending  = "asc"
+	sortDescending = "desc"
+)
+
+// sortableFields contains the top-level fields the lists can be sorted by
+var sortableFields = []string{"dc", "name", "status", "timestamp"}
+
+// listPage holds the pagination and sorting parameters of a list request. A
+// limit of zero means no limit
+type listPage struct {
+	limit  int
+	offset int
+	sort   string
+	dir    string
+}
+
+// parseListPage parses the limit, offset, sort and dir parameters of a list
+// request. The list can be sorted by any of the provided fields. The order
+// parameter is an alias of dir, which must not conflict with it
+func parseListPage(query url.Values, fields []string) (listPage, error) {
+	var page listPage
+
+	if l := query.Get("limit"); l != "" {
+		limit, err := strconv.Atoi(l)
+		if err != nil || limit < 0 {
+			return page, fmt.Errorf("Invalid limit parameter '%s'", l)
+		}
+		page.limit = limit
+	}
+
+	if o := query.Get("offset"); o != "" {
+		offset, err := strconv.Atoi(o)
+		if err != nil || offset < 0 {
+			return page, fmt.Errorf("Invalid offset parameter '%s'", o)
+		}
+		page.offset = offset
+	}
+
+	if s := query.Get("sort"); s != "" {
+		valid := false
+		for _, field := range fields {
+			if s == field {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return page, fmt.Errorf("Invalid sort parameter '%s'", s)
+		}
+		page.sort = s
+	}
+
+	for _, param := range []string{"dir", "order"} {
+		dir := query.Get(param)
+		if dir == "" {
+			continue
+		}
+		if dir != sortAscending && dir != sortDescending {
+			return page, fmt.Errorf("Invalid %s parameter '%s'", param, dir)
+		}
+		if page.dir != "" && page.dir != dir {
+			return page, fmt.Errorf("Conflicting dir '%s' and order '%s' parameters", page.dir, dir)
+		}
+		page.dir = dir
+	}
+
+	return page, nil
+}
+
+// sortList sorts the provided elements by a top-level field. The elements
+// missing the field are kept last, in their original order, and the list is
+// left untouched if the field holds values of different types
+func sortList(list []interface{}, field string, descending bool) []interface{} {
+	var kind string
+	for _, e := range list {
+		element, _ := e.(map[string]interface{})
+		value, ok := element[field]
+		if !ok || value == nil {
+			continue
+		}
+
+		var current string
+		switch value.(type) {
+		case string:
+			current = "string"
+		case float64, int, int64:
+			current = "number"
+		default:
+			return list
+		}
+
+		if kind != "" && kind != current {
+			return list
+		}
+		kind = current
+	}
+
+	number := func(v interface{}) float64 {
+		switch n := v.(type) {
+		case int:
+			return float64(n)
+		case int64:
+			return float64(n)
+		default:
+			f, _ := v.(float64)
+			return f
+		}
+	}
+
+	sorted := make([]interface{}, len(list))
+	copy(sorted, list)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, _ := sorted[i].(map[string]interface{})
+		b, _ := sorted[j].(map[string]interface{})
+		va, okA := a[field]
+		vb, okB := b[field]
+		okA, okB = okA && va != nil, okB && vb != nil
+		if !okA || !okB {
+			return okA && !okB
+		}
+
+		if kind == "string" {
+			if descending {
+				return va.(string) > vb.(string)
+			}
+			return va.(string) < vb.(string)
+		}
+
+		if descending {
+			return number(va) > number(vb)
+		}
+		return number(va) < number(vb)
+	})
+
+	return sorted
+}
+
+// pageLink returns the link to the page of the list at the provided offset
+func pageLink(r *http.Request, offset int, rel string) string {
+	query := r.URL.Query()
+	query.Set("offset", strconv.Itoa(offset))
+
+	return fmt.Sprintf("<%s?%s>; rel=\"%s\"", r.URL.Path, query.Encode(), rel)
+}
+
+// paginateList sorts the provided elements and returns the requested page.
+// The total number of elements is provided in the X-Total-Count header, and
+// the links to the previous and next pages in the Link header. It must be
+// called on the elements visible to the user, so the pagination never
+// reveals the hidden elements
+func paginateList(w http.ResponseWriter, r *http.Request, list []interface{}, page listPage) []interface{} {
+	if page.sort != "" {
+		list = sortList(list, page.sort, page.dir == sortDescending)
+	}
+
+	total := len(list)
+	w.Header().Set(totalCountHeader, strconv.Itoa(total))
+
+	start := page.offset
+	if start > total {
+		start = total
+	}
+	end := total
+	if page.limit > 0 && start+page.limit < total {
+		end = start + page.limit
+	}
+
+	var links []string
+	if page.limit > 0 && end < total {
+		links = append(links, pageLink(r, end, "next"))
+	}
+	if start > 0 {
+		prev := start - page.limit
+		if page.limit == 0 || prev < 0 {
+			prev = 0
+		}
+		links = append(links, pageLink(r, prev, "prev"))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+
+	return list[start:end]
+}
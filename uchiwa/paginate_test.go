@@ -0,0 +1,97 @@
+package uchiwa
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseListPage(t *testing.T) {
+	page, err := parseListPage(url.Values{}, sortableFields)
+	assert.Nil(t, err)
+	assert.Equal(t, listPage{}, page)
+
+	page, err = parseListPage(url.Values{"limit": {"10"}, "offset": {"20"}, "sort": {"name"}, "dir": {"desc"}}, sortableFields)
+	assert.Nil(t, err)
+	assert.Equal(t, listPage{limit: 10, offset: 20, sort: "name", dir: "desc"}, page)
+
+	// The order parameter is an alias of dir
+	page, err = parseListPage(url.Values{"sort": {"name"}, "order": {"desc"}}, sortableFields)
+	assert.Nil(t, err)
+	assert.Equal(t, "desc", page.dir)
+
+	page, err = parseListPage(url.Values{"dir": {"asc"}, "order": {"asc"}}, sortableFields)
+	assert.Nil(t, err)
+	assert.Equal(t, "asc", page.dir)
+
+	for _, query := range []url.Values{
+		{"limit": {"foo"}},
+		{"limit": {"-1"}},
+		{"offset": {"-1"}},
+		{"sort": {"output"}},
+		{"dir": {"up"}},
+		{"order": {"up"}},
+		{"dir": {"asc"}, "order": {"desc"}},
+	} {
+		_, err = parseListPage(query, sortableFields)
+		assert.NotNil(t, err, "%v", query)
+	}
+}
+
+func TestSortList(t *testing.T) {
+	list := []interface{}{
+		map[string]interface{}{"name": "foo", "status": float64(1)},
+		map[string]interface{}{"status": float64(2)},
+		map[string]interface{}{"name": "bar", "status": float64(0)},
+		map[string]interface{}{"name": "qux"},
+	}
+
+	names := func(list []interface{}) []interface{} {
+		var result []interface{}
+		for _, e := range list {
+			result = append(result, e.(map[string]interface{})["name"])
+		}
+		return result
+	}
+
+	// The elements missing the field are kept last
+	assert.Equal(t, []interface{}{"bar", "foo", "qux", nil}, names(sortList(list, "name", false)))
+	assert.Equal(t, []interface{}{"qux", "foo", "bar", nil}, names(sortList(list, "name", true)))
+	assert.Equal(t, []interface{}{nil, "foo", "bar", "qux"}, names(sortList(list, "status", true)))
+
+	// The list is left untouched with values of different types
+	mixed := append(list, map[string]interface{}{"name": float64(1)})
+	assert.Equal(t, mixed, sortList(mixed, "name", false))
+}
+
+func TestPaginateList(t *testing.T) {
+	list := []interface{}{"a", "b", "c", "d", "e"}
+
+	r := httptest.NewRequest("GET", "/clients?limit=2&offset=2", nil)
+	w := httptest.NewRecorder()
+	page, _ := parseListPage(r.URL.Query(), sortableFields)
+	assert.Equal(t, []interface{}{"c", "d"}, paginateList(w, r, list, page))
+	assert.Equal(t, "5", w.Header().Get(totalCountHeader))
+	assert.Equal(t, `</clients?limit=2&offset=4>; rel="next", </clients?limit=2&offset=0>; rel="prev"`, w.Header().Get("Link"))
+
+	// Last page
+	r = httptest.NewRequest("GET", "/clients?limit=2&offset=4", nil)
+	w = httptest.NewRecorder()
+	page, _ = parseListPage(r.URL.Query(), sortableFields)
+	assert.Equal(t, []interface{}{"e"}, paginateList(w, r, list, page))
+	assert.Equal(t, `</clients?limit=2&offset=2>; rel="prev"`, w.Header().Get("Link"))
+
+	// Offset beyond the list
+	r = httptest.NewRequest("GET", "/clients?offset=10", nil)
+	w = httptest.NewRecorder()
+	page, _ = parseListPage(r.URL.Query(), sortableFields)
+	assert.Equal(t, []interface{}{}, paginateList(w, r, list, page))
+
+	// No pagination
+	r = httptest.NewRequest("GET", "/clients", nil)
+	w = httptest.NewRecorder()
+	assert.Equal(t, list, paginateList(w, r, list, listPage{}))
+	assert.Equal(t, "", w.Header().Get("Link"))
+}
@@ -0,0 +1,147 @@
+package uchiwa
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sensu/uchiwa/uchiwa/structs"
+)
+
+// prometheusContentType is the content type of the Prometheus text
+// exposition format
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// prometheusCounts contains the resource counts of the datacenters exposed as
+// gauges, along with their description
+var prometheusCounts = []struct {
+	name string
+	help string
+}{
+	{"aggregates", "Number of aggregates"},
+	{"checks", "Number of checks"},
+	{"clients", "Number of clients"},
+	{"silenced", "Number of silence entries"},
+	{"stashes", "Number of stashes"},
+}
+
+// prometheusStatuses contains the statuses of the events gauge
+var prometheusStatuses = []string{"critical", "warning", "unknown"}
+
+// prometheusDatacenter holds the metrics of a datacenter
+type prometheusDatacenter struct {
+	name   string
+	up     bool
+	counts map[string]int
+	events map[string]int
+}
+
+// escapeLabelValue escapes the backslashes, double quotes and line feeds of a
+// label value, as required by the Prometheus text exposition format
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// buildPrometheusDatacenters returns the metrics of the provided datacenters,
+// sorted by name. A datacenter is up unless its health reports it unreachable,
+// and its counts are only known when it was polled
+func buildPrometheusDatacenters(names []string, polled []*structs.Datacenter, events []interface{}, health map[string]structs.SensuHealth) []prometheusDatacenter {
+	metrics := make(map[string]map[string]int, len(polled))
+	for _, dc := range polled {
+		metrics[dc.Name] = dc.Metrics
+	}
+
+	statuses := make(map[string]map[string]int)
+	for _, e := range events {
+		event, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		dc, ok := event["dc"].(string)
+		if !ok {
+			continue
+		}
+
+		check, ok := event["check"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		var status string
+		switch check["status"] {
+		case 0.0:
+			continue
+		case 1.0:
+			status = "warning"
+		case 2.0:
+			status = "critical"
+		default:
+			status = "unknown"
+		}
+
+		if statuses[dc] == nil {
+			statuses[dc] = make(map[string]int)
+		}
+		statuses[dc][status]++
+	}
+
+	datacenters := make([]prometheusDatacenter, 0, len(names))
+	for _, name := range names {
+		_, polled := metrics[name]
+		datacenters = append(datacenters, prometheusDatacenter{
+			name:   name,
+			up:     polled && health[name].Status != 2,
+			counts: metrics[name],
+			events: statuses[name],
+		})
+	}
+
+	sort.Slice(datacenters, func(i, j int) bool {
+		return datacenters[i].name < datacenters[j].name
+	})
+
+	return datacenters
+}
+
+// encodePrometheus encodes the metrics of the provided datacenters in the
+// Prometheus text exposition format. The counts of the datacenters that were
+// not polled are omitted
+func encodePrometheus(datacenters []prometheusDatacenter) []byte {
+	buf := &bytes.Buffer{}
+
+	fmt.Fprintln(buf, "# HELP uchiwa_datacenter_up Whether the datacenter is reachable")
+	fmt.Fprintln(buf, "# TYPE uchiwa_datacenter_up gauge")
+	for _, dc := range datacenters {
+		up := 0
+		if dc.up {
+			up = 1
+		}
+		fmt.Fprintf(buf, "uchiwa_datacenter_up{dc=\"%s\"} %d\n", escapeLabelValue(dc.name), up)
+	}
+
+	for _, count := range prometheusCounts {
+		fmt.Fprintf(buf, "# HELP uchiwa_%s %s\n", count.name, count.help)
+		fmt.Fprintf(buf, "# TYPE uchiwa_%s gauge\n", count.name)
+		for _, dc := range datacenters {
+			if dc.counts == nil {
+				continue
+			}
+			fmt.Fprintf(buf, "uchiwa_%s{dc=\"%s\"} %d\n", count.name, escapeLabelValue(dc.name), dc.counts[count.name])
+		}
+	}
+
+	fmt.Fprintln(buf, "# HELP uchiwa_events Number of events, by status")
+	fmt.Fprintln(buf, "# TYPE uchiwa_events gauge")
+	for _, dc := range datacenters {
+		if dc.counts == nil {
+			continue
+		}
+		for _, status := range prometheusStatuses {
+			fmt.Fprintf(buf, "uchiwa_events{dc=\"%s\",status=\"%s\"} %d\n", escapeLabelValue(dc.name), status, dc.events[status])
+		}
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,53 @@
+package uchiwa
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sensu/uchiwa/uchiwa/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscapeLabelValue(t *testing.T) {
+	assert.Equal(t, "us-east-1", escapeLabelValue("us-east-1"))
+	assert.Equal(t, `foo\"bar\\baz\nqux`, escapeLabelValue("foo\"bar\\baz\nqux"))
+}
+
+func TestEncodePrometheus(t *testing.T) {
+	names := []string{"us-west-1", "us-east-1", `eu"west`}
+	polled := []*structs.Datacenter{
+		&structs.Datacenter{Name: "us-west-1", Metrics: map[string]int{"clients": 3, "checks": 2}},
+		&structs.Datacenter{Name: `eu"west`, Metrics: map[string]int{"clients": 1}},
+	}
+	events := []interface{}{
+		map[string]interface{}{"dc": "us-west-1", "check": map[string]interface{}{"status": 2.0}},
+		map[string]interface{}{"dc": "us-west-1", "check": map[string]interface{}{"status": 2.0}},
+		map[string]interface{}{"dc": "us-west-1", "check": map[string]interface{}{"status": 1.0}},
+		map[string]interface{}{"dc": "us-west-1", "check": map[string]interface{}{"status": 3.0}},
+		map[string]interface{}{"dc": "us-west-1", "check": map[string]interface{}{"status": 0.0}},
+	}
+	health := map[string]structs.SensuHealth{
+		"us-east-1": structs.SensuHealth{Output: "Connection error. Is the Sensu API running?", Status: 2},
+	}
+
+	datacenters := buildPrometheusDatacenters(names, polled, events, health)
+	assert.Equal(t, 3, len(datacenters))
+	assert.Equal(t, `eu"west`, datacenters[0].name)
+
+	output := string(encodePrometheus(datacenters))
+	lines := strings.Split(output, "\n")
+	assert.Contains(t, lines, `uchiwa_datacenter_up{dc="eu\"west"} 1`)
+	assert.Contains(t, lines, `uchiwa_datacenter_up{dc="us-east-1"} 0`)
+	assert.Contains(t, lines, `uchiwa_datacenter_up{dc="us-west-1"} 1`)
+	assert.Contains(t, lines, `uchiwa_clients{dc="us-west-1"} 3`)
+	assert.Contains(t, lines, `uchiwa_clients{dc="eu\"west"} 1`)
+	assert.Contains(t, lines, `uchiwa_stashes{dc="us-west-1"} 0`)
+	assert.Contains(t, lines, `uchiwa_events{dc="us-west-1",status="critical"} 2`)
+	assert.Contains(t, lines, `uchiwa_events{dc="us-west-1",status="warning"} 1`)
+	assert.Contains(t, lines, `uchiwa_events{dc="us-west-1",status="unknown"} 1`)
+	assert.Contains(t, lines, `uchiwa_events{dc="eu\"west",status="critical"} 0`)
+	assert.Contains(t, lines, "# TYPE uchiwa_events gauge")
+
+	// The counts of the unreachable datacenters are unknown
+	assert.NotContains(t, output, `uchiwa_clients{dc="us-east-1"}`)
+}
@@ -0,0 +1,98 @@
+package uchiwa
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/sensu/uchiwa/uchiwa/authentication"
+	"github.com/sensu/uchiwa/uchiwa/helpers"
+	"github.com/sensu/uchiwa/uchiwa/logger"
+)
+
+// rateLimitClaim is the JWT claim containing a per-user rate limit
+const rateLimitClaim = "rate_limit"
+
+// rateLimiter counts the requests made by each user during a one minute window
+type rateLimiter struct {
+	mutex  sync.Mutex
+	counts map[string]int
+	window time.Time
+}
+
+// allow records a request for the provided key and returns false if the key
+// already reached the provided limit during the current window
+func (l *rateLimiter) allow(key string, limit int, now time.Time) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.counts == nil || now.Sub(l.window) >= time.Minute {
+		l.counts = make(map[string]int)
+		l.window = now
+	}
+
+	if l.counts[key] >= limit {
+		return false
+	}
+
+	l.counts[key]++
+	return true
+}
+
+// getRateLimit returns the rate limit contained in the JWT claims, if it's a
+// positive integer, capped at the provided maximum. Otherwise the default
+// limit is returned
+func getRateLimit(token *jwt.Token, defaultLimit, max int) int {
+	if token == nil {
+		return defaultLimit
+	}
+
+	claim, ok := token.Claims[rateLimitClaim]
+	if !ok {
+		return defaultLimit
+	}
+
+	limit, ok := claim.(float64)
+	if !ok || limit < 1 || limit != math.Trunc(limit) {
+		logger.Debugf("Ignoring the invalid %s claim: %+v", rateLimitClaim, claim)
+		return defaultLimit
+	}
+
+	if max > 0 && int(limit) > max {
+		return max
+	}
+
+	return int(limit)
+}
+
+// rateLimitHandler rejects the requests of users who exceeded their rate limit
+func (u *Uchiwa) rateLimitHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		config := u.Config.Uchiwa.RateLimit
+		if config.Requests <= 0 || u.rateLimiter == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token := authentication.GetJWTFromContext(r)
+
+		// Identify anonymous requests by their IP address
+		key := helpers.GetIP(r)
+		if token != nil {
+			key = getUsername(token)
+		}
+
+		limit := getRateLimit(token, config.Requests, config.Max)
+		if !u.rateLimiter.allow(key, limit, time.Now()) {
+			logger.Debugf("Rate limit of %d requests per minute exceeded by %s", limit, key)
+			w.Header().Set("Retry-After", strconv.Itoa(60))
+			http.Error(w, "Too many requests", http.StatusTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
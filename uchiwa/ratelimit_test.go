@@ -0,0 +1,48 @@
+package uchiwa
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	l := &rateLimiter{}
+	now := time.Now()
+
+	assert.True(t, l.allow("foo", 2, now))
+	assert.True(t, l.allow("foo", 2, now))
+	assert.False(t, l.allow("foo", 2, now))
+
+	// Every key has its own counter
+	assert.True(t, l.allow("bar", 2, now))
+
+	// The counters are reset once the window is over
+	assert.True(t, l.allow("foo", 2, now.Add(time.Minute)))
+}
+
+func TestGetRateLimit(t *testing.T) {
+	assert.Equal(t, 60, getRateLimit(nil, 60, 1000))
+
+	token := jwt.New(jwt.GetSigningMethod("RS256"))
+	assert.Equal(t, 60, getRateLimit(token, 60, 1000))
+
+	token.Claims["rate_limit"] = 600.0
+	assert.Equal(t, 600, getRateLimit(token, 60, 1000))
+
+	// Capped at the maximum
+	token.Claims["rate_limit"] = 5000.0
+	assert.Equal(t, 1000, getRateLimit(token, 60, 1000))
+
+	// Invalid claims
+	token.Claims["rate_limit"] = -1.0
+	assert.Equal(t, 60, getRateLimit(token, 60, 1000))
+
+	token.Claims["rate_limit"] = 1.5
+	assert.Equal(t, 60, getRateLimit(token, 60, 1000))
+
+	token.Claims["rate_limit"] = "600"
+	assert.Equal(t, 60, getRateLimit(token, 60, 1000))
+}
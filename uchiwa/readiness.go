@@ -0,0 +1,69 @@
+package uchiwa
+
+import (
+	"sync"
+
+	"github.com/sensu/uchiwa/uchiwa/sensu"
+	"github.com/sensu/uchiwa/uchiwa/structs"
+)
+
+// readiness tracks whether the initial poll of the datacenters, including its
+// retries, is over, and which datacenters were successfully polled at least
+// once. The datacenters must be accessed with the mutex held
+type readiness struct {
+	once        sync.Once
+	ready       chan struct{}
+	datacenters map[string]bool
+}
+
+// readinessStatus is the response of the /health/ready endpoint
+type readinessStatus struct {
+	Ready       bool            `json:"ready"`
+	Datacenters map[string]bool `json:"datacenters"`
+}
+
+func newReadiness() *readiness {
+	return &readiness{
+		ready:       make(chan struct{}),
+		datacenters: make(map[string]bool),
+	}
+}
+
+// update records the datacenters of the provided data as initialized and
+// marks the initial poll as over
+func (r *readiness) update(data *structs.Data) {
+	for _, dc := range data.Dc {
+		r.datacenters[dc.Name] = true
+	}
+
+	r.once.Do(func() { close(r.ready) })
+}
+
+// isReady returns true once the initial poll is over
+func (r *readiness) isReady() bool {
+	if r == nil {
+		return false
+	}
+
+	select {
+	case <-r.ready:
+		return true
+	default:
+		return false
+	}
+}
+
+// status returns the readiness of Uchiwa and the initialization status of
+// each provided datacenter
+func (r *readiness) status(datacenters []sensu.Sensu) readinessStatus {
+	status := readinessStatus{
+		Ready:       r.isReady(),
+		Datacenters: make(map[string]bool, len(datacenters)),
+	}
+
+	for _, dc := range datacenters {
+		status.Datacenters[dc.Name] = r != nil && r.datacenters[dc.Name]
+	}
+
+	return status
+}
@@ -0,0 +1,26 @@
+package uchiwa
+
+import (
+	"testing"
+
+	"github.com/sensu/uchiwa/uchiwa/sensu"
+	"github.com/sensu/uchiwa/uchiwa/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadiness(t *testing.T) {
+	datacenters := []sensu.Sensu{{Name: "us-east-1"}, {Name: "us-west-1"}}
+
+	var missing *readiness
+	assert.Equal(t, readinessStatus{Datacenters: map[string]bool{"us-east-1": false, "us-west-1": false}}, missing.status(datacenters))
+
+	r := newReadiness()
+	assert.False(t, r.isReady())
+
+	r.update(&structs.Data{Dc: []*structs.Datacenter{{Name: "us-east-1"}}})
+	assert.Equal(t, readinessStatus{Ready: true, Datacenters: map[string]bool{"us-east-1": true, "us-west-1": false}}, r.status(datacenters))
+
+	// A datacenter remains initialized once polled
+	r.update(&structs.Data{Dc: []*structs.Datacenter{{Name: "us-west-1"}}})
+	assert.Equal(t, readinessStatus{Ready: true, Datacenters: map[string]bool{"us-east-1": true, "us-west-1": true}}, r.status(datacenters))
+}
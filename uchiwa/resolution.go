@@ -0,0 +1,141 @@
+package uchiwa
+
+import (
+	"sort"
+	"time"
+
+	"github.com/sensu/uchiwa/uchiwa/structs"
+)
+
+// resolutionSamplesLimit is the number of event resolutions remembered
+const resolutionSamplesLimit = 1000
+
+// firingEvent holds an unresolved event and the time it started firing
+type firingEvent struct {
+	dc    string
+	since int64
+}
+
+// resolutionSample holds the time an event was resolved and how long, in
+// seconds, it took to resolve
+type resolutionSample struct {
+	Dc       string
+	Resolved int64
+	Duration int64
+}
+
+// resolutionStats holds the distribution of the time to resolution, in
+// seconds, of the events
+type resolutionStats struct {
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P95   float64 `json:"p95"`
+	P99   float64 `json:"p99"`
+	Max   float64 `json:"max"`
+}
+
+// resolutionLog records how long the events take to resolve across the polls.
+// It must be accessed with the mutex held
+type resolutionLog struct {
+	firing  map[string]firingEvent
+	samples []resolutionSample
+}
+
+// eventStart returns when the provided event started firing, which is its
+// last state change if known
+func eventStart(event map[string]interface{}, now time.Time) int64 {
+	if change, ok := event["last_state_change"].(float64); ok && change > 0 {
+		return int64(change)
+	}
+	return now.Unix()
+}
+
+// update records the events of the provided data, and the resolution of the
+// events no longer present. The events of the datacenters that could not be
+// polled are considered unresolved
+func (l *resolutionLog) update(data *structs.Data, now time.Time) {
+	if len(data.Dc) == 0 {
+		return
+	}
+
+	polled := make(map[string]bool, len(data.Dc))
+	for _, dc := range data.Dc {
+		polled[dc.Name] = true
+	}
+
+	// The new events are assumed to have started firing now, unless they
+	// provide their last state change
+	firing := make(map[string]firingEvent, len(data.Events))
+	for _, e := range data.Events {
+		event, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		id, ok := event["_id"].(string)
+		if !ok {
+			continue
+		}
+
+		if previous, ok := l.firing[id]; ok {
+			firing[id] = previous
+			continue
+		}
+
+		dc, _ := event["dc"].(string)
+		firing[id] = firingEvent{dc: dc, since: eventStart(event, now)}
+	}
+
+	for id, event := range l.firing {
+		if _, ok := firing[id]; ok {
+			continue
+		}
+
+		if !polled[event.dc] {
+			firing[id] = event
+			continue
+		}
+
+		l.samples = append(l.samples, resolutionSample{Dc: event.dc, Resolved: now.Unix(), Duration: now.Unix() - event.since})
+	}
+
+	if extra := len(l.samples) - resolutionSamplesLimit; extra > 0 {
+		l.samples = append([]resolutionSample(nil), l.samples[extra:]...)
+	}
+
+	l.firing = firing
+}
+
+// buildResolutionStats computes the distribution of the time to resolution of
+// the events resolved since the provided time
+func buildResolutionStats(samples []resolutionSample, since time.Time) resolutionStats {
+	var stats resolutionStats
+	var durations []float64
+	var total float64
+
+	for _, sample := range samples {
+		if sample.Resolved < since.Unix() {
+			continue
+		}
+
+		durations = append(durations, float64(sample.Duration))
+		total += float64(sample.Duration)
+	}
+
+	stats.Count = len(durations)
+	if stats.Count == 0 {
+		return stats
+	}
+
+	sort.Float64s(durations)
+	stats.Mean = total / float64(stats.Count)
+	stats.P50 = percentile(durations, 0.5)
+	stats.P90 = percentile(durations, 0.9)
+	stats.P95 = percentile(durations, 0.95)
+	stats.P99 = percentile(durations, 0.99)
+	stats.Max = durations[len(durations)-1]
+
+	return stats
+}
@@ -0,0 +1,67 @@
+package uchiwa
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/sensu/uchiwa/uchiwa/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolutionLog(t *testing.T) {
+	l := &resolutionLog{}
+	dcs := []*structs.Datacenter{{Name: "us-east-1"}, {Name: "us-west-1"}}
+	foo := map[string]interface{}{"_id": "us-east-1/foo/cpu", "dc": "us-east-1", "last_state_change": float64(50)}
+	bar := map[string]interface{}{"_id": "us-east-1/bar/cpu", "dc": "us-east-1"}
+	qux := map[string]interface{}{"_id": "us-west-1/qux/cpu", "dc": "us-west-1"}
+
+	l.update(&structs.Data{Dc: dcs, Events: []interface{}{foo, qux, "foo"}}, time.Unix(100, 0))
+	l.update(&structs.Data{Dc: dcs, Events: []interface{}{foo, bar, qux}}, time.Unix(200, 0))
+
+	// us-west-1 could not be polled, so qux is not resolved
+	l.update(&structs.Data{Dc: dcs[:1], Events: []interface{}{}}, time.Unix(300, 0))
+	samples := append([]resolutionSample(nil), l.samples...)
+	sort.Slice(samples, func(i, j int) bool { return samples[i].Duration < samples[j].Duration })
+	assert.Equal(t, []resolutionSample{
+		{Dc: "us-east-1", Resolved: 300, Duration: 100},
+		{Dc: "us-east-1", Resolved: 300, Duration: 250},
+	}, samples)
+
+	l.update(&structs.Data{Dc: dcs}, time.Unix(400, 0))
+	assert.Equal(t, 3, len(l.samples))
+	assert.Equal(t, resolutionSample{Dc: "us-west-1", Resolved: 400, Duration: 300}, l.samples[2])
+
+	// Every datacenter is unreachable
+	l.update(&structs.Data{}, time.Unix(500, 0))
+	assert.Equal(t, 3, len(l.samples))
+
+	// The retention is bounded
+	for i := 0; i < resolutionSamplesLimit; i++ {
+		l.update(&structs.Data{Dc: dcs, Events: []interface{}{map[string]interface{}{"_id": fmt.Sprint(i), "dc": "us-east-1"}}}, time.Unix(600, 0))
+	}
+	assert.Equal(t, resolutionSamplesLimit, len(l.samples))
+}
+
+func TestBuildResolutionStats(t *testing.T) {
+	var samples []resolutionSample
+	for i := 1; i <= 100; i++ {
+		samples = append(samples, resolutionSample{Dc: "us-east-1", Resolved: int64(i), Duration: int64(i * 10)})
+	}
+
+	stats := buildResolutionStats(samples, time.Unix(0, 0))
+	assert.Equal(t, 100, stats.Count)
+	assert.Equal(t, 505.0, stats.Mean)
+	assert.Equal(t, 500.0, stats.P50)
+	assert.Equal(t, 900.0, stats.P90)
+	assert.Equal(t, 950.0, stats.P95)
+	assert.Equal(t, 990.0, stats.P99)
+	assert.Equal(t, 1000.0, stats.Max)
+
+	stats = buildResolutionStats(samples, time.Unix(91, 0))
+	assert.Equal(t, 10, stats.Count)
+	assert.Equal(t, 950.0, stats.P50)
+
+	assert.Equal(t, resolutionStats{}, buildResolutionStats(nil, time.Unix(0, 0)))
+}
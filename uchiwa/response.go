@@ -0,0 +1,350 @@
+package uchiwa
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sensu/uchiwa/uchiwa/logger"
+)
+
+// truncatedHeader is set on list responses that were cut short because they
+// exceeded the configured response budget
+const truncatedHeader = "X-Truncated"
+
+// digestHeader contains, when requested with the digest parameter, the
+// SHA-256 digest of the uncompressed list response
+const digestHeader = "X-Content-Digest"
+
+// dataVersionHeader contains the refresh generation of the data, so the
+// clients can tell whether it changed since their previous request
+const dataVersionHeader = "X-Data-Version"
+
+// gzipWriters pools the gzip writers used to compress the responses, in
+// order to avoid allocating a new writer for every request
+var gzipWriters = sync.Pool{
+	New: func() interface{} {
+		return gzip.NewWriter(ioutil.Discard)
+	},
+}
+
+// getGzipWriter returns a pooled gzip writer that writes to w. The writer
+// must be closed and released with putGzipWriter once the response is written
+func getGzipWriter(w io.Writer) *gzip.Writer {
+	gz := gzipWriters.Get().(*gzip.Writer)
+	gz.Reset(w)
+	return gz
+}
+
+// putGzipWriter closes the provided gzip writer, flushing any pending data,
+// and returns it to the pool
+func putGzipWriter(gz *gzip.Writer) {
+	gz.Close()
+	gz.Reset(ioutil.Discard)
+	gzipWriters.Put(gz)
+}
+
+// nopWriteCloser adds a no-op Close method to a writer
+type nopWriteCloser struct {
+	io.Writer
+}
+
+// Close does nothing
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// pooledGzipWriter is a pooled gzip writer, released once closed
+type pooledGzipWriter struct {
+	*gzip.Writer
+}
+
+// Close flushes the pending data and returns the writer to the pool
+func (gz pooledGzipWriter) Close() error {
+	err := gz.Writer.Close()
+	putGzipWriter(gz.Writer)
+	return err
+}
+
+// encodedWriter writes the provided status and returns a writer compressing
+// the body with the content coding negotiated with the client. The returned
+// writer must be closed for the response to be complete
+func (u *Uchiwa) encodedWriter(w http.ResponseWriter, r *http.Request, status int) io.WriteCloser {
+	encoding := u.negotiateEncoding(w, r)
+	if encoding == encodingIdentity {
+		w.WriteHeader(status)
+		return nopWriteCloser{w}
+	}
+
+	w.Header().Set("Content-Encoding", encoding)
+	w.WriteHeader(status)
+
+	if encoding == encodingGzip {
+		return pooledGzipWriter{getGzipWriter(w)}
+	}
+
+	// The deflate content coding is the zlib format
+	return zlib.NewWriter(w)
+}
+
+// writeEncoded writes the provided body along with the provided status,
+// compressed with the content coding negotiated with the client. The
+// compressor is closed before returning, so the response is always complete
+func (u *Uchiwa) writeEncoded(w http.ResponseWriter, r *http.Request, status int, body []byte) error {
+	ew := u.encodedWriter(w, r, status)
+	if _, err := ew.Write(body); err != nil {
+		ew.Close()
+		return err
+	}
+	return ew.Close()
+}
+
+// writeJSON writes v as JSON along with the provided status, compressed with
+// gzip or deflate if supported by the client. v is entirely encoded before
+// anything is written, so an encoding error results in a 500 rather than a
+// partial response
+func (u *Uchiwa) writeJSON(w http.ResponseWriter, r *http.Request, status int, v interface{}) error {
+	buf := &bytes.Buffer{}
+	if err := json.NewEncoder(buf).Encode(v); err != nil {
+		http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
+		return err
+	}
+
+	// Create header
+	w.Header().Set("Accept-Charset", "utf-8")
+	w.Header().Set("Content-Type", "application/json")
+
+	return u.writeEncoded(w, r, status, buf.Bytes())
+}
+
+// wantsFormat returns whether the response is requested in the provided
+// format rather than JSON, either with the format parameter or with the Accept
+// header. JSON is kept unless the media type of the format is explicitly
+// accepted before it
+func wantsFormat(r *http.Request, format, mediaType string) (bool, error) {
+	switch f := r.URL.Query().Get("format"); f {
+	case format:
+		return true, nil
+	case "json":
+		return false, nil
+	case "":
+	default:
+		return false, fmt.Errorf("Invalid format parameter '%s'", f)
+	}
+
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		t, _, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		if t == "application/json" {
+			return false, nil
+		}
+		if t == mediaType {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// encodeList encodes the provided elements as a JSON array, one at a time, to
+// the provided writer, so the whole array is never held in memory. If budget
+// is positive, the encoding stops before the array would exceed this number of
+// bytes and truncated is true. The output is identical to a json.Encoder when
+// nothing is truncated
+func encodeList(w io.Writer, list []interface{}, budget int) (truncated bool, err error) {
+	written := 0
+	write := func(b []byte) error {
+		n, err := w.Write(b)
+		written += n
+		return err
+	}
+
+	if err := write([]byte{'['}); err != nil {
+		return false, err
+	}
+	for i, element := range list {
+		b, err := json.Marshal(element)
+		if err != nil {
+			return false, err
+		}
+
+		// Always keep room for the closing bracket and newline
+		size := len(b) + 2
+		if i > 0 {
+			size++
+		}
+		if budget > 0 && written+size > budget {
+			truncated = true
+			break
+		}
+
+		if i > 0 {
+			b = append([]byte{','}, b...)
+		}
+		if err := write(b); err != nil {
+			return false, err
+		}
+	}
+
+	return truncated, write([]byte("]\n"))
+}
+
+// writeCacheable writes v as JSON along with its ETag, or only a 304 status if
+// it matches the ETag provided by the client in the If-None-Match header. The
+// ETag is computed on the uncompressed body
+func (u *Uchiwa) writeCacheable(w http.ResponseWriter, r *http.Request, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	sum := sha256.Sum256(b)
+	etag := fmt.Sprintf("\"%x\"", sum[:16])
+	w.Header().Set("ETag", etag)
+
+	for _, match := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		if strings.TrimSpace(match) == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	// Create header
+	w.Header().Set("Accept-Charset", "utf-8")
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := u.writeEncoded(w, r, http.StatusOK, append(b, '\n')); err != nil {
+		logger.Warningf("Cannot write response data: %v", err)
+	}
+}
+
+// listDigest returns the digest of an encoded list, in the format of the
+// Digest header
+func listDigest(b []byte) string {
+	sum := sha256.Sum256(b)
+	return "sha-256=" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// writeList writes the provided elements as a JSON array, compressed with gzip
+// or deflate if supported by the client. The array is truncated, and the
+// X-Truncated header set, when it exceeds the MaxResponseBytes budget. The
+// digest of the array is provided in the X-Content-Digest header if the digest
+// parameter is true, and the refresh generation of the data in the
+// X-Data-Version header. The keys of the elements are normalized according to
+// NormalizeKeys
+func (u *Uchiwa) writeList(w http.ResponseWriter, r *http.Request, list []interface{}) {
+	digest := false
+	if d := r.URL.Query().Get("digest"); d != "" {
+		var err error
+		digest, err = strconv.ParseBool(d)
+		if err != nil {
+			http.Error(w, "Invalid digest parameter", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if list == nil {
+		list = make([]interface{}, 0)
+	}
+	if u.Config.Uchiwa.NormalizeKeys != "" {
+		list = normalizeKeys(list, u.Config.Uchiwa.NormalizeKeys).([]interface{})
+	}
+
+	// Create header
+	w.Header().Set("Accept-Charset", "utf-8")
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(dataVersionHeader, strconv.FormatUint(atomic.LoadUint64(&u.dataVersion), 10))
+
+	// Without any budget or digest, which must be sent before the body, the
+	// list is streamed to the client. Since the status is already sent, the
+	// response is aborted if an element can't be encoded, so the client never
+	// mistakes the incomplete array for a successful response
+	budget := u.Config.Uchiwa.MaxResponseBytes
+	if budget <= 0 && !digest {
+		ew := u.encodedWriter(w, r, http.StatusOK)
+		if _, err := encodeList(ew, list, 0); err != nil {
+			logger.Warningf("Cannot write response data: %v", err)
+			panic(http.ErrAbortHandler)
+		}
+		if err := ew.Close(); err != nil {
+			logger.Warningf("Cannot write response data: %v", err)
+		}
+		return
+	}
+
+	// Otherwise the list is encoded beforehand, within the budget if any
+	buf := &bytes.Buffer{}
+	truncated, err := encodeList(buf, list, budget)
+	if err != nil {
+		w.Header().Del(dataVersionHeader)
+		http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if truncated {
+		w.Header().Set(truncatedHeader, "true")
+	}
+	if digest {
+		w.Header().Set(digestHeader, listDigest(buf.Bytes()))
+	}
+
+	if err := u.writeEncoded(w, r, http.StatusOK, buf.Bytes()); err != nil {
+		logger.Warningf("Cannot write response data: %v", err)
+	}
+}
+
+// headResponseWriter discards the body written in response to a HEAD request,
+// while counting its bytes. The status is held back until the handler returns,
+// so the Content-Length of the body that would have been sent, compressed or
+// not, can be reported
+type headResponseWriter struct {
+	http.ResponseWriter
+
+	status int
+	length int
+}
+
+// WriteHeader records the provided status, which is sent by finish
+func (w *headResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// Write counts and discards the provided bytes
+func (w *headResponseWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	w.length += len(p)
+	return len(p), nil
+}
+
+// finish sends the recorded status along with the Content-Length of the
+// discarded body, unless the handler already set it or the status forbids a
+// body
+func (w *headResponseWriter) finish() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	bodyAllowed := w.status >= 200 && w.status != http.StatusNoContent && w.status != http.StatusNotModified
+	if bodyAllowed && w.Header().Get("Content-Length") == "" {
+		w.Header().Set("Content-Length", strconv.Itoa(w.length))
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+}
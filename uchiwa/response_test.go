@@ -0,0 +1,271 @@
+package uchiwa
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sensu/uchiwa/uchiwa/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeList(t *testing.T) {
+	list := []interface{}{
+		map[string]interface{}{"name": "foo"},
+		map[string]interface{}{"name": "bar"},
+		map[string]interface{}{"name": "qux"},
+	}
+
+	// Budget disabled
+	buf := &bytes.Buffer{}
+	truncated, err := encodeList(buf, list, 0)
+	assert.Nil(t, err)
+	assert.False(t, truncated)
+	assert.Equal(t, "[{\"name\":\"foo\"},{\"name\":\"bar\"},{\"name\":\"qux\"}]\n", buf.String())
+
+	// Budget large enough for every element
+	buf.Reset()
+	truncated, err = encodeList(buf, list, 1024)
+	assert.Nil(t, err)
+	assert.False(t, truncated)
+	assert.Equal(t, "[{\"name\":\"foo\"},{\"name\":\"bar\"},{\"name\":\"qux\"}]\n", buf.String())
+
+	// Budget reached after two elements
+	buf.Reset()
+	truncated, err = encodeList(buf, list, 40)
+	assert.Nil(t, err)
+	assert.True(t, truncated)
+	assert.Equal(t, "[{\"name\":\"foo\"},{\"name\":\"bar\"}]\n", buf.String())
+	assert.True(t, buf.Len() <= 40)
+
+	// Budget smaller than a single element
+	buf.Reset()
+	truncated, err = encodeList(buf, list, 5)
+	assert.Nil(t, err)
+	assert.True(t, truncated)
+	assert.Equal(t, "[]\n", buf.String())
+}
+
+func TestWriteList(t *testing.T) {
+	u := &Uchiwa{Config: &config.Config{Uchiwa: config.GlobalConfig{MaxResponseBytes: 20}}}
+	list := []interface{}{"foo", "bar", "qux", "quux", "corge"}
+
+	r, _ := http.NewRequest("GET", "/clients", nil)
+	w := httptest.NewRecorder()
+	u.writeList(w, r, list)
+
+	assert.Equal(t, "true", w.Header().Get(truncatedHeader))
+	assert.Equal(t, "0", w.Header().Get(dataVersionHeader))
+
+	var result []interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &result)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{"foo", "bar", "qux"}, result)
+
+	// Budget disabled
+	u.Config.Uchiwa.MaxResponseBytes = 0
+	w = httptest.NewRecorder()
+	u.writeList(w, r, nil)
+
+	assert.Equal(t, "", w.Header().Get(truncatedHeader))
+	assert.Equal(t, "[]\n", w.Body.String())
+
+	// The streamed list is identical to the encoded one, compressed or not
+	expected := &bytes.Buffer{}
+	json.NewEncoder(expected).Encode(list)
+
+	w = httptest.NewRecorder()
+	u.writeList(w, r, list)
+	assert.Equal(t, expected.String(), w.Body.String())
+	assert.Equal(t, "0", w.Header().Get(dataVersionHeader))
+
+	r.Header.Set("Accept-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	u.writeList(w, r, list)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	gz, err := gzip.NewReader(w.Body)
+	assert.Nil(t, err)
+	body, err := ioutil.ReadAll(gz)
+	assert.Nil(t, err)
+	assert.Equal(t, expected.String(), string(body))
+}
+
+func TestWriteListAbort(t *testing.T) {
+	u := &Uchiwa{Config: &config.Config{}}
+
+	// An element that can't be encoded aborts the streamed response
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u.writeList(w, r, []interface{}{"foo", make(chan int)})
+	}))
+	defer server.Close()
+
+	// The connection is closed either before or while the body is read
+	res, err := http.Get(server.URL)
+	if err == nil {
+		defer res.Body.Close()
+		_, err = ioutil.ReadAll(res.Body)
+	}
+	assert.NotNil(t, err)
+
+	// While a 500 is sent if the list is encoded beforehand
+	u.Config.Uchiwa.MaxResponseBytes = 1024
+	r, _ := http.NewRequest("GET", "/clients", nil)
+	w := httptest.NewRecorder()
+	u.writeList(w, r, []interface{}{"foo", make(chan int)})
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestWriteListDigest(t *testing.T) {
+	u := &Uchiwa{Config: &config.Config{}}
+	list := []interface{}{map[string]interface{}{"name": "foo", "dc": "us-east-1"}}
+
+	// No digest unless requested
+	r, _ := http.NewRequest("GET", "/clients", nil)
+	w := httptest.NewRecorder()
+	u.writeList(w, r, list)
+	assert.Equal(t, "", w.Header().Get(digestHeader))
+
+	r, _ = http.NewRequest("GET", "/clients?digest=true", nil)
+	w = httptest.NewRecorder()
+	u.writeList(w, r, list)
+	digest := w.Header().Get(digestHeader)
+	assert.Equal(t, listDigest(w.Body.Bytes()), digest)
+
+	// The digest is stable, and computed on the uncompressed content
+	r.Header.Set("Accept-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	u.writeList(w, r, []interface{}{map[string]interface{}{"dc": "us-east-1", "name": "foo"}})
+	assert.Equal(t, digest, w.Header().Get(digestHeader))
+
+	w = httptest.NewRecorder()
+	u.writeList(w, r, []interface{}{map[string]interface{}{"dc": "us-east-1", "name": "bar"}})
+	assert.NotEqual(t, digest, w.Header().Get(digestHeader))
+
+	r, _ = http.NewRequest("GET", "/clients?digest=foo", nil)
+	w = httptest.NewRecorder()
+	u.writeList(w, r, list)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestGzipWriterPool(t *testing.T) {
+	u := &Uchiwa{Config: &config.Config{}}
+	list := []interface{}{"foo", "bar"}
+
+	// Pooled writers must be reset between responses
+	for i := 0; i < 3; i++ {
+		r, _ := http.NewRequest("GET", "/clients", nil)
+		r.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		u.writeList(w, r, list)
+
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+
+		gz, err := gzip.NewReader(w.Body)
+		assert.Nil(t, err)
+		body, err := ioutil.ReadAll(gz)
+		assert.Nil(t, err)
+		assert.Equal(t, "[\"foo\",\"bar\"]\n", string(body))
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	u := &Uchiwa{Config: &config.Config{}}
+	v := map[string]interface{}{"name": "foo"}
+
+	// The gzip response is identical to a gzip writer wrapping a JSON encoder
+	r, _ := http.NewRequest("GET", "/datacenters", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	assert.Nil(t, u.writeJSON(w, r, http.StatusMultipleChoices, v))
+	assert.Equal(t, http.StatusMultipleChoices, w.Code)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	expected := &bytes.Buffer{}
+	gz := gzip.NewWriter(expected)
+	json.NewEncoder(gz).Encode(v)
+	gz.Close()
+	assert.Equal(t, expected.Bytes(), w.Body.Bytes())
+
+	// deflate
+	r.Header.Set("Accept-Encoding", "deflate")
+	w = httptest.NewRecorder()
+	assert.Nil(t, u.writeJSON(w, r, http.StatusOK, v))
+	assert.Equal(t, "deflate", w.Header().Get("Content-Encoding"))
+	zr, err := zlib.NewReader(w.Body)
+	assert.Nil(t, err)
+	body, err := ioutil.ReadAll(zr)
+	assert.Nil(t, err)
+	assert.Equal(t, "{\"name\":\"foo\"}\n", string(body))
+
+	// Compression refused
+	r.Header.Set("Accept-Encoding", "gzip;q=0")
+	w = httptest.NewRecorder()
+	assert.Nil(t, u.writeJSON(w, r, http.StatusOK, v))
+	assert.Equal(t, "", w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "{\"name\":\"foo\"}\n", w.Body.String())
+
+	// Nothing is written but the error when the encoding fails
+	w = httptest.NewRecorder()
+	assert.NotNil(t, u.writeJSON(w, r, http.StatusOK, map[string]interface{}{"foo": make(chan int)}))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "", w.Header().Get("Content-Encoding"))
+}
+
+func TestWriteCacheable(t *testing.T) {
+	u := &Uchiwa{Config: &config.Config{}}
+	v := map[string]int{"critical": 1}
+
+	r, _ := http.NewRequest("GET", "/summary", nil)
+	w := httptest.NewRecorder()
+	u.writeCacheable(w, r, v)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "{\"critical\":1}\n", w.Body.String())
+	etag := w.Header().Get("ETag")
+	assert.NotEqual(t, "", etag)
+
+	// Matching ETag
+	r.Header.Set("If-None-Match", "\"foo\", "+etag)
+	w = httptest.NewRecorder()
+	u.writeCacheable(w, r, v)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, "", w.Body.String())
+
+	// Stale ETag
+	w = httptest.NewRecorder()
+	u.writeCacheable(w, r, map[string]int{"critical": 2})
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+}
+
+func TestWantsFormat(t *testing.T) {
+	r := httptest.NewRequest("GET", "/metrics", nil)
+	prometheus, err := wantsFormat(r, "prometheus", "text/plain")
+	assert.Nil(t, err)
+	assert.False(t, prometheus)
+
+	r.Header.Set("Accept", "text/plain;version=0.0.4;q=0.3,*/*;q=0.2")
+	prometheus, err = wantsFormat(r, "prometheus", "text/plain")
+	assert.Nil(t, err)
+	assert.True(t, prometheus)
+
+	r.Header.Set("Accept", "application/json, text/plain, */*")
+	prometheus, err = wantsFormat(r, "prometheus", "text/plain")
+	assert.Nil(t, err)
+	assert.False(t, prometheus)
+
+	// The format parameter takes precedence
+	r = httptest.NewRequest("GET", "/metrics?format=prometheus", nil)
+	prometheus, err = wantsFormat(r, "prometheus", "text/plain")
+	assert.Nil(t, err)
+	assert.True(t, prometheus)
+
+	r = httptest.NewRequest("GET", "/metrics?format=xml", nil)
+	_, err = wantsFormat(r, "prometheus", "text/plain")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,75 @@
+package uchiwa
+
+import (
+	"net/http"
+	"runtime"
+	"sync/atomic"
+
+	"github.com/sensu/uchiwa/uchiwa/logger"
+)
+
+// lockData locks the data mutex, counting the caller as waiting until it is
+// acquired
+func (u *Uchiwa) lockData() {
+	atomic.AddInt64(&u.lockWaiters, 1)
+	u.Mu.Lock()
+	atomic.AddInt64(&u.lockWaiters, -1)
+}
+
+// runtimeMemory holds a subset of the memory statistics of the runtime
+type runtimeMemory struct {
+	Alloc       uint64 `json:"alloc"`
+	HeapInuse   uint64 `json:"heap_inuse"`
+	HeapObjects uint64 `json:"heap_objects"`
+	NumGC       uint32 `json:"num_gc"`
+	Sys         uint64 `json:"sys"`
+	TotalAlloc  uint64 `json:"total_alloc"`
+}
+
+// runtimeStats holds the current load of the process
+type runtimeStats struct {
+	InFlight    int64         `json:"in_flight"`
+	Streams     int           `json:"streams"`
+	LockWaiters int64         `json:"lock_waiters"`
+	Goroutines  int           `json:"goroutines"`
+	Memory      runtimeMemory `json:"memory"`
+}
+
+// inFlightHandler counts the requests being processed and rejects the ones
+// beyond the MaxInFlightRequests limit with a 503. A limit below 1 means no
+// limit
+func (u *Uchiwa) inFlightHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := int64(u.Config.Uchiwa.MaxInFlightRequests)
+		if n := atomic.AddInt64(&u.inFlight, 1); limit > 0 && n > limit {
+			atomic.AddInt64(&u.inFlight, -1)
+			logger.Debugf("Rejecting the request from %s, too many concurrent requests", r.RemoteAddr)
+			writeJSONError(w, http.StatusServiceUnavailable, "Too many concurrent requests")
+			return
+		}
+		defer atomic.AddInt64(&u.inFlight, -1)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// buildRuntimeStats returns the current load of the process
+func (u *Uchiwa) buildRuntimeStats() runtimeStats {
+	var memory runtime.MemStats
+	runtime.ReadMemStats(&memory)
+
+	return runtimeStats{
+		InFlight:    atomic.LoadInt64(&u.inFlight),
+		Streams:     u.streams.open(),
+		LockWaiters: atomic.LoadInt64(&u.lockWaiters),
+		Goroutines:  runtime.NumGoroutine(),
+		Memory: runtimeMemory{
+			Alloc:       memory.Alloc,
+			HeapInuse:   memory.HeapInuse,
+			HeapObjects: memory.HeapObjects,
+			NumGC:       memory.NumGC,
+			Sys:         memory.Sys,
+			TotalAlloc:  memory.TotalAlloc,
+		},
+	}
+}
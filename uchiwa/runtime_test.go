@@ -0,0 +1,77 @@
+package uchiwa
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sensu/uchiwa/uchiwa/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockData(t *testing.T) {
+	u := &Uchiwa{Mu: &sync.Mutex{}}
+	u.lockData()
+
+	locked := make(chan struct{})
+	go func() {
+		u.lockData()
+		close(locked)
+		u.Mu.Unlock()
+	}()
+
+	for i := 0; i < 100 && atomic.LoadInt64(&u.lockWaiters) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, int64(1), atomic.LoadInt64(&u.lockWaiters))
+
+	u.Mu.Unlock()
+	<-locked
+	assert.Equal(t, int64(0), atomic.LoadInt64(&u.lockWaiters))
+}
+
+func TestBuildRuntimeStats(t *testing.T) {
+	u := &Uchiwa{Config: &config.Config{}, Mu: &sync.Mutex{}, streams: &streamLimiter{}}
+
+	var stats runtimeStats
+	handler := u.inFlightHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stats = u.buildRuntimeStats()
+	}))
+	r, _ := http.NewRequest("GET", "/events", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	assert.Equal(t, int64(1), stats.InFlight)
+	assert.True(t, stats.Goroutines > 0)
+	assert.True(t, stats.Memory.Sys > 0)
+	assert.Equal(t, int64(0), u.buildRuntimeStats().InFlight)
+}
+
+func TestInFlightHandlerLimit(t *testing.T) {
+	u := &Uchiwa{Config: &config.Config{}}
+	u.Config.Uchiwa.MaxInFlightRequests = 1
+
+	var inner *httptest.ResponseRecorder
+	handler := u.inFlightHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A second request while this one is processed
+		if inner == nil {
+			inner = httptest.NewRecorder()
+			u.inFlightHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(inner, r)
+		}
+	}))
+
+	r, _ := http.NewRequest("GET", "/events", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusServiceUnavailable, inner.Code)
+	assert.Equal(t, int64(0), atomic.LoadInt64(&u.inFlight))
+
+	// No limit
+	u.Config.Uchiwa.MaxInFlightRequests = 0
+	inner = nil
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	assert.Equal(t, http.StatusOK, inner.Code)
+}
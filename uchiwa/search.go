@@ -0,0 +1,43 @@
+package uchiwa
+
+import "strings"
+
+// searchLimit is the maximum number of results returned per resource type
+const searchLimit = 25
+
+// searchResults contains the results of a search, categorized by resource type
+type searchResults struct {
+	Aggregates []interface{} `json:"aggregates"`
+	Checks     []interface{} `json:"checks"`
+	Clients    []interface{} `json:"clients"`
+	Stashes    []interface{} `json:"stashes"`
+}
+
+// searchResources returns, up to the provided limit, the resources for which
+// the value of the provided attribute contains the term, ignoring case
+func searchResources(term, attribute string, resources []interface{}, limit int) []interface{} {
+	results := make([]interface{}, 0)
+	term = strings.ToLower(term)
+
+	for _, r := range resources {
+		if len(results) >= limit {
+			break
+		}
+
+		m, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		value, ok := m[attribute].(string)
+		if !ok {
+			continue
+		}
+
+		if strings.Contains(strings.ToLower(value), term) {
+			results = append(results, m)
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,33 @@
+package uchiwa
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchResources(t *testing.T) {
+	clients := []interface{}{
+		map[string]interface{}{"name": "web-01", "dc": "us-east-1"},
+		map[string]interface{}{"name": "WEB-02", "dc": "us-east-1"},
+		map[string]interface{}{"name": "db-01", "dc": "us-east-1"},
+		map[string]interface{}{"dc": "us-east-1"},
+		"foo",
+	}
+
+	results := searchResources("web", "name", clients, searchLimit)
+	assert.Equal(t, 2, len(results))
+
+	results = searchResources("web", "name", clients, 1)
+	assert.Equal(t, 1, len(results))
+
+	results = searchResources("qux", "name", clients, searchLimit)
+	assert.NotNil(t, results)
+	assert.Equal(t, 0, len(results))
+
+	stashes := []interface{}{
+		map[string]interface{}{"path": "silence/web-01", "dc": "us-east-1"},
+	}
+	results = searchResources("WEB", "path", stashes, searchLimit)
+	assert.Equal(t, 1, len(results))
+}
@@ -16,12 +16,12 @@ func (s *Sensu) delete(endpoint string) error {
 
 	var err error
 	for i := 0; i < len(apis); i++ {
-		logger.Infof("DELETE %s/%s", s.APIs[i].URL, endpoint)
+		logger.Infof("DELETE %s/%s", apis[i].URL, endpoint)
 		err = apis[i].delete(endpoint)
 		if err == nil {
 			return err
 		}
-		logger.Warningf("DELETE %s/%s returned: %v", s.APIs[i].URL, endpoint, err)
+		logger.Warningf("DELETE %s/%s returned: %v", apis[i].URL, endpoint, err)
 	}
 
 	return err
@@ -34,12 +34,12 @@ func (s *Sensu) getBytes(endpoint string) ([]byte, *http.Response, error) {
 	apis := shuffle(s.APIs)
 
 	for i := 0; i < len(apis); i++ {
-		logger.Debugf("GET %s/%s", s.APIs[i].URL, endpoint)
+		logger.Debugf("GET %s/%s", apis[i].URL, endpoint)
 		bytes, res, err = apis[i].getBytes(endpoint)
 		if err == nil {
 			return bytes, res, err
 		}
-		logger.Warningf("GET %s/%s returned: %v", s.APIs[i].URL, endpoint, err)
+		logger.Warningf("GET %s/%s returned: %v", apis[i].URL, endpoint, err)
 	}
 
 	return nil, nil, err
@@ -51,12 +51,12 @@ func (s *Sensu) getSlice(endpoint string, limit int) ([]interface{}, error) {
 	apis := shuffle(s.APIs)
 
 	for i := 0; i < len(apis); i++ {
-		logger.Debugf("GET %s/%s", s.APIs[i].URL, endpoint)
+		logger.Debugf("GET %s/%s", apis[i].URL, endpoint)
 		slice, err = apis[i].getSlice(endpoint, limit)
 		if err == nil {
 			return slice, err
 		}
-		logger.Warningf("GET %s/%s returned: %v", s.APIs[i].URL, endpoint, err)
+		logger.Warningf("GET %s/%s returned: %v", apis[i].URL, endpoint, err)
 	}
 
 	return nil, err
@@ -68,12 +68,12 @@ func (s *Sensu) getMap(endpoint string) (map[string]interface{}, error) {
 	apis := shuffle(s.APIs)
 
 	for i := 0; i < len(apis); i++ {
-		logger.Debugf("GET %s/%s", s.APIs[i].URL, endpoint)
+		logger.Debugf("GET %s/%s", apis[i].URL, endpoint)
 		m, err = apis[i].getMap(endpoint)
 		if err == nil {
 			return m, err
 		}
-		logger.Warningf("GET %s/%s returned: %v", s.APIs[i].URL, endpoint, err)
+		logger.Warningf("GET %s/%s returned: %v", apis[i].URL, endpoint, err)
 	}
 
 	return nil, err
@@ -85,23 +85,29 @@ func (s *Sensu) postPayload(endpoint string, payload string) (map[string]interfa
 	apis := shuffle(s.APIs)
 
 	for i := 0; i < len(apis); i++ {
-		logger.Debugf("POST %s/%s", s.APIs[i].URL, endpoint)
+		logger.Debugf("POST %s/%s", apis[i].URL, endpoint)
 		m, err = apis[i].postPayload(endpoint, payload)
 		if err == nil {
 			return m, err
 		}
-		logger.Warningf("POST %s/%s returned: %v", s.APIs[i].URL, endpoint, err)
+		logger.Warningf("POST %s/%s returned: %v", apis[i].URL, endpoint, err)
 	}
 
 	return nil, err
 }
 
-// shuffle the provided []API
-func shuffle(apis []API) []API {
-	rand.Seed(time.Now().UnixNano())
+// shuffle returns pointers to the provided APIs in a random order. The
+// provided slice is left untouched since it is shared by concurrent requests
+func shuffle(apis []API) []*API {
+	shuffled := make([]*API, len(apis))
 	for i := range apis {
+		shuffled[i] = &apis[i]
+	}
+
+	rand.Seed(time.Now().UnixNano())
+	for i := range shuffled {
 		j := rand.Intn(i + 1)
-		apis[i], apis[j] = apis[j], apis[i]
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
 	}
-	return apis
+	return shuffled
 }
@@ -134,6 +134,8 @@ func (api *API) delete(endpoint string) error {
 		req.SetBasicAuth(api.User, api.Pass)
 	}
 
+	api.setHeaders(req)
+
 	res, err := api.Client.Do(req)
 	if err != nil {
 		return err
@@ -19,6 +19,8 @@ func (api *API) doRequest(req *http.Request) ([]byte, *http.Response, error) {
 		req.SetBasicAuth(api.User, api.Pass)
 	}
 
+	api.setHeaders(req)
+
 	req.Close = api.CloseRequest
 
 	if api.Tracing {
@@ -99,3 +101,11 @@ func (api *API) doRequest(req *http.Request) ([]byte, *http.Response, error) {
 	return body, res, nil
 
 }
+
+// setHeaders adds the custom headers of the API to the request. Their values
+// might be secrets, so they must never be logged
+func (api *API) setHeaders(req *http.Request) {
+	for name, value := range api.Headers {
+		req.Header.Set(name, value)
+	}
+}
@@ -6,3 +6,8 @@ import "fmt"
 func (s *Sensu) DeleteCheckResult(check, client string) error {
 	return s.delete(fmt.Sprintf("results/%s/%s", client, check))
 }
+
+// GetResults returns a slice of the latest check results of every client
+func (s *Sensu) GetResults() ([]interface{}, error) {
+	return s.getSlice("results", NoLimit)
+}
@@ -18,14 +18,20 @@ const DefaultLimit int = 1000
 
 // Sensu struct contains the name and all the APIs for a particular datacenter
 type Sensu struct {
-	Name string
-	APIs []API
+	Name   string
+	Alias  string
+	Schema string
+	APIs   []API
+	// Interval is the polling interval of the datacenter, in seconds, which
+	// overrides the global refresh interval when positive
+	Interval int
 }
 
 // API struct contains the details of a specific Sensu API
 type API struct {
 	CloseRequest      bool
 	DisableKeepAlives bool
+	Headers           map[string]string
 	Insecure          bool
 	Pass              string
 	Path              string
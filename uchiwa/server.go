@@ -1,13 +1,16 @@
 package uchiwa
 
 import (
-	"compress/gzip"
+	"context"
 	"crypto/tls"
-	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/dgrijalva/jwt-go"
 	"github.com/sensu/uchiwa/uchiwa/audit"
 	"github.com/sensu/uchiwa/uchiwa/authentication"
 	"github.com/sensu/uchiwa/uchiwa/authorization"
@@ -23,6 +26,38 @@ var Authorization authorization.Authorization
 // Filters contains the available filters for the Sensu data
 var Filters filters.Filters
 
+// activityHandler serves the /activity endpoint
+func (u *Uchiwa) activityHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+
+	// The feed is drawn from the audit entries retained in memory
+	if u.Config.Uchiwa.Audit.Retain <= 0 {
+		http.Error(w, "The activity feed is disabled", http.StatusNotFound)
+		return
+	}
+
+	var since int64
+	if s := r.URL.Query().Get("since"); s != "" {
+		var err error
+		since, err = strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			http.Error(w, "The since parameter must be a Unix timestamp", http.StatusBadRequest)
+			return
+		}
+	}
+
+	token := authentication.GetJWTFromContext(r)
+
+	feed := buildActivityFeed(audit.Entries(), time.Unix(since, 0), func(dc string) bool {
+		return !Filters.GetRequest(dc, token)
+	})
+
+	u.writeJSON(w, r, http.StatusOK, feed)
+}
+
 // aggregateHandler serves the /aggregates/:name[...] endpoint
 func (u *Uchiwa) aggregateHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet && r.Method != http.MethodHead && r.Method != http.MethodDelete {
@@ -40,7 +75,10 @@ func (u *Uchiwa) aggregateHandler(w http.ResponseWriter, r *http.Request) {
 	token := authentication.GetJWTFromContext(r)
 
 	// Get the datacenter name, passed as a query string
-	dc := r.URL.Query().Get("dc")
+	dc, ok := u.datacenterParam(w, r)
+	if !ok {
+		return
+	}
 
 	if dc == "" {
 		aggregates, err := u.findAggregate(name)
@@ -49,38 +87,14 @@ func (u *Uchiwa) aggregateHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		u.Mu.Lock()
+		u.lockData()
 		visibleAggregates := Filters.Aggregates(&aggregates, token)
 		u.Mu.Unlock()
 
 		if len(visibleAggregates) > 1 {
-			// Create header
-			w.Header().Add("Accept-Charset", "utf-8")
-			w.Header().Add("Content-Type", "application/json")
-
-			// If GZIP compression is not supported by the client
-			if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
-				w.WriteHeader(http.StatusMultipleChoices)
-
-				encoder := json.NewEncoder(w)
-				if err = encoder.Encode(visibleAggregates); err != nil {
-					http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
-					return
-				}
-
-				return
-			}
-
-			w.Header().Add("Content-Encoding", "gzip")
-			w.WriteHeader(http.StatusMultipleChoices)
-
-			gz := gzip.NewWriter(w)
-			defer gz.Close()
-			if err = json.NewEncoder(gz).Encode(visibleAggregates); err != nil {
-				http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
-				return
-			}
+			visibleAggregates = prioritizeDatacenters(visibleAggregates, u.Config.Uchiwa.DatacenterPriority)
 
+			u.writeJSON(w, r, http.StatusMultipleChoices, visibleAggregates)
 			return
 		}
 
@@ -105,11 +119,36 @@ func (u *Uchiwa) aggregateHandler(w http.ResponseWriter, r *http.Request) {
 	// Are we responding to a /aggregates/:name request?
 	if len(resources) == 3 {
 		if r.Method == http.MethodDelete {
+			// The deleted aggregate is returned if the return parameter is true
+			returnDeleted := false
+			if ret := r.URL.Query().Get("return"); ret != "" {
+				var err error
+				returnDeleted, err = strconv.ParseBool(ret)
+				if err != nil {
+					http.Error(w, "Invalid return parameter", http.StatusBadRequest)
+					return
+				}
+			}
+
+			var aggregate *map[string]interface{}
+			if returnDeleted {
+				var err error
+				aggregate, err = u.GetAggregate(name, dc)
+				if err != nil {
+					http.Error(w, fmt.Sprint(err), http.StatusNotFound)
+					return
+				}
+			}
+
 			err := u.DeleteAggregate(name, dc)
 			if err != nil {
 				http.Error(w, fmt.Sprint(err), 500)
 				return
 			}
+
+			if returnDeleted {
+				u.writeJSON(w, r, http.StatusOK, aggregate)
+			}
 			return
 		}
 
@@ -119,11 +158,7 @@ func (u *Uchiwa) aggregateHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		encoder := json.NewEncoder(w)
-		if err := encoder.Encode(aggregate); err != nil {
-			http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
-			return
-		}
+		u.writeJSON(w, r, http.StatusOK, aggregate)
 		return
 	}
 
@@ -131,7 +166,8 @@ func (u *Uchiwa) aggregateHandler(w http.ResponseWriter, r *http.Request) {
 	var err error
 
 	if len(resources) == 4 {
-		// We are responding to a /aggregates/:name/[checks|clients] request
+		// We are responding to a
+		// /aggregates/:name/[checks|clients|offenders|subscriptions] request
 
 		if resources[3] == "checks" {
 			data, err = u.GetAggregateChecks(name, dc)
@@ -145,6 +181,39 @@ func (u *Uchiwa) aggregateHandler(w http.ResponseWriter, r *http.Request) {
 				http.Error(w, fmt.Sprint(err), 500)
 				return
 			}
+		} else if resources[3] == "offenders" {
+			results := make(map[string][]interface{}, len(aggregateSeverities))
+			for _, severity := range aggregateSeverities {
+				data, err := u.GetAggregateResults(name, severity.name, dc)
+				if err != nil {
+					http.Error(w, fmt.Sprint(err), 500)
+					return
+				}
+
+				// Filter the results like /aggregates/:name/results/:severity
+				results[severity.name] = Filters.AggregateResults(data, token)
+			}
+
+			u.lockData()
+			clients := Filters.Clients(&u.Data.Clients, token)
+			u.Mu.Unlock()
+
+			u.writeJSON(w, r, http.StatusOK, aggregateOffenders(results, clients, dc))
+			return
+		} else if resources[3] == "subscriptions" {
+			members, err := u.GetAggregateChecks(name, dc)
+			if err != nil {
+				http.Error(w, fmt.Sprint(err), 500)
+				return
+			}
+
+			u.lockData()
+			checks := Filters.Checks(&u.Data.Checks, token)
+			clients := Filters.Clients(&u.Data.Clients, token)
+			u.Mu.Unlock()
+
+			u.writeJSON(w, r, http.StatusOK, aggregateSubscriptions(*members, checks, clients, dc))
+			return
 		} else {
 			http.Error(w, fmt.Sprint(err), http.StatusNotFound)
 			return
@@ -152,22 +221,33 @@ func (u *Uchiwa) aggregateHandler(w http.ResponseWriter, r *http.Request) {
 
 	} else if len(resources) == 5 {
 		// We are responding to a /aggregates/:name/results/:severity request
+		groupBy := r.URL.Query().Get("groupBy")
+		if groupBy != "" && groupBy != "check" {
+			http.Error(w, "Invalid groupBy parameter", http.StatusBadRequest)
+			return
+		}
+
 		severity := resources[4]
 		data, err = u.GetAggregateResults(name, severity, dc)
 		if err != nil {
 			http.Error(w, fmt.Sprint(err), 500)
 			return
 		}
+
+		// Mask the redacted attributes of the results
+		results := Filters.AggregateResults(data, token)
+		data = &results
+
+		if groupBy == "check" {
+			u.writeJSON(w, r, http.StatusOK, groupAggregateResults(*data))
+			return
+		}
 	} else {
 		http.Error(w, "", http.StatusBadRequest)
 		return
 	}
 
-	encoder := json.NewEncoder(w)
-	if err := encoder.Encode(data); err != nil {
-		http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
-		return
-	}
+	u.writeJSON(w, r, http.StatusOK, data)
 
 	return
 }
@@ -179,43 +259,161 @@ func (u *Uchiwa) aggregatesHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	page, err := parseListPage(r.URL.Query(), sortableFields)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	token := authentication.GetJWTFromContext(r)
 
-	u.Mu.Lock()
+	u.lockData()
 	aggregates := Filters.Aggregates(&u.Data.Aggregates, token)
 	u.Mu.Unlock()
 
-	if len(aggregates) == 0 {
-		aggregates = make([]interface{}, 0)
-	}
+	u.writeList(w, r, paginateList(w, r, aggregates, page))
+
+	return
+}
 
-	// Create header
-	w.Header().Add("Accept-Charset", "utf-8")
-	w.Header().Add("Content-Type", "application/json")
+// auditActivityHandler serves the /audit/activity endpoint
+func (u *Uchiwa) auditActivityHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
 
-	// If GZIP compression is not supported by the client
-	if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
-		encoder := json.NewEncoder(w)
-		if err := encoder.Encode(aggregates); err != nil {
-			http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
+	var since int64
+	if s := r.URL.Query().Get("since"); s != "" {
+		var err error
+		since, err = strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			http.Error(w, "The since parameter must be a Unix timestamp", http.StatusBadRequest)
 			return
 		}
+	}
+
+	activities := buildAuditActivity(audit.Entries(), time.Unix(since, 0))
+
+	u.writeJSON(w, r, http.StatusOK, activities)
+}
+
+// auditResourceHandler serves the /audit/resource endpoint
+func (u *Uchiwa) auditResourceHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "", http.StatusBadRequest)
 		return
 	}
 
-	w.Header().Set("Content-Encoding", "gzip")
+	dc, ok := u.datacenterParam(w, r)
+	if !ok {
+		return
+	}
 
-	gz := gzip.NewWriter(w)
-	defer gz.Close()
-	if err := json.NewEncoder(gz).Encode(aggregates); err != nil {
-		http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
+	resource := structs.AuditResource{
+		Type: r.URL.Query().Get("type"),
+		Name: r.URL.Query().Get("name"),
+		Dc:   dc,
+	}
+	if resource.Type == "" || resource.Name == "" {
+		http.Error(w, "The type and name parameters are required", http.StatusBadRequest)
 		return
 	}
 
-	return
+	// The entries are always sorted from the most recent
+	page, err := parseListPage(r.URL.Query(), nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	trail := resourceAuditTrail(audit.Entries(), resource)
+
+	u.writeList(w, r, paginateList(w, r, trail, page))
+}
+
+// backupHandler serves the /backup/(silences|stashes) endpoint, restricted to
+// the administrators. Every restored entry is added to the audit log
+func (u *Uchiwa) backupHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead && r.Method != http.MethodPost {
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+
+	resources := strings.Split(r.URL.Path, "/")
+	if len(resources) != 3 || (resources[2] != "silences" && resources[2] != "stashes") {
+		http.Error(w, "", http.StatusNotFound)
+		return
+	}
+
+	token := authentication.GetJWTFromContext(r)
+
+	// GET on /backup/:resource
+	if r.Method != http.MethodPost {
+		u.lockData()
+		entries := u.Data.Stashes
+		if resources[2] == "silences" {
+			entries = u.Data.Silenced
+		}
+		u.Mu.Unlock()
+
+		if entries == nil {
+			entries = make([]interface{}, 0)
+		}
+
+		u.writeJSON(w, r, http.StatusOK, entries)
+		return
+	}
+
+	// POST on /backup/:resource
+	var report backupReport
+	if resources[2] == "silences" {
+		var entries []silence
+		if !u.decodeJSONBody(w, r, &entries) {
+			return
+		}
+
+		u.lockData()
+		existing := u.Data.Silenced
+		u.Mu.Unlock()
+
+		report = restoreSilences(entries, existing, func(data silence) error {
+			if err := u.PostSilence(data); err != nil {
+				return err
+			}
+
+			auditAction(r, token, "restore", silenceURL(data), silenceResources(data)...)
+			return nil
+		})
+	} else {
+		var entries []stash
+		if !u.decodeJSONBody(w, r, &entries) {
+			return
+		}
+
+		u.lockData()
+		existing := u.Data.Stashes
+		u.Mu.Unlock()
+
+		report = restoreStashes(entries, existing, func(data stash) error {
+			if err := u.PostStash(data); err != nil {
+				return err
+			}
+
+			auditAction(r, token, "restore", fmt.Sprintf("/stashes/%s?dc=%s", data.Path, data.Dc), structs.AuditResource{Type: "stash", Name: data.Path, Dc: data.Dc})
+			return nil
+		})
+	}
+
+	status := http.StatusCreated
+	if report.failed() {
+		status = http.StatusMultiStatus
+	}
+
+	u.writeJSON(w, r, status, report)
 }
 
-// checkHandler serves the /checks/ endpoint
+// checkHandler serves the /checks/:check(/stats) endpoint
 func (u *Uchiwa) checkHandler(w http.ResponseWriter, r *http.Request) {
 	// We only support DELETE & GET requests
 	if r.Method != http.MethodGet && r.Method != http.MethodHead {
@@ -234,7 +432,10 @@ func (u *Uchiwa) checkHandler(w http.ResponseWriter, r *http.Request) {
 	name := resources[2]
 
 	// Get the datacenter name, passed as a query string
-	dc := r.URL.Query().Get("dc")
+	dc, ok := u.datacenterParam(w, r)
+	if !ok {
+		return
+	}
 
 	if dc == "" {
 		checks, err := u.findCheck(name)
@@ -243,38 +444,14 @@ func (u *Uchiwa) checkHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		u.Mu.Lock()
+		u.lockData()
 		visibleChecks := Filters.Checks(&checks, token)
 		u.Mu.Unlock()
 
 		if len(visibleChecks) > 1 {
-			// Create header
-			w.Header().Add("Accept-Charset", "utf-8")
-			w.Header().Add("Content-Type", "application/json")
-
-			// If GZIP compression is not supported by the client
-			if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
-				w.WriteHeader(http.StatusMultipleChoices)
-
-				encoder := json.NewEncoder(w)
-				if err = encoder.Encode(visibleChecks); err != nil {
-					http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
-					return
-				}
-
-				return
-			}
-
-			w.Header().Add("Content-Encoding", "gzip")
-			w.WriteHeader(http.StatusMultipleChoices)
-
-			gz := gzip.NewWriter(w)
-			defer gz.Close()
-			if err = json.NewEncoder(gz).Encode(visibleChecks); err != nil {
-				http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
-				return
-			}
+			visibleChecks = prioritizeDatacenters(visibleChecks, u.Config.Uchiwa.DatacenterPriority)
 
+			u.writeJSON(w, r, http.StatusMultipleChoices, visibleChecks)
 			return
 		}
 
@@ -297,17 +474,60 @@ func (u *Uchiwa) checkHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// GET on /checks/:check/stats
+	if len(resources) == 4 && resources[3] == "stats" {
+		if u.Config.Uchiwa.CheckStats.Samples <= 0 {
+			http.Error(w, "The check statistics are disabled", http.StatusNotFound)
+			return
+		}
+
+		u.lockData()
+		samples := u.Data.CheckSamples[fmt.Sprintf("%s/%s", dc, name)]
+		u.Mu.Unlock()
+
+		stats := buildCheckStats(samples, u.Config.Uchiwa.CheckStats.Window, time.Now())
+		stats.Check = name
+		stats.Dc = dc
+
+		u.writeJSON(w, r, http.StatusOK, stats)
+
+		return
+	}
+
+	// GET on /checks/:check/results
+	if len(resources) == 4 && resources[3] == "results" {
+		var statuses map[string]bool
+		if s := r.URL.Query().Get("status"); s != "" {
+			var err error
+			statuses, err = parseCheckStatuses(s)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Invalid status parameter: %v", err), http.StatusBadRequest)
+				return
+			}
+		}
+
+		u.lockData()
+		checks := Filters.Checks(&u.Data.Checks, token)
+		clients := Filters.Clients(&u.Data.Clients, token)
+		events := Filters.Events(&u.Data.Events, token)
+		u.Mu.Unlock()
+
+		results := buildCheckResults(name, dc, checks, clients, events, statuses)
+
+		u.writeJSON(w, r, http.StatusOK, results)
+		return
+	}
+
 	data, err := u.GetCheck(dc, name)
 	if err != nil {
 		http.Error(w, fmt.Sprint(err), http.StatusNotFound)
 		return
 	}
 
-	encoder := json.NewEncoder(w)
-	if err := encoder.Encode(data); err != nil {
-		http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
-		return
-	}
+	// Mask the redacted attributes of the check
+	check := Filters.Checks(&[]interface{}{data}, token)[0]
+
+	u.writeJSON(w, r, http.StatusOK, check)
 
 	return
 }
@@ -319,42 +539,56 @@ func (u *Uchiwa) checksHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	page, err := parseListPage(r.URL.Query(), sortableFields)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	token := authentication.GetJWTFromContext(r)
 
-	u.Mu.Lock()
+	u.lockData()
 	checks := Filters.Checks(&u.Data.Checks, token)
 	u.Mu.Unlock()
 
-	if len(checks) == 0 {
-		checks = make([]interface{}, 0)
-	}
+	u.writeList(w, r, paginateList(w, r, checks, page))
+	return
+}
 
-	// Create header
-	w.Header().Add("Accept-Charset", "utf-8")
-	w.Header().Add("Content-Type", "application/json")
+// checksChangedHandler serves the /checks/changed endpoint
+func (u *Uchiwa) checksChangedHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
 
-	// If GZIP compression is not supported by the client
-	if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
-		encoder := json.NewEncoder(w)
-		if err := encoder.Encode(checks); err != nil {
-			http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
+	var since int64
+	if s := r.URL.Query().Get("since"); s != "" {
+		var err error
+		since, err = strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			http.Error(w, "The since parameter must be a Unix timestamp", http.StatusBadRequest)
 			return
 		}
-		return
 	}
 
-	w.Header().Set("Content-Encoding", "gzip")
+	token := authentication.GetJWTFromContext(r)
+
+	u.lockData()
+	changes := u.checks.since(time.Unix(since, 0))
+	u.Mu.Unlock()
 
-	gz := gzip.NewWriter(w)
-	defer gz.Close()
-	if err := json.NewEncoder(gz).Encode(checks); err != nil {
-		http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
-		return
+	visible := []checkChange{}
+	for _, change := range changes {
+		if !Filters.GetRequest(change.Dc, token) {
+			visible = append(visible, change)
+		}
 	}
-	return
+
+	u.writeJSON(w, r, http.StatusOK, visible)
 }
 
-// clientHandler serves the /clients/:client(/history) endpoint
+// clientHandler serves the /clients/:client(/detail|/events|/history|/keepalive) endpoint
 func (u *Uchiwa) clientHandler(w http.ResponseWriter, r *http.Request) {
 	// We only support DELETE & GET requests
 	if r.Method != http.MethodDelete && r.Method != http.MethodGet && r.Method != http.MethodHead {
@@ -373,7 +607,10 @@ func (u *Uchiwa) clientHandler(w http.ResponseWriter, r *http.Request) {
 	name := resources[2]
 
 	// Get the datacenter name, passed as a query string
-	dc := r.URL.Query().Get("dc")
+	dc, ok := u.datacenterParam(w, r)
+	if !ok {
+		return
+	}
 
 	if dc == "" {
 		clients, err := u.findClient(name)
@@ -382,38 +619,14 @@ func (u *Uchiwa) clientHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		u.Mu.Lock()
+		u.lockData()
 		visibleClients := Filters.Clients(&clients, token)
 		u.Mu.Unlock()
 
 		if len(visibleClients) > 1 {
-			// Create header
-			w.Header().Add("Accept-Charset", "utf-8")
-			w.Header().Add("Content-Type", "application/json")
-
-			// If GZIP compression is not supported by the client
-			if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
-				w.WriteHeader(http.StatusMultipleChoices)
-
-				encoder := json.NewEncoder(w)
-				if err = encoder.Encode(visibleClients); err != nil {
-					http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
-					return
-				}
-
-				return
-			}
-
-			w.Header().Add("Content-Encoding", "gzip")
-			w.WriteHeader(http.StatusMultipleChoices)
-
-			gz := gzip.NewWriter(w)
-			defer gz.Close()
-			if err = json.NewEncoder(gz).Encode(visibleClients); err != nil {
-				http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
-				return
-			}
+			visibleClients = prioritizeDatacenters(visibleClients, u.Config.Uchiwa.DatacenterPriority)
 
+			u.writeJSON(w, r, http.StatusMultipleChoices, visibleClients)
 			return
 		}
 
@@ -436,48 +649,185 @@ func (u *Uchiwa) clientHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// DELETE on /clients/:client/events
+	if r.Method == http.MethodDelete && len(resources) == 4 && resources[3] == "events" {
+		u.lockData()
+		events := Filters.Events(&u.Data.Events, token)
+		u.Mu.Unlock()
+
+		status := http.StatusAccepted
+		results := []eventResolution{}
+
+		for _, check := range clientEventsChecks(name, dc, events) {
+			result := eventResolution{Check: check, Client: name, Dc: dc}
+
+			err := u.ResolveEvent(check, name, dc)
+			if err != nil {
+				result.Error = err.Error()
+				status = http.StatusMultiStatus
+			} else {
+				// Add the resolution to the audit log
+				auditAction(r, token, "resolve", fmt.Sprintf("/events/%s/%s?dc=%s", name, check, dc), eventResources(name, check, dc)...)
+			}
+
+			results = append(results, result)
+		}
+
+		u.writeJSON(w, r, status, results)
+		return
+	}
+
 	// DELETE on /clients/:client
 	if r.Method == http.MethodDelete {
+		// The deleted client is returned if the return parameter is true
+		returnDeleted := false
+		if ret := r.URL.Query().Get("return"); ret != "" {
+			var err error
+			returnDeleted, err = strconv.ParseBool(ret)
+			if err != nil {
+				http.Error(w, "Invalid return parameter", http.StatusBadRequest)
+				return
+			}
+		}
+
+		var client map[string]interface{}
+		if returnDeleted {
+			var err error
+			client, err = u.GetClient(dc, name)
+			if err != nil {
+				http.Error(w, fmt.Sprint(err), http.StatusNotFound)
+				return
+			}
+		}
+
 		err := u.DeleteClient(dc, name)
 		if err != nil {
 			http.Error(w, fmt.Sprint(err), http.StatusInternalServerError)
 			return
 		}
 
-		w.WriteHeader(http.StatusAccepted)
+		// Add the deletion to the audit log
+		auditAction(r, token, "deleteclient", fmt.Sprintf("/clients/%s?dc=%s", name, dc), structs.AuditResource{Type: "client", Name: name, Dc: dc})
+
+		if !returnDeleted {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+
+		u.writeJSON(w, r, http.StatusAccepted, client)
 		return
 	}
 
-	// GET on /clients/:client/history
-	if len(resources) == 4 {
-		data, err := u.GetClientHistory(dc, name)
+	// GET on /clients/:client/history/diff
+	if len(resources) == 5 && resources[3] == "history" && resources[4] == "diff" {
+		from, err := strconv.ParseInt(r.URL.Query().Get("from"), 10, 64)
 		if err != nil {
-			http.Error(w, fmt.Sprint(err), http.StatusNotFound)
+			http.Error(w, "The from parameter must be a Unix timestamp", http.StatusBadRequest)
+			return
+		}
+		to, err := strconv.ParseInt(r.URL.Query().Get("to"), 10, 64)
+		if err != nil {
+			http.Error(w, "The to parameter must be a Unix timestamp", http.StatusBadRequest)
 			return
 		}
+		if from > to {
+			http.Error(w, "The from parameter must not be after the to parameter", http.StatusBadRequest)
+			return
+		}
+
+		u.lockData()
+		client := findClientInDc(name, dc, Filters.Clients(&u.Data.Clients, token))
+		before, foundBefore := u.clientHistory.at(dc, name, from)
+		after, foundAfter := u.clientHistory.at(dc, name, to)
+		u.Mu.Unlock()
 
-		encoder := json.NewEncoder(w)
-		if err := encoder.Encode(data); err != nil {
-			http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
+		if client == nil {
+			http.Error(w, fmt.Sprintf("Could not find the client '%s'", name), http.StatusNotFound)
 			return
 		}
+		if !foundBefore || !foundAfter {
+			http.Error(w, fmt.Sprintf("No history of the client '%s' is retained for the requested period", name), http.StatusNotFound)
+			return
+		}
+
+		diff := clientHistoryDiff{Client: name, Dc: dc, From: from, To: to, Changes: diffClientSnapshots(before, after)}
+
+		u.writeJSON(w, r, http.StatusOK, diff)
 
 		return
 	}
 
-	// GET on /clients/:client
-	data, err := u.GetClient(dc, name)
-	if err != nil {
-		http.Error(w, fmt.Sprint(err), http.StatusNotFound)
+	// GET on /clients/:client/detail
+	if len(resources) == 4 && resources[3] == "detail" {
+		// The history, used to find the coverage gaps, is retrieved from the
+		// API beforehand so the lock is only acquired once
+		var history []interface{}
+		if api, err := getAPI(u.Datacenters, dc); err == nil {
+			history, err = api.GetClientHistory(name)
+			if err != nil {
+				logger.Warning(err)
+			}
+		}
+
+		u.lockData()
+		client := findClientInDc(name, dc, Filters.Clients(&u.Data.Clients, token))
+		var detail clientDetail
+		if client != nil {
+			checks := Filters.Checks(&u.Data.Checks, token)
+			events := Filters.Events(&u.Data.Events, token)
+			silenced := Filters.Silenced(&u.Data.Silenced, token)
+			detail = buildClientDetail(client, checks, events, silenced, history)
+		}
+		u.Mu.Unlock()
+
+		if client == nil {
+			http.Error(w, fmt.Sprintf("Could not find the client '%s'", name), http.StatusNotFound)
+			return
+		}
+
+		u.writeJSON(w, r, http.StatusOK, detail)
+
+		return
+	}
+
+	// GET on /clients/:client/keepalive
+	if len(resources) == 4 && resources[3] == "keepalive" {
+		client, err := u.GetClient(dc, name)
+		if err != nil {
+			http.Error(w, fmt.Sprint(err), http.StatusNotFound)
+			return
+		}
+
+		u.writeJSON(w, r, http.StatusOK, buildClientKeepalive(client, dc, time.Now()))
+
+		return
+	}
+
+	// GET on /clients/:client/history
+	if len(resources) == 4 {
+		history, err := u.GetClientHistory(dc, name)
+		if err != nil {
+			http.Error(w, fmt.Sprint(err), http.StatusNotFound)
+			return
+		}
+
+		// Mask the redacted attributes of the last results
+		data := Filters.History(&history, token)
+
+		u.writeJSON(w, r, http.StatusOK, data)
+
 		return
 	}
 
-	encoder := json.NewEncoder(w)
-	if err := encoder.Encode(data); err != nil {
-		http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
+	// GET on /clients/:client
+	data, err := u.GetClient(dc, name)
+	if err != nil {
+		http.Error(w, fmt.Sprint(err), http.StatusNotFound)
 		return
 	}
 
+	u.writeJSON(w, r, http.StatusOK, data)
+
 	return
 }
 
@@ -485,45 +835,45 @@ func (u *Uchiwa) clientHandler(w http.ResponseWriter, r *http.Request) {
 func (u *Uchiwa) clientsHandler(w http.ResponseWriter, r *http.Request) {
 	// Support GET & HEAD requests
 	if r.Method == http.MethodGet || r.Method == http.MethodHead {
-		token := authentication.GetJWTFromContext(r)
-
-		u.Mu.Lock()
-		clients := Filters.Clients(&u.Data.Clients, token)
-		u.Mu.Unlock()
-
-		if len(clients) == 0 {
-			clients = make([]interface{}, 0)
+		query := r.URL.Query()
+		if !u.datacenterParams(w, query) {
+			return
 		}
 
-		// Create header
-		w.Header().Add("Accept-Charset", "utf-8")
-		w.Header().Add("Content-Type", "application/json")
-
-		// If GZIP compression is not supported by the client
-		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
-			encoder := json.NewEncoder(w)
-			if err := encoder.Encode(clients); err != nil {
-				http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
-				return
-			}
+		criteria, err := parseClientFilters(query, time.Now())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		page, err := parseListPage(r.URL.Query(), sortableFields)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		csv, err := wantsFormat(r, "csv", "text/csv")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
-		w.Header().Set("Content-Encoding", "gzip")
-		gz := gzip.NewWriter(w)
-		defer gz.Close()
-		if err := json.NewEncoder(gz).Encode(clients); err != nil {
-			http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
+		token := authentication.GetJWTFromContext(r)
+
+		u.lockData()
+		clients := filterElements(Filters.Clients(&u.Data.Clients, token), criteria)
+		u.Mu.Unlock()
+
+		clients = paginateList(w, r, clients, page)
+		if csv {
+			u.writeCSV(w, r, clients, clientColumns, "clients.csv")
 			return
 		}
+
+		u.writeList(w, r, clients)
 		return
 	} else if r.Method == http.MethodPost {
 		// Support POST requests
-		decoder := json.NewDecoder(r.Body)
 		var payload interface{}
-		err := decoder.Decode(&payload)
-		if err != nil {
-			http.Error(w, "Could not decode body", http.StatusInternalServerError)
+		if !u.decodeJSONBody(w, r, &payload) {
 			return
 		}
 
@@ -536,7 +886,7 @@ func (u *Uchiwa) clientsHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		err = u.UpdateClient(payload)
+		err := u.UpdateClient(payload)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
@@ -560,20 +910,16 @@ func (u *Uchiwa) configHandler(w http.ResponseWriter, r *http.Request) {
 	resources := strings.Split(r.URL.Path, "/")
 
 	if len(resources) == 2 {
-		encoder := json.NewEncoder(w)
-		if err := encoder.Encode(u.PublicConfig); err != nil {
-			http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
-			return
-		}
+		u.writeJSON(w, r, http.StatusOK, u.PublicConfig)
 	} else {
 		if resources[2] == "auth" {
-			fmt.Fprintf(w, "{\"driver\": \"%s\"}", u.PublicConfig.Uchiwa.Auth.Driver)
+			u.writeJSON(w, r, http.StatusOK, map[string]string{"driver": u.PublicConfig.Uchiwa.Auth.Driver})
+		} else if resources[2] == "features" {
+			u.writeJSON(w, r, http.StatusOK, u.features())
+		} else if resources[2] == "silencing" {
+			u.writeJSON(w, r, http.StatusOK, newSilencingPolicy(u.Config.Uchiwa.UsersOptions))
 		} else if resources[2] == "users" {
-			encoder := json.NewEncoder(w)
-			if err := encoder.Encode(u.PublicConfig.Uchiwa.UsersOptions); err != nil {
-				http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
-				return
-			}
+			u.writeJSON(w, r, http.StatusOK, u.PublicConfig.Uchiwa.UsersOptions)
 		} else {
 			http.Error(w, "", http.StatusNotFound)
 			return
@@ -594,7 +940,11 @@ func (u *Uchiwa) datacenterHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	name := resources[2]
+	name, ok := u.datacenterName(resources[2])
+	if !ok {
+		datacenterNotFound(w, resources[2])
+		return
+	}
 
 	token := authentication.GetJWTFromContext(r)
 	unauthorized := Filters.GetRequest(name, token)
@@ -603,9 +953,10 @@ func (u *Uchiwa) datacenterHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Create header
-	w.Header().Add("Accept-Charset", "utf-8")
-	w.Header().Add("Content-Type", "application/json")
+	if len(resources) == 4 && resources[3] == "event-rate" {
+		u.datacenterEventRateHandler(w, r, name)
+		return
+	}
 
 	datacenter, err := u.Datacenter(name)
 	if err != nil {
@@ -613,13 +964,41 @@ func (u *Uchiwa) datacenterHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	encoder := json.NewEncoder(w)
-	if err := encoder.Encode(datacenter); err != nil {
-		http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
+	u.writeJSON(w, r, http.StatusOK, datacenter)
+
+	return
+}
+
+// datacenterEventRateHandler serves the /datacenters/:name/event-rate
+// endpoint. The window parameter limits the samples to the provided number of
+// most recent seconds
+func (u *Uchiwa) datacenterEventRateHandler(w http.ResponseWriter, r *http.Request, name string) {
+	var window int64
+	if s := r.URL.Query().Get("window"); s != "" {
+		var err error
+		window, err = strconv.ParseInt(s, 10, 64)
+		if err != nil || window < 1 {
+			http.Error(w, "Invalid window parameter", http.StatusBadRequest)
+			return
+		}
+	}
+
+	since := time.Unix(0, 0)
+	if window > 0 {
+		since = time.Now().Add(-time.Duration(window) * time.Second)
+	}
+
+	u.lockData()
+	_, err := u.Datacenter(name)
+	points := u.eventRates.since(name, since)
+	u.Mu.Unlock()
+
+	if err != nil {
+		http.Error(w, fmt.Sprint(""), http.StatusNotFound)
 		return
 	}
 
-	return
+	u.writeJSON(w, r, http.StatusOK, points)
 }
 
 // datacentersHandler serves the /datacenters endpoint
@@ -632,29 +1011,76 @@ func (u *Uchiwa) datacentersHandler(w http.ResponseWriter, r *http.Request) {
 	token := authentication.GetJWTFromContext(r)
 	datacenters := Filters.Datacenters(u.Data.Dc, token)
 
-	// Create header
-	w.Header().Add("Accept-Charset", "utf-8")
-	w.Header().Add("Content-Type", "application/json")
+	u.writeJSON(w, r, http.StatusOK, datacenters)
+	return
+}
+
+// datacentersFreshnessHandler serves the /datacenters/freshness endpoint
+func (u *Uchiwa) datacentersFreshnessHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
 
-	// If GZIP compression is not supported by the client
-	if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
-		encoder := json.NewEncoder(w)
-		if err := encoder.Encode(datacenters); err != nil {
-			http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
-			return
+	token := authentication.GetJWTFromContext(r)
+
+	// Datacenters that were never polled successfully are reported with a
+	// timestamp of zero
+	freshness := make(map[string]int64)
+	u.lockData()
+	for _, datacenter := range *u.Datacenters {
+		if Filters.GetRequest(datacenter.Name, token) {
+			continue
 		}
+		freshness[datacenter.Name] = u.Data.LastPoll[datacenter.Name]
+	}
+	u.Mu.Unlock()
+
+	u.writeJSON(w, r, http.StatusOK, freshness)
+}
+
+// datacentersProblemsHandler serves the /datacenters/problems endpoint
+func (u *Uchiwa) datacentersProblemsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "", http.StatusBadRequest)
 		return
 	}
 
-	w.Header().Set("Content-Encoding", "gzip")
+	token := authentication.GetJWTFromContext(r)
+
+	// Use the configured datacenters, since the unreachable ones are missing
+	// from the data
+	u.lockData()
+	var datacenters []string
+	for _, datacenter := range *u.Datacenters {
+		if Filters.GetRequest(datacenter.Name, token) {
+			continue
+		}
+		datacenters = append(datacenters, datacenter.Name)
+	}
+	events := Filters.Events(&u.Data.Events, token)
+	problems := datacenterProblems(datacenters, events, u.Data.Health.Sensu)
+	u.Mu.Unlock()
+
+	u.writeJSON(w, r, http.StatusOK, problems)
+}
 
-	gz := gzip.NewWriter(w)
-	defer gz.Close()
-	if err := json.NewEncoder(gz).Encode(datacenters); err != nil {
-		http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
+// datacentersRankedHandler serves the /datacenters/ranked endpoint
+func (u *Uchiwa) datacentersRankedHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "", http.StatusBadRequest)
 		return
 	}
-	return
+
+	token := authentication.GetJWTFromContext(r)
+
+	u.lockData()
+	datacenters := Filters.Datacenters(u.Data.Dc, token)
+	events := Filters.Events(&u.Data.Events, token)
+	ranked := rankDatacenters(datacenters, events, u.Data.Health.Sensu)
+	u.Mu.Unlock()
+
+	u.writeJSON(w, r, http.StatusOK, ranked)
 }
 
 // eventHandler serves the /events/:client/:check endpoint
@@ -675,7 +1101,10 @@ func (u *Uchiwa) eventHandler(w http.ResponseWriter, r *http.Request) {
 	token := authentication.GetJWTFromContext(r)
 
 	// Get the datacenter name, passed as a query string
-	dc := r.URL.Query().Get("dc")
+	dc, ok := u.datacenterParam(w, r)
+	if !ok {
+		return
+	}
 
 	if dc == "" {
 		clients, err := u.findClient(client)
@@ -684,37 +1113,14 @@ func (u *Uchiwa) eventHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		u.Mu.Lock()
+		u.lockData()
 		visibleClients := Filters.Clients(&clients, token)
 		u.Mu.Unlock()
 
 		if len(visibleClients) > 1 {
-			// Create header
-			w.Header().Add("Accept-Charset", "utf-8")
-			w.Header().Add("Content-Type", "application/json")
-
-			// If GZIP compression is not supported by the client
-			if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
-				w.WriteHeader(http.StatusMultipleChoices)
-
-				encoder := json.NewEncoder(w)
-				if err = encoder.Encode(visibleClients); err != nil {
-					http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
-					return
-				}
-				return
-			}
-
-			w.Header().Add("Content-Encoding", "gzip")
-			w.WriteHeader(http.StatusMultipleChoices)
-
-			gz := gzip.NewWriter(w)
-			defer gz.Close()
-			if err = json.NewEncoder(gz).Encode(visibleClients); err != nil {
-				http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
-				return
-			}
+			visibleClients = prioritizeDatacenters(visibleClients, u.Config.Uchiwa.DatacenterPriority)
 
+			u.writeJSON(w, r, http.StatusMultipleChoices, visibleClients)
 			return
 		}
 
@@ -743,172 +1149,513 @@ func (u *Uchiwa) eventHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Add the resolution to the audit log
+	auditAction(r, token, "resolve", fmt.Sprintf("/events/%s/%s?dc=%s", client, check, dc), eventResources(client, check, dc)...)
+
 	w.WriteHeader(http.StatusAccepted)
 	return
 }
 
-// eventsHandler serves the /events endpoint
-func (u *Uchiwa) eventsHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+// eventsBulkHandler serves the /events/bulk endpoint, which resolves every
+// provided event. A failed resolution does not abort the others and is
+// reported along with its error
+func (u *Uchiwa) eventsBulkHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
 		http.Error(w, "", http.StatusBadRequest)
 		return
 	}
 
+	var items []eventResolution
+	if !u.decodeJSONBody(w, r, &items) {
+		return
+	}
+	if len(items) == 0 {
+		writeJSONError(w, http.StatusBadRequest, "At least one event must be provided")
+		return
+	}
+
 	token := authentication.GetJWTFromContext(r)
 
-	u.Mu.Lock()
-	events := Filters.Events(&u.Data.Events, token)
-	u.Mu.Unlock()
+	status := http.StatusAccepted
+	results := make([]eventResolution, len(items))
+
+	for i, item := range items {
+		result := eventResolution{Check: item.Check, Client: item.Client, Dc: item.Dc}
+		dc, found := u.datacenterName(item.Dc)
+		if found {
+			result.Dc = dc
+		}
+
+		if result.Client == "" || result.Check == "" || result.Dc == "" {
+			result.Error = "The client, check and dc are required"
+		} else if !found {
+			result.Error = fmt.Sprintf("Could not find the datacenter '%s'", item.Dc)
+		} else if Filters.GetRequest(result.Dc, token) {
+			// Do not reveal the existence of the unauthorized datacenters
+			result.Error = "Not found"
+		} else if err := u.ResolveEvent(result.Check, result.Client, result.Dc); err != nil {
+			result.Error = err.Error()
+		} else {
+			// Add the resolution to the audit log
+			auditAction(r, token, "resolve", fmt.Sprintf("/events/%s/%s?dc=%s", result.Client, result.Check, result.Dc), eventResources(result.Client, result.Check, result.Dc)...)
+		}
 
-	if len(events) == 0 {
-		events = make([]interface{}, 0)
+		if result.Error != "" {
+			status = http.StatusMultiStatus
+		}
+		results[i] = result
 	}
 
-	// Create header
-	w.Header().Add("Accept-Charset", "utf-8")
-	w.Header().Add("Content-Type", "application/json")
+	u.writeJSON(w, r, status, results)
+}
+
+// eventsHandler serves the /events endpoint
+func (u *Uchiwa) eventsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
 
-	// If GZIP compression is not supported by the client
-	if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
-		encoder := json.NewEncoder(w)
-		if err := encoder.Encode(events); err != nil {
-			http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
+	// Optionally filter the events on whether they are silenced
+	filter := r.URL.Query().Get("silenced")
+	var silenced bool
+	if filter != "" {
+		var err error
+		silenced, err = strconv.ParseBool(filter)
+		if err != nil {
+			http.Error(w, "Invalid silenced parameter", http.StatusBadRequest)
 			return
 		}
+	}
+
+	query := r.URL.Query()
+	if !u.datacenterParams(w, query) {
 		return
 	}
 
-	w.Header().Set("Content-Encoding", "gzip")
+	criteria, err := parseEventFilters(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	gz := gzip.NewWriter(w)
-	defer gz.Close()
-	if err := json.NewEncoder(gz).Encode(events); err != nil {
-		http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
+	// The events can also be sorted by how long they have been in a problem
+	// state, in descending order by default
+	page, err := parseListPage(r.URL.Query(), append([]string{"duration"}, sortableFields...))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	return
-}
+	// Optionally group the events sharing the same check name and output
+	dedupe := false
+	if d := r.URL.Query().Get("dedupeOutput"); d != "" {
+		var err error
+		dedupe, err = strconv.ParseBool(d)
+		if err != nil {
+			http.Error(w, "Invalid dedupeOutput parameter", http.StatusBadRequest)
+			return
+		}
+	}
 
-// healthHandler serves the /health endpoint
-func (u *Uchiwa) healthHandler(w http.ResponseWriter, r *http.Request) {
-	var encoded []byte
-	var err error
-	returnCode := http.StatusOK
+	// Optionally export the events as CSV, which has no room for the groups
+	csv, err := wantsFormat(r, "csv", "text/csv")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if csv && dedupe {
+		http.Error(w, "The dedupeOutput parameter is not supported in the CSV format", http.StatusBadRequest)
+		return
+	}
 
-	if r.URL.Path[1:] == "health/sensu" {
-		for _, sensu := range u.Data.Health.Sensu {
-			if sensu.Output != "ok" {
-				returnCode = http.StatusServiceUnavailable
-			}
-		}
-		encoded, err = json.Marshal(u.Data.Health.Sensu)
-	} else if r.URL.Path[1:] == "health/uchiwa" {
-		if u.Data.Health.Uchiwa != "ok" {
-			returnCode = http.StatusServiceUnavailable
-		}
-		encoded, err = json.Marshal(u.Data.Health.Uchiwa)
-	} else {
-		for _, sensu := range u.Data.Health.Sensu {
-			if sensu.Output != "ok" {
-				returnCode = http.StatusServiceUnavailable
-			}
-		}
+	token := authentication.GetJWTFromContext(r)
 
-		if u.Data.Health.Uchiwa != "ok" {
-			returnCode = http.StatusServiceUnavailable
-		}
+	u.lockData()
+	events := filterElements(Filters.Events(&u.Data.Events, token), criteria)
+	if filter != "" {
+		events = filterSilencedEvents(events, u.Data.Silenced, silenced)
+	}
+	u.Mu.Unlock()
 
-		encoded, err = json.Marshal(u.Data.Health)
+	// The events are sorted before they are grouped
+	if page.sort == "duration" {
+		events = sortEventsByDuration(events, time.Now(), page.dir == sortAscending)
+	} else if page.sort != "" {
+		events = sortList(events, page.sort, page.dir == sortDescending)
 	}
+	page.sort = ""
+	if dedupe {
+		events = groupEventsByOutput(events)
+	}
+	events = paginateList(w, r, events, page)
 
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
+	if csv {
+		u.writeCSV(w, r, events, eventColumns, "events.csv")
 		return
 	}
 
-	w.WriteHeader(returnCode)
-	w.Write(encoded)
+	u.writeList(w, r, events)
+
 	return
 }
 
-// logoutHandler serves the /logout endpoint
-func (u *Uchiwa) logoutHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+// eventsNeverOKHandler serves the /events/neverok endpoint
+func (u *Uchiwa) eventsNeverOKHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
 		http.Error(w, "", http.StatusBadRequest)
 		return
 	}
 
 	token := authentication.GetJWTFromContext(r)
-	var username string
-	username, ok := token.Claims["username"].(string)
-	if !ok {
-		username = "Unknown"
-	}
 
-	// Add the logout to the audit log
-	log := structs.AuditLog{
-		Action:     "logout",
-		Level:      "default",
-		RemoteAddr: helpers.GetIP(r),
-		User:       username,
+	u.lockData()
+	events := Filters.Events(&u.Data.Events, token)
+	u.Mu.Unlock()
+
+	neverOK := make([]interface{}, 0)
+	for _, e := range events {
+		event, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if isNeverOK(event) {
+			neverOK = append(neverOK, event)
+		}
 	}
-	audit.Log(log)
 
-	authentication.DeleteCookies(w)
-	http.Redirect(w, r, "/login", 302)
-	return
+	u.writeList(w, r, neverOK)
 }
 
-// metricsHandler serves the /metrics endpoint
-func (u *Uchiwa) metricsHandler(w http.ResponseWriter, r *http.Request) {
+// eventsStatusesHandler serves the /events/statuses endpoint
+func (u *Uchiwa) eventsStatusesHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet && r.Method != http.MethodHead {
 		http.Error(w, "", http.StatusBadRequest)
 		return
 	}
 
-	encoder := json.NewEncoder(w)
-	if err := encoder.Encode(&u.Data.Metrics); err != nil {
-		http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
-		return
-	}
+	token := authentication.GetJWTFromContext(r)
+
+	u.lockData()
+	events := Filters.Events(&u.Data.Events, token)
+	statuses := countEventStatuses(events)
+	u.Mu.Unlock()
+
+	u.writeJSON(w, r, http.StatusOK, statuses)
 }
 
-// requestHandler serves the /request endpoint
-func (u *Uchiwa) requestHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
+// eventsFlappingHandler serves the /events/flapping endpoint
+func (u *Uchiwa) eventsFlappingHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
 		http.Error(w, "", http.StatusBadRequest)
 		return
 	}
 
-	decoder := json.NewDecoder(r.Body)
-	var data structs.CheckExecution
-	err := decoder.Decode(&data)
-	if err != nil {
-		http.Error(w, "Could not decode body", http.StatusInternalServerError)
-		return
+	threshold := defaultFlapThreshold
+	if t := r.URL.Query().Get("threshold"); t != "" {
+		var err error
+		threshold, err = strconv.Atoi(t)
+		if err != nil || threshold < 0 {
+			http.Error(w, "Invalid threshold parameter", http.StatusBadRequest)
+			return
+		}
 	}
 
-	// verify that the authenticated user is authorized to access this resource
 	token := authentication.GetJWTFromContext(r)
-	unauthorized := Filters.GetRequest(data.Dc, token)
-	if unauthorized {
-		http.Error(w, fmt.Sprint(""), http.StatusNotFound)
-		return
-	}
+	since := time.Now().Add(-time.Duration(u.Config.Uchiwa.Flapping.Window) * time.Second)
 
-	err = u.IssueCheckExecution(data)
-	if err != nil {
-		http.Error(w, "", http.StatusNotFound)
-		return
-	}
+	u.lockData()
+	events := Filters.Events(&u.Data.Events, token)
+	flapping := flappingEvents(events, u.transitions.transitions, threshold, since)
+	u.Mu.Unlock()
 
-	return
+	u.writeJSON(w, r, http.StatusOK, flapping)
 }
 
-// resultsHandler serves the /results/:client/:check endpoint
-func (u *Uchiwa) resultsHandler(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodDelete {
+// eventsResolutionStatsHandler serves the /events/resolution-stats endpoint
+func (u *Uchiwa) eventsResolutionStatsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+
+	var since int64
+	if s := r.URL.Query().Get("since"); s != "" {
+		var err error
+		since, err = strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			http.Error(w, "The since parameter must be a Unix timestamp", http.StatusBadRequest)
+			return
+		}
+	}
+
+	token := authentication.GetJWTFromContext(r)
+
+	u.lockData()
+	var samples []resolutionSample
+	for _, sample := range u.resolutions.samples {
+		if !Filters.GetRequest(sample.Dc, token) {
+			samples = append(samples, sample)
+		}
+	}
+	u.Mu.Unlock()
+
+	stats := buildResolutionStats(samples, time.Unix(since, 0))
+
+	u.writeJSON(w, r, http.StatusOK, stats)
+}
+
+// eventsSinceHandler serves the /events/since endpoint
+func (u *Uchiwa) eventsSinceHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+
+	token := authentication.GetJWTFromContext(r)
+
+	u.lockData()
+	changes := u.events.since(r.URL.Query().Get("cursor"))
+	delta := eventsDelta{
+		Cursor:  changes.cursor,
+		Resync:  changes.resync,
+		Events:  Filters.Events(&changes.events, token),
+		Removed: eventIDs(Filters.Events(&changes.removed, token)),
+	}
+	u.Mu.Unlock()
+
+	if delta.Events == nil {
+		delta.Events = []interface{}{}
+	}
+
+	u.writeJSON(w, r, http.StatusOK, delta)
+}
+
+// healthHandler serves the /health endpoint
+func (u *Uchiwa) healthHandler(w http.ResponseWriter, r *http.Request) {
+	var data interface{}
+	returnCode := http.StatusOK
+
+	if r.URL.Path[1:] == "health/sensu" {
+		for _, sensu := range u.Data.Health.Sensu {
+			if sensu.Output != "ok" {
+				returnCode = http.StatusServiceUnavailable
+			}
+		}
+		data = u.Data.Health.Sensu
+	} else if r.URL.Path[1:] == "health/ready" {
+		u.lockData()
+		status := u.readiness.status(*u.Datacenters)
+		u.Mu.Unlock()
+
+		if !status.Ready {
+			returnCode = http.StatusServiceUnavailable
+		}
+		data = status
+	} else if r.URL.Path[1:] == "health/uchiwa" {
+		if u.Data.Health.Uchiwa != "ok" {
+			returnCode = http.StatusServiceUnavailable
+		}
+		data = u.Data.Health.Uchiwa
+	} else {
+		for _, sensu := range u.Data.Health.Sensu {
+			if sensu.Output != "ok" {
+				returnCode = http.StatusServiceUnavailable
+			}
+		}
+
+		if u.Data.Health.Uchiwa != "ok" {
+			returnCode = http.StatusServiceUnavailable
+		}
+
+		data = u.Data.Health
+	}
+
+	u.writeJSON(w, r, returnCode, data)
+}
+
+// logoutHandler serves the /logout endpoint
+func (u *Uchiwa) logoutHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+
+	token := authentication.GetJWTFromContext(r)
+
+	// Add the logout to the audit log
+	auditAction(r, token, "logout", "")
+
+	authentication.DeleteCookies(w)
+	http.Redirect(w, r, "/login", 302)
+	return
+}
+
+// logsHandler serves the /logs endpoint
+func (u *Uchiwa) logsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+
+	limit := 100
+	if l := r.URL.Query().Get("limit"); l != "" {
+		var err error
+		limit, err = strconv.Atoi(l)
+		if err != nil || limit < 1 {
+			http.Error(w, "The limit parameter must be a positive integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	entries := logger.Entries(limit, r.URL.Query().Get("level"))
+
+	u.writeJSON(w, r, http.StatusOK, entries)
+}
+
+// metricsHandler serves the /metrics endpoint, in JSON or, if requested, in
+// the Prometheus text exposition format
+func (u *Uchiwa) metricsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+
+	prometheus, err := wantsFormat(r, "prometheus", "text/plain")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if prometheus {
+		token := authentication.GetJWTFromContext(r)
+
+		u.lockData()
+		var names []string
+		for _, datacenter := range *u.Datacenters {
+			if !Filters.GetRequest(datacenter.Name, token) {
+				names = append(names, datacenter.Name)
+			}
+		}
+		datacenters := buildPrometheusDatacenters(names, Filters.Datacenters(u.Data.Dc, token), Filters.Events(&u.Data.Events, token), u.Data.Health.Sensu)
+		u.Mu.Unlock()
+
+		w.Header().Set("Content-Type", prometheusContentType)
+		if err := u.writeEncoded(w, r, http.StatusOK, encodePrometheus(datacenters)); err != nil {
+			logger.Warningf("Cannot write response data: %v", err)
+		}
+		return
+	}
+
+	u.writeJSON(w, r, http.StatusOK, &u.Data.Metrics)
+}
+
+// metricsRuntimeHandler serves the /metrics/runtime endpoint
+func (u *Uchiwa) metricsRuntimeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+
+	stats := u.buildRuntimeStats()
+
+	u.writeJSON(w, r, http.StatusOK, stats)
+}
+
+// requestHandler serves the /request endpoint
+func (u *Uchiwa) requestHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+
+	var data structs.CheckExecution
+	if !u.decodeJSONBody(w, r, &data) {
+		return
+	}
+
+	dc, ok := u.datacenterName(data.Dc)
+	if !ok {
+		datacenterNotFound(w, data.Dc)
+		return
+	}
+	data.Dc = dc
+
+	// verify that the authenticated user is authorized to access this resource
+	token := authentication.GetJWTFromContext(r)
+	unauthorized := Filters.GetRequest(data.Dc, token)
+	if unauthorized {
+		http.Error(w, fmt.Sprint(""), http.StatusNotFound)
+		return
+	}
+
+	// Only the allowed checks can be executed on demand
+	if !isAdhocCheckAllowed(data.Check, u.Config.Uchiwa.UsersOptions.AllowedAdhocChecks) {
+		// Add the rejection to the audit log
+		log := structs.AuditLog{
+			Action:     "requestdenied",
+			Level:      "default",
+			Output:     fmt.Sprintf("The check '%s' is not allowed to be executed on demand", data.Check),
+			RemoteAddr: helpers.GetIP(r),
+			Resources:  []structs.AuditResource{{Type: "check", Name: data.Check, Dc: data.Dc}},
+			URL:        r.URL.String(),
+			User:       getUsername(token),
+		}
+		audit.Log(log)
+
+		http.Error(w, fmt.Sprintf("The check '%s' cannot be executed on demand", data.Check), http.StatusForbidden)
+		return
+	}
+
+	err := u.IssueCheckExecution(data)
+
+	u.lockData()
+	u.checkRequests.add(data, err, time.Now())
+	u.Mu.Unlock()
+
+	if err != nil {
+		http.Error(w, "", http.StatusNotFound)
+		return
+	}
+
+	// Add the check execution to the audit log
+	auditAction(r, token, "request", fmt.Sprintf("/checks/%s?dc=%s", data.Check, data.Dc), structs.AuditResource{Type: "check", Name: data.Check, Dc: data.Dc})
+
+	return
+}
+
+// requestPendingHandler serves the /request/pending endpoint
+func (u *Uchiwa) requestPendingHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+
+	dc, ok := u.datacenterParam(w, r)
+	if !ok {
+		return
+	}
+	token := authentication.GetJWTFromContext(r)
+
+	u.lockData()
+	requests := []checkRequest{}
+	for _, request := range u.checkRequests.pending(time.Now(), u.Config.Uchiwa.CheckRequests.TTL) {
+		if dc != "" && request.Dc != dc {
+			continue
+		}
+		if !Filters.GetRequest(request.Dc, token) {
+			requests = append(requests, request)
+		}
+	}
+	u.Mu.Unlock()
+
+	u.writeJSON(w, r, http.StatusOK, requests)
+}
+
+// resultsHandler serves the /results/:client/:check endpoint
+func (u *Uchiwa) resultsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
 		http.Error(w, "", http.StatusBadRequest)
 		return
 	}
@@ -924,7 +1671,10 @@ func (u *Uchiwa) resultsHandler(w http.ResponseWriter, r *http.Request) {
 	token := authentication.GetJWTFromContext(r)
 
 	// Get the datacenter name, passed as a query string
-	dc := r.URL.Query().Get("dc")
+	dc, ok := u.datacenterParam(w, r)
+	if !ok {
+		return
+	}
 
 	if dc == "" {
 		clients, err := u.findClient(client)
@@ -933,37 +1683,14 @@ func (u *Uchiwa) resultsHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		u.Mu.Lock()
+		u.lockData()
 		visibleClients := Filters.Clients(&clients, token)
 		u.Mu.Unlock()
 
 		if len(visibleClients) > 1 {
-			// Create header
-			w.Header().Add("Accept-Charset", "utf-8")
-			w.Header().Add("Content-Type", "application/json")
-
-			// If GZIP compression is not supported by the client
-			if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
-				w.WriteHeader(http.StatusMultipleChoices)
-
-				encoder := json.NewEncoder(w)
-				if err = encoder.Encode(visibleClients); err != nil {
-					http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
-					return
-				}
-				return
-			}
-
-			w.Header().Add("Content-Encoding", "gzip")
-			w.WriteHeader(http.StatusMultipleChoices)
-
-			gz := gzip.NewWriter(w)
-			defer gz.Close()
-			if err = json.NewEncoder(gz).Encode(visibleClients); err != nil {
-				http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
-				return
-			}
+			visibleClients = prioritizeDatacenters(visibleClients, u.Config.Uchiwa.DatacenterPriority)
 
+			u.writeJSON(w, r, http.StatusMultipleChoices, visibleClients)
 			return
 		}
 
@@ -991,6 +1718,9 @@ func (u *Uchiwa) resultsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Add the deletion to the audit log
+	auditAction(r, token, "deleteresult", fmt.Sprintf("/results/%s/%s?dc=%s", client, check, dc), eventResources(client, check, dc)...)
+
 	w.WriteHeader(http.StatusAccepted)
 	return
 }
@@ -1012,7 +1742,10 @@ func (u *Uchiwa) stashHandler(w http.ResponseWriter, r *http.Request) {
 	token := authentication.GetJWTFromContext(r)
 
 	// Get the datacenter name, passed as a query string
-	dc := r.URL.Query().Get("dc")
+	dc, ok := u.datacenterParam(w, r)
+	if !ok {
+		return
+	}
 
 	if dc == "" {
 		stashes, err := u.findStash(path)
@@ -1021,37 +1754,14 @@ func (u *Uchiwa) stashHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		u.Mu.Lock()
+		u.lockData()
 		visibleStashes := Filters.Stashes(&stashes, token)
 		u.Mu.Unlock()
 
 		if len(visibleStashes) > 1 {
-			// Create header
-			w.Header().Add("Accept-Charset", "utf-8")
-			w.Header().Add("Content-Type", "application/json")
-
-			// If GZIP compression is not supported by the client
-			if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
-				w.WriteHeader(http.StatusMultipleChoices)
-
-				encoder := json.NewEncoder(w)
-				if err = encoder.Encode(visibleStashes); err != nil {
-					http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
-					return
-				}
-				return
-			}
-
-			w.Header().Add("Content-Encoding", "gzip")
-			w.WriteHeader(http.StatusMultipleChoices)
-
-			gz := gzip.NewWriter(w)
-			defer gz.Close()
-			if err = json.NewEncoder(gz).Encode(visibleStashes); err != nil {
-				http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
-				return
-			}
+			visibleStashes = prioritizeDatacenters(visibleStashes, u.Config.Uchiwa.DatacenterPriority)
 
+			u.writeJSON(w, r, http.StatusMultipleChoices, visibleStashes)
 			return
 		}
 
@@ -1080,57 +1790,77 @@ func (u *Uchiwa) stashHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Add the deletion to the audit log
+	auditAction(r, token, "deletestash", fmt.Sprintf("/stashes/%s?dc=%s", path, dc), structs.AuditResource{Type: "stash", Name: path, Dc: dc})
+
 	w.WriteHeader(http.StatusAccepted)
 	return
 }
 
+// searchHandler serves the /search endpoint
+func (u *Uchiwa) searchHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+
+	term := r.URL.Query().Get("q")
+	if term == "" {
+		http.Error(w, "The q parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	token := authentication.GetJWTFromContext(r)
+
+	u.lockData()
+	aggregates := Filters.Aggregates(&u.Data.Aggregates, token)
+	checks := Filters.Checks(&u.Data.Checks, token)
+	clients := Filters.Clients(&u.Data.Clients, token)
+	stashes := Filters.Stashes(&u.Data.Stashes, token)
+	u.Mu.Unlock()
+
+	results := searchResults{
+		Aggregates: searchResources(term, "name", aggregates, searchLimit),
+		Checks:     searchResources(term, "name", checks, searchLimit),
+		Clients:    searchResources(term, "name", clients, searchLimit),
+		Stashes:    searchResources(term, "path", stashes, searchLimit),
+	}
+
+	u.writeJSON(w, r, http.StatusOK, results)
+}
+
 // silencedHandler serves the /silenced endpoint
 func (u *Uchiwa) silencedHandler(w http.ResponseWriter, r *http.Request) {
 	token := authentication.GetJWTFromContext(r)
 
 	if r.Method == http.MethodGet || r.Method == http.MethodHead {
 		// GET on /silenced
-		u.Mu.Lock()
-		silenced := Filters.Silenced(&u.Data.Silenced, token)
-		u.Mu.Unlock()
-
-		if len(silenced) == 0 {
-			silenced = make([]interface{}, 0)
-		}
-
-		// Create header
-		w.Header().Add("Accept-Charset", "utf-8")
-		w.Header().Add("Content-Type", "application/json")
-
-		// If GZIP compression is not supported by the client
-		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
-			encoder := json.NewEncoder(w)
-			if err := encoder.Encode(silenced); err != nil {
-				http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
-				return
-			}
+		page, err := parseListPage(r.URL.Query(), sortableFields)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
-		w.Header().Set("Content-Encoding", "gzip")
+		u.lockData()
+		silenced := Filters.Silenced(&u.Data.Silenced, token)
+		u.Mu.Unlock()
 
-		gz := gzip.NewWriter(w)
-		defer gz.Close()
-		if err := json.NewEncoder(gz).Encode(silenced); err != nil {
-			http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
-			return
-		}
+		u.writeList(w, r, paginateList(w, r, silenced, page))
 
 		return
 	} else if r.Method == http.MethodPost {
 		// POST on /silenced
-		decoder := json.NewDecoder(r.Body)
 		var data silence
-		err := decoder.Decode(&data)
-		if err != nil {
-			http.Error(w, "Could not decode body", http.StatusInternalServerError)
+		if !u.decodeJSONBody(w, r, &data) {
+			return
+		}
+
+		dc, ok := u.datacenterName(data.Dc)
+		if !ok {
+			datacenterNotFound(w, data.Dc)
 			return
 		}
+		data.Dc = dc
 
 		// verify that the authenticated user is authorized to access this resource
 		unauthorized := Filters.GetRequest(data.Dc, token)
@@ -1139,88 +1869,228 @@ func (u *Uchiwa) silencedHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		if token != nil && token.Claims["username"] != nil {
-			data.Creator = token.Claims["username"].(string)
-		}
+		data.Creator = getUsername(token)
 
 		resources := strings.Split(r.URL.Path, "/")
 		if len(resources) > 2 && resources[2] == "clear" {
-			err = u.ClearSilenced(data)
+			err := u.ClearSilenced(data)
 			if err != nil {
 				http.Error(w, "Could not clear from entry in the silenced registry", http.StatusNotFound)
 				return
 			}
-			return
-		}
 
-		if u.Config.Uchiwa.UsersOptions.DisableNoExpiration && (data.Expire < 1 && !data.ExpireOnResolve) {
-			http.Error(w, "Open-ended silence entries are disallowed", http.StatusNotFound)
+			// Add the clearing to the audit log
+			auditAction(r, token, "clearsilence", silenceURL(data), silenceResources(data)...)
 			return
 		}
 
-		if u.Config.Uchiwa.UsersOptions.RequireSilencingReason && data.Reason == "" {
-			http.Error(w, "A reason must be provided for every silence entry", http.StatusNotFound)
+		policy := newSilencingPolicy(u.Config.Uchiwa.UsersOptions)
+		defaulted := policy.applyDefaults(&data)
+		if err := policy.validate(data); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
 			return
 		}
-
-		err = u.PostSilence(data)
-		if err != nil {
-			http.Error(w, "Could not create the entry in the silenced registry", http.StatusNotFound)
+		if err := policy.validateReason(data); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
-	} else {
+
+		// Optionally verify that the subscription & check actually exist
+		var mismatches []string
+		if policy.ValidateSilenceTargets != "" {
+			u.lockData()
+			mismatches = silenceTargetMismatches(data, u.Data.Clients, u.Data.Checks, u.Data.Events)
+			u.Mu.Unlock()
+
+			if len(mismatches) > 0 && policy.ValidateSilenceTargets == silenceTargetsReject {
+				writeJSONError(w, http.StatusUnprocessableEntity, strings.Join(mismatches, ". "))
+				return
+			}
+		}
+
+		err := u.PostSilence(data)
+		if err != nil {
+			http.Error(w, "Could not create the entry in the silenced registry", http.StatusNotFound)
+			return
+		}
+
+		// Add the creation to the audit log
+		auditAction(r, token, "silence", silenceURL(data), silenceResources(data)...)
+
+		// Report the warnings and the default expiration applied, if any
+		response := make(map[string]interface{})
+		if len(mismatches) > 0 {
+			logger.Warningf("The silence entry created by %s targets unknown resources: %s", data.Creator, strings.Join(mismatches, ". "))
+			response["warnings"] = mismatches
+		}
+		if defaulted {
+			response["expire"] = data.Expire
+		}
+
+		if len(response) > 0 {
+			u.writeJSON(w, r, http.StatusOK, response)
+		}
+	} else {
 		http.Error(w, "", http.StatusBadRequest)
 		return
 	}
 }
 
-// stashesHandler serves the /stashes endpoint
-func (u *Uchiwa) stashesHandler(w http.ResponseWriter, r *http.Request) {
+// silencedBulkHandler serves the /silenced/bulk endpoint, which creates every
+// provided silence entry according to the silencing policy. A failed creation
+// does not abort the others and is reported along with its error
+func (u *Uchiwa) silencedBulkHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+
+	var items []silence
+	if !u.decodeJSONBody(w, r, &items) {
+		return
+	}
+	if len(items) == 0 {
+		writeJSONError(w, http.StatusBadRequest, "At least one silence entry must be provided")
+		return
+	}
+
 	token := authentication.GetJWTFromContext(r)
 
-	if r.Method == http.MethodGet || r.Method == http.MethodHead {
-		// GET on /stashes
-		u.Mu.Lock()
-		stashes := Filters.Stashes(&u.Data.Stashes, token)
-		u.Mu.Unlock()
+	creator := getUsername(token)
+
+	policy := newSilencingPolicy(u.Config.Uchiwa.UsersOptions)
 
-		if len(stashes) == 0 {
-			stashes = make([]interface{}, 0)
+	status := http.StatusOK
+	results := make([]silenceResult, len(items))
+
+	for i, data := range items {
+		data.Creator = creator
+		policy.applyDefaults(&data)
+
+		dc, found := u.datacenterName(data.Dc)
+		if found {
+			data.Dc = dc
 		}
 
-		// Create header
-		w.Header().Add("Accept-Charset", "utf-8")
-		w.Header().Add("Content-Type", "application/json")
+		result := silenceResult{silence: data}
+		if !found {
+			result.Error = fmt.Sprintf("Could not find the datacenter '%s'", data.Dc)
+		} else if Filters.GetRequest(data.Dc, token) {
+			// Do not reveal the existence of the unauthorized datacenters
+			result.Error = "Not found"
+		} else if err := policy.validate(data); err != nil {
+			result.Error = err.Error()
+		} else if err := policy.validateReason(data); err != nil {
+			result.Error = err.Error()
+		} else {
+			// Optionally verify that the subscription & check actually exist
+			if policy.ValidateSilenceTargets != "" {
+				u.lockData()
+				result.Warnings = silenceTargetMismatches(data, u.Data.Clients, u.Data.Checks, u.Data.Events)
+				u.Mu.Unlock()
+			}
 
-		// If GZIP compression is not supported by the client
-		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
-			encoder := json.NewEncoder(w)
-			if err := encoder.Encode(stashes); err != nil {
-				http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
-				return
+			if len(result.Warnings) > 0 && policy.ValidateSilenceTargets == silenceTargetsReject {
+				result.Error = strings.Join(result.Warnings, ". ")
+				result.Warnings = nil
+			} else if err := u.PostSilence(data); err != nil {
+				result.Error = "Could not create the entry in the silenced registry"
+			} else {
+				// Add the creation to the audit log
+				auditAction(r, token, "silence", silenceURL(data), silenceResources(data)...)
 			}
+		}
+
+		if result.Error != "" {
+			status = http.StatusMultiStatus
+		}
+		results[i] = result
+	}
+
+	u.writeJSON(w, r, status, results)
+}
+
+// silencedOrphanedHandler serves the /silenced/orphaned endpoint
+func (u *Uchiwa) silencedOrphanedHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+
+	token := authentication.GetJWTFromContext(r)
+
+	u.lockData()
+	silenced := Filters.Silenced(&u.Data.Silenced, token)
+	polled := make(map[string]bool, len(u.Data.Dc))
+	for _, dc := range u.Data.Dc {
+		polled[dc.Name] = true
+	}
+	orphaned := orphanedSilences(silenced, u.Data.Clients, u.Data.Checks, u.Data.Events, polled, time.Now())
+	u.Mu.Unlock()
+
+	u.writeList(w, r, orphaned)
+}
+
+// silencedExpiryHistogramHandler serves the /silenced/expiry-histogram endpoint
+func (u *Uchiwa) silencedExpiryHistogramHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+
+	bucket := int64(defaultExpiryBucket)
+	if b := r.URL.Query().Get("bucket"); b != "" {
+		var err error
+		bucket, err = strconv.ParseInt(b, 10, 64)
+		if err != nil || bucket < 1 {
+			http.Error(w, "Invalid bucket parameter", http.StatusBadRequest)
 			return
 		}
+	}
+
+	token := authentication.GetJWTFromContext(r)
+
+	u.lockData()
+	silenced := Filters.Silenced(&u.Data.Silenced, token)
+	u.Mu.Unlock()
 
-		w.Header().Set("Content-Encoding", "gzip")
+	histogram := buildExpiryHistogram(silenced, bucket, time.Now())
 
-		gz := gzip.NewWriter(w)
-		defer gz.Close()
-		if err := json.NewEncoder(gz).Encode(stashes); err != nil {
-			http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
+	u.writeJSON(w, r, http.StatusOK, histogram)
+}
+
+// stashesHandler serves the /stashes endpoint
+func (u *Uchiwa) stashesHandler(w http.ResponseWriter, r *http.Request) {
+	token := authentication.GetJWTFromContext(r)
+
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		// GET on /stashes
+		page, err := parseListPage(r.URL.Query(), sortableFields)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
+		u.lockData()
+		stashes := Filters.Stashes(&u.Data.Stashes, token)
+		u.Mu.Unlock()
+
+		u.writeList(w, r, paginateList(w, r, stashes, page))
+
 		return
 	} else if r.Method == http.MethodPost {
 		// POST on /stashes
-		decoder := json.NewDecoder(r.Body)
 		var data stash
-		err := decoder.Decode(&data)
-		if err != nil {
-			http.Error(w, "Could not decode body", http.StatusInternalServerError)
+		if !u.decodeJSONBody(w, r, &data) {
+			return
+		}
+
+		dc, ok := u.datacenterName(data.Dc)
+		if !ok {
+			datacenterNotFound(w, data.Dc)
 			return
 		}
+		data.Dc = dc
 
 		// verify that the authenticated user is authorized to access this resource
 		unauthorized := Filters.GetRequest(data.Dc, token)
@@ -1229,22 +2099,72 @@ func (u *Uchiwa) stashesHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		if token != nil && token.Claims["username"] != nil {
-			data.Content["username"] = token.Claims["username"]
+		// Optionally prevent the existing stashes from being overwritten
+		if u.Config.Uchiwa.UsersOptions.PreventStashOverwrite {
+			overwrite := false
+			if o := r.URL.Query().Get("overwrite"); o != "" {
+				var err error
+				overwrite, err = strconv.ParseBool(o)
+				if err != nil {
+					http.Error(w, "Invalid overwrite parameter", http.StatusBadRequest)
+					return
+				}
+			}
+
+			u.lockData()
+			exists := stashExists(u.Data.Stashes, data.Dc, data.Path)
+			u.Mu.Unlock()
+
+			if exists && !overwrite {
+				writeJSONError(w, http.StatusConflict, fmt.Sprintf("A stash already exists at the path '%s'", data.Path))
+				return
+			}
 		}
 
-		err = u.PostStash(data)
+		if data.Content == nil {
+			data.Content = make(map[string]interface{})
+		}
+		data.Content["username"] = getUsername(token)
+
+		err := u.PostStash(data)
 		if err != nil {
 			http.Error(w, "Could not create the stash", http.StatusNotFound)
 			return
 		}
+
+		// Add the creation to the audit log
+		auditAction(r, token, "createstash", fmt.Sprintf("/stashes/%s?dc=%s", data.Path, data.Dc), structs.AuditResource{Type: "stash", Name: data.Path, Dc: data.Dc})
 	} else {
 		http.Error(w, "", http.StatusBadRequest)
 		return
 	}
 }
 
-// subscriptionHandler serves the /subscriptions/:subscription endpoint
+// summaryHandler serves the /summary endpoint
+func (u *Uchiwa) summaryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+
+	token := authentication.GetJWTFromContext(r)
+
+	u.lockData()
+	clients := Filters.Clients(&u.Data.Clients, token)
+	events := Filters.Events(&u.Data.Events, token)
+	health := make(map[string]structs.SensuHealth, len(u.Data.Health.Sensu))
+	for name, h := range u.Data.Health.Sensu {
+		if !Filters.GetRequest(name, token) {
+			health[name] = h
+		}
+	}
+	summary := buildEstateSummary(clients, events, health)
+	u.Mu.Unlock()
+
+	u.writeCacheable(w, r, summary)
+}
+
+// subscriptionHandler serves the /subscriptions/:subscription(/coverage) endpoint
 func (u *Uchiwa) subscriptionHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet && r.Method != http.MethodHead {
 		http.Error(w, "", http.StatusBadRequest)
@@ -1257,6 +2177,17 @@ func (u *Uchiwa) subscriptionHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Are we responding to a /subscriptions/:subscription/(coverage|events)
+	// request?
+	action := ""
+	if len(resources) > 3 {
+		switch resources[len(resources)-1] {
+		case "coverage", "events":
+			action = resources[len(resources)-1]
+			resources = resources[:len(resources)-1]
+		}
+	}
+
 	name := strings.Join(resources[2:], "/")
 	subscriptions := []structs.Subscription{
 		structs.Subscription{Name: name},
@@ -1270,7 +2201,29 @@ func (u *Uchiwa) subscriptionHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	if action == "" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if action == "events" {
+		u.lockData()
+		clients := Filters.Clients(&u.Data.Clients, token)
+		events := Filters.Events(&u.Data.Events, token)
+		u.Mu.Unlock()
+
+		u.writeList(w, r, subscriptionEvents(name, clients, events))
+		return
+	}
+
+	u.lockData()
+	clients := Filters.Clients(&u.Data.Clients, token)
+	checks := Filters.Checks(&u.Data.Checks, token)
+	events := Filters.Events(&u.Data.Events, token)
+	results := retainedResults(events, u.Data.CheckSamples)
+	u.Mu.Unlock()
+
+	u.writeJSON(w, r, http.StatusOK, buildSubscriptionCoverage(name, clients, checks, results))
 	return
 }
 
@@ -1283,7 +2236,7 @@ func (u *Uchiwa) subscriptionsHandler(w http.ResponseWriter, r *http.Request) {
 
 	token := authentication.GetJWTFromContext(r)
 
-	u.Mu.Lock()
+	u.lockData()
 	subscriptions := Filters.Subscriptions(&u.Data.Subscriptions, token)
 	u.Mu.Unlock()
 
@@ -1291,11 +2244,7 @@ func (u *Uchiwa) subscriptionsHandler(w http.ResponseWriter, r *http.Request) {
 		subscriptions = make([]structs.Subscription, 0)
 	}
 
-	encoder := json.NewEncoder(w)
-	if err := encoder.Encode(subscriptions); err != nil {
-		http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
-		return
-	}
+	u.writeJSON(w, r, http.StatusOK, subscriptions)
 }
 
 // userHandler serves the /user endpoint
@@ -1311,12 +2260,132 @@ func (u *Uchiwa) userHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	encoder := json.NewEncoder(w)
-	if err := encoder.Encode(token.Claims); err != nil {
-		http.Error(w, fmt.Sprintf("Cannot encode response data: %v", err), http.StatusInternalServerError)
+	u.writeJSON(w, r, http.StatusOK, token.Claims)
+	return
+}
+
+// userCapabilitiesHandler serves the /user/capabilities endpoint
+func (u *Uchiwa) userCapabilitiesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "", http.StatusBadRequest)
 		return
 	}
-	return
+
+	capabilities := buildCapabilities(authentication.GetJWTFromContext(r))
+
+	u.writeJSON(w, r, http.StatusOK, capabilities)
+}
+
+// addVary adds the provided request header to the Vary header, unless it is
+// already listed
+func addVary(header http.Header, name string) {
+	for _, value := range header["Vary"] {
+		for _, field := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), name) {
+				return
+			}
+		}
+	}
+
+	header.Add("Vary", name)
+}
+
+// debugEncodingHandler serves the /debug/encoding endpoint, which describes
+// how the encoding of the responses is negotiated for the request
+func (u *Uchiwa) debugEncodingHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+
+	encoding := u.selectEncoding(r)
+	accepted := parseAcceptEncoding(r.Header.Get("Accept-Encoding"))
+	diagnostics := encodingDiagnostics{
+		AcceptEncoding:      r.Header.Get("Accept-Encoding"),
+		Accepted:            accepted,
+		UserAgent:           r.Header.Get("User-Agent"),
+		ForcedByUserAgent:   encoding == encodingGzip && u.forcesGzip(r) && !acceptsCoding(accepted, encodingGzip),
+		Encoding:            encoding,
+		Compressed:          encoding != encodingIdentity,
+		ForceGzipUserAgents: u.Config.Uchiwa.ForceGzipUserAgents,
+		MaxResponseBytes:    u.Config.Uchiwa.MaxResponseBytes,
+	}
+
+	u.writeJSON(w, r, http.StatusOK, diagnostics)
+}
+
+// adminHandler restricts the access to the users with an administrator role
+func adminHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isAdmin(authentication.GetJWTFromContext(r)) {
+			http.Error(w, "Request forbidden", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isAdmin returns whether the user has an administrator role, which is always
+// the case when the authentication is not enabled
+func isAdmin(token *jwt.Token) bool {
+	if token == nil {
+		return true
+	}
+
+	role, err := authentication.GetRoleFromToken(token)
+	return err == nil && role.Admin
+}
+
+// corsAllowedMethods and corsAllowedHeaders contain the methods and request
+// headers allowed from the other origins
+const (
+	corsAllowedMethods = "GET, HEAD, POST, DELETE, OPTIONS"
+	corsAllowedHeaders = "Authorization, Content-Type, If-None-Match"
+)
+
+// corsExposedHeaders contains the response headers readable from the other
+// origins
+var corsExposedHeaders = strings.Join([]string{"Link", totalCountHeader, truncatedHeader, digestHeader, dataVersionHeader}, ", ")
+
+// corsHandler sets the CORS headers on the requests from the provided origins,
+// and answers their preflight requests before they reach the authentication,
+// since they carry no credentials. The credentials are never allowed with the
+// * wildcard, which allows any origin. It does nothing without any origin
+func corsHandler(next http.Handler, origins []string) http.Handler {
+	if len(origins) == 0 {
+		return next
+	}
+
+	wildcard := helpers.IsStringInArray("*", origins)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		w.Header().Add("Vary", "Origin")
+
+		if origin == "" || (!wildcard && !helpers.IsStringInArray(origin, origins)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if wildcard {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		// Preflight request
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", corsAllowedMethods)
+			w.Header().Set("Access-Control-Allow-Headers", corsAllowedHeaders)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Header().Set("Access-Control-Expose-Headers", corsExposedHeaders)
+		next.ServeHTTP(w, r)
+	})
 }
 
 // noCacheHandler sets the proper headers to prevent any sort of caching for the
@@ -1332,6 +2401,103 @@ func noCacheHandler(next http.Handler) http.Handler {
 	})
 }
 
+// readyHandler rejects the requests with a 503 until the initial poll of the
+// datacenters is over, instead of serving empty data, unless the
+// ServeBeforeReady option is enabled
+func (u *Uchiwa) readyHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u.readiness != nil && !u.Config.Uchiwa.ServeBeforeReady && !u.readiness.isReady() {
+			writeJSONError(w, http.StatusServiceUnavailable, "Data not yet available, the initial poll of the datacenters is in progress")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// staleDataHandler flags the responses with the X-Data-Stale header while the
+// last known good data is served
+func (u *Uchiwa) staleDataHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u.lockData()
+		stale := u.stale
+		u.Mu.Unlock()
+
+		if stale {
+			w.Header().Set("X-Data-Stale", "true")
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// collectionPaths contains the paths of the collection endpoints, which are
+// served by a different handler than their sub-resources
+var collectionPaths = []string{
+	"/aggregates",
+	"/checks",
+	"/clients",
+	"/datacenters",
+	"/events",
+	"/silenced",
+	"/stashes",
+	"/subscriptions",
+}
+
+// normalizePathsHandler redirects the requests to a collection endpoint with a
+// trailing slash, e.g. /clients/, to the collection endpoint itself
+func normalizePathsHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/") {
+			path := strings.TrimRight(r.URL.Path, "/")
+			if helpers.IsStringInArray(path, collectionPaths) {
+				url := *r.URL
+				url.Path = path
+				// Preserve the method of the request
+				http.Redirect(w, r, url.String(), http.StatusPermanentRedirect)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// pathLimitHandler rejects the requests whose path exceeds the provided
+// length or number of segments, before it is parsed by the handlers. A limit
+// is disabled when not positive
+func pathLimitHandler(next http.Handler, maxLength, maxSegments int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if maxLength > 0 && len(r.URL.Path) > maxLength {
+			http.Error(w, "Request path too long", http.StatusRequestURITooLong)
+			return
+		}
+
+		if maxSegments > 0 && strings.Count(r.URL.Path, "/") > maxSegments {
+			http.Error(w, "Too many segments in the request path", http.StatusRequestURITooLong)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// headHandler answers the HEAD requests with the status and headers of the
+// corresponding GET requests, including the Content-Length of their body, but
+// without the body itself
+func headHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		hw := &headResponseWriter{ResponseWriter: w}
+		next.ServeHTTP(hw, r)
+		hw.finish()
+	})
+}
+
 func securityHandler(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Add("X-Frame-Options", "DENY")
@@ -1340,55 +2506,179 @@ func securityHandler(next http.Handler) http.Handler {
 	})
 }
 
-// WebServer starts the web server and serves GET & POST requests
-func (u *Uchiwa) WebServer(publicPath *string, auth authentication.Config) {
+// newServeMux returns a new ServeMux with every route registered, so the
+// routes of each Uchiwa instance are isolated and can safely be rebuilt
+func (u *Uchiwa) newServeMux(publicPath string, auth authentication.Config) *http.ServeMux {
+	mux := http.NewServeMux()
+
+	// private wraps a handler with the in-flight counting, authentication,
+	// rate limiting, stale data and authorization middlewares
+	private := func(handler http.HandlerFunc) http.Handler {
+		return u.inFlightHandler(auth.Authenticate(u.rateLimitHandler(u.readyHandler(u.staleDataHandler(Authorization.Handler(handler))))))
+	}
+
+	// admin wraps a handler with the same middlewares as private, while also
+	// restricting the access to the administrators
+	admin := func(handler http.HandlerFunc) http.Handler {
+		return u.inFlightHandler(auth.Authenticate(u.rateLimitHandler(u.readyHandler(u.staleDataHandler(Authorization.Handler(adminHandler(handler)))))))
+	}
+
 	// Private endpoints
-	http.Handle("/aggregates", auth.Authenticate(Authorization.Handler(http.HandlerFunc(u.aggregatesHandler))))
-	http.Handle("/aggregates/", auth.Authenticate(Authorization.Handler(http.HandlerFunc(u.aggregateHandler))))
-	http.Handle("/checks", auth.Authenticate(Authorization.Handler(http.HandlerFunc(u.checksHandler))))
-	http.Handle("/checks/", auth.Authenticate(Authorization.Handler(http.HandlerFunc(u.checkHandler))))
-	http.Handle("/clients", auth.Authenticate(Authorization.Handler(http.HandlerFunc(u.clientsHandler))))
-	http.Handle("/clients/", auth.Authenticate(Authorization.Handler(http.HandlerFunc(u.clientHandler))))
-	http.Handle("/config", auth.Authenticate(Authorization.Handler(http.HandlerFunc(u.configHandler))))
-	http.Handle("/datacenters", auth.Authenticate(Authorization.Handler(http.HandlerFunc(u.datacentersHandler))))
-	http.Handle("/datacenters/", auth.Authenticate(Authorization.Handler(http.HandlerFunc(u.datacenterHandler))))
-	http.Handle("/events", auth.Authenticate(Authorization.Handler(http.HandlerFunc(u.eventsHandler))))
-	http.Handle("/events/", auth.Authenticate(Authorization.Handler(http.HandlerFunc(u.eventHandler))))
-	http.Handle("/logout", auth.Authenticate(Authorization.Handler(http.HandlerFunc(u.logoutHandler))))
-	http.Handle("/request", auth.Authenticate(Authorization.Handler(http.HandlerFunc(u.requestHandler))))
-	http.Handle("/results/", auth.Authenticate(Authorization.Handler(http.HandlerFunc(u.resultsHandler))))
-	http.Handle("/silenced", auth.Authenticate(Authorization.Handler(http.HandlerFunc(u.silencedHandler))))
-	http.Handle("/silenced/clear", auth.Authenticate(Authorization.Handler(http.HandlerFunc(u.silencedHandler))))
-	http.Handle("/stashes", auth.Authenticate(Authorization.Handler(http.HandlerFunc(u.stashesHandler))))
-	http.Handle("/stashes/", auth.Authenticate(Authorization.Handler(http.HandlerFunc(u.stashHandler))))
-	http.Handle("/subscriptions", auth.Authenticate(Authorization.Handler(http.HandlerFunc(u.subscriptionsHandler))))
-	http.Handle("/subscriptions/", auth.Authenticate(Authorization.Handler(http.HandlerFunc(u.subscriptionHandler))))
-	http.Handle("/user", auth.Authenticate(Authorization.Handler(http.HandlerFunc(u.userHandler))))
+	mux.Handle("/activity", private(u.activityHandler))
+	mux.Handle("/aggregates", private(u.aggregatesHandler))
+	mux.Handle("/aggregates/", private(u.aggregateHandler))
+	mux.Handle("/backup/", admin(u.backupHandler))
+	mux.Handle("/checks", private(u.checksHandler))
+	mux.Handle("/checks/", private(u.checkHandler))
+	mux.Handle("/checks/changed", private(u.checksChangedHandler))
+	mux.Handle("/clients", private(u.clientsHandler))
+	mux.Handle("/clients/", private(u.clientHandler))
+	mux.Handle("/config", private(u.configHandler))
+	mux.Handle("/datacenters", private(u.datacentersHandler))
+	mux.Handle("/datacenters/", private(u.datacenterHandler))
+	mux.Handle("/datacenters/freshness", private(u.datacentersFreshnessHandler))
+	mux.Handle("/datacenters/problems", private(u.datacentersProblemsHandler))
+	mux.Handle("/datacenters/ranked", private(u.datacentersRankedHandler))
+	mux.Handle("/events", private(u.eventsHandler))
+	mux.Handle("/events/", private(u.eventHandler))
+	mux.Handle("/events/bulk", u.featureHandler("bulkEvents", private(u.eventsBulkHandler)))
+	mux.Handle("/events/flapping", u.featureHandler("flappingEvents", private(u.eventsFlappingHandler)))
+	mux.Handle("/events/neverok", private(u.eventsNeverOKHandler))
+	mux.Handle("/events/resolution-stats", private(u.eventsResolutionStatsHandler))
+	mux.Handle("/events/since", private(u.eventsSinceHandler))
+	mux.Handle("/events/stream", u.featureHandler("eventsStream", private(u.streamLimitHandler(http.HandlerFunc(u.eventsStreamHandler)).ServeHTTP)))
+	mux.Handle("/events/statuses", private(u.eventsStatusesHandler))
+	mux.Handle("/logout", private(u.logoutHandler))
+	mux.Handle("/audit/activity", admin(u.auditActivityHandler))
+	mux.Handle("/audit/resource", admin(u.auditResourceHandler))
+	mux.Handle("/logs", admin(u.logsHandler))
+	mux.Handle("/metrics/runtime", admin(u.metricsRuntimeHandler))
+	mux.Handle("/request", private(u.requestHandler))
+	mux.Handle("/request/pending", u.featureHandler("pendingRequests", private(u.requestPendingHandler)))
+	mux.Handle("/results/", private(u.resultsHandler))
+	mux.Handle("/search", private(u.searchHandler))
+	mux.Handle("/silenced", private(u.silencedHandler))
+	mux.Handle("/silenced/bulk", u.featureHandler("bulkSilences", private(u.silencedBulkHandler)))
+	mux.Handle("/silenced/clear", private(u.silencedHandler))
+	mux.Handle("/silenced/expiry-histogram", private(u.silencedExpiryHistogramHandler))
+	mux.Handle("/silenced/orphaned", u.featureHandler("orphanedSilences", private(u.silencedOrphanedHandler)))
+	mux.Handle("/stashes", private(u.stashesHandler))
+	mux.Handle("/stashes/", private(u.stashHandler))
+	mux.Handle("/subscriptions", private(u.subscriptionsHandler))
+	mux.Handle("/subscriptions/", private(u.subscriptionHandler))
+	mux.Handle("/summary", private(u.summaryHandler))
+	mux.Handle("/user", private(u.userHandler))
+	mux.Handle("/user/capabilities", u.featureHandler("userCapabilities", private(u.userCapabilitiesHandler)))
 
 	if u.Config.Uchiwa.Enterprise == false {
-		http.Handle("/metrics", auth.Authenticate(Authorization.Handler(http.HandlerFunc(u.metricsHandler))))
+		mux.Handle("/metrics", private(u.metricsHandler))
+	}
+
+	// Diagnostic endpoints
+	if u.Config.Uchiwa.Debug {
+		mux.Handle("/debug/encoding", private(u.debugEncodingHandler))
 	}
 
 	// Static files
-	http.Handle("/", noCacheHandler(securityHandler(http.FileServer(http.Dir(*publicPath)))))
+	mux.Handle("/", noCacheHandler(securityHandler(http.FileServer(http.Dir(publicPath)))))
 
 	// Public endpoints
-	http.Handle("/config/", http.HandlerFunc(u.configHandler))
-	http.Handle("/health", http.HandlerFunc(u.healthHandler))
-	http.Handle("/health/", http.HandlerFunc(u.healthHandler))
-	http.Handle("/login", auth.Login())
+	mux.Handle("/config/", http.HandlerFunc(u.configHandler))
+	mux.Handle("/health", http.HandlerFunc(u.healthHandler))
+	mux.Handle("/health/", http.HandlerFunc(u.healthHandler))
+	mux.Handle("/login", auth.Login())
+
+	return mux
+}
+
+// Handler returns the handler of every route, including the CORS headers and
+// the path normalization and limits, so Uchiwa can be embedded in another
+// server. The static files are served from PublicPath and the users are
+// authenticated with Auth
+func (u *Uchiwa) Handler() http.Handler {
+	var handler http.Handler = u.newServeMux(u.PublicPath, u.Auth)
+	if u.Config.Uchiwa.NormalizePaths {
+		handler = normalizePathsHandler(handler)
+	}
+	handler = pathLimitHandler(handler, u.Config.Uchiwa.MaxPathLength, u.Config.Uchiwa.MaxPathSegments)
+	handler = corsHandler(handler, u.Config.Uchiwa.CORS.AllowedOrigins)
+	return headHandler(handler)
+}
+
+// Shutdown gracefully stops the web server started by WebServer, waiting for
+// the active connections, including the streams, until ctx is done
+func (u *Uchiwa) Shutdown(ctx context.Context) error {
+	u.serverMu.Lock()
+	server := u.server
+	u.shutdown = true
+	u.serverMu.Unlock()
+
+	// The web server will not be started
+	if server == nil {
+		return nil
+	}
+
+	return server.Shutdown(ctx)
+}
+
+// newServer returns the web server listening on the provided address. The
+// streams are ended once it shuts down, since the graceful shutdown would
+// otherwise wait for their clients to disconnect
+func (u *Uchiwa) newServer(addr string, handler http.Handler) *http.Server {
+	server := &http.Server{
+		Addr:    addr,
+		Handler: handler,
+	}
+	server.RegisterOnShutdown(u.subscribers.close)
+
+	return server
+}
+
+// WebServer starts the web server and serves GET & POST requests, until it is
+// stopped with Shutdown. It listens right away, so the health endpoints report
+// the initial poll of the datacenters while readyHandler rejects the data
+// requests until it is over
+func (u *Uchiwa) WebServer(publicPath *string, auth authentication.Config) {
+	u.PublicPath = *publicPath
+	u.Auth = auth
+	handler := u.Handler()
 
 	listen := fmt.Sprintf("%s:%d", u.Config.Uchiwa.Host, u.Config.Uchiwa.Port)
 	logger.Warningf("Uchiwa is now listening on %s", listen)
 
-	if u.Config.Uchiwa.SSL.CertFile != "" && u.Config.Uchiwa.SSL.KeyFile != "" {
-		server := http.Server{
-			Addr:         listen,
-			TLSConfig:    u.Config.Uchiwa.SSL.TLSConfig,
-			TLSNextProto: make(map[string]func(*http.Server, *tls.Conn, http.Handler), 0),
-		}
-		logger.Fatal(server.ListenAndServeTLS(u.Config.Uchiwa.SSL.CertFile, u.Config.Uchiwa.SSL.KeyFile))
+	server := u.newServer(listen, handler)
+
+	ssl := u.Config.Uchiwa.SSL.CertFile != "" && u.Config.Uchiwa.SSL.KeyFile != ""
+	if ssl {
+		server.TLSConfig = u.Config.Uchiwa.SSL.TLSConfig
+		server.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler), 0)
+	}
+
+	u.serverMu.Lock()
+	if u.shutdown {
+		u.serverMu.Unlock()
+		return
+	}
+	u.server = server
+	u.serverMu.Unlock()
+
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	// Optionally limit the concurrent connections of each remote IP
+	if u.Config.Uchiwa.MaxConnectionsPerIP > 0 {
+		listener = newIPLimitListener(listener, u.Config.Uchiwa.MaxConnectionsPerIP, parseTrustedProxies(u.Config.Uchiwa.TrustedProxies))
 	}
 
-	logger.Fatal(http.ListenAndServe(listen, nil))
+	if ssl {
+		err = server.ServeTLS(listener, u.Config.Uchiwa.SSL.CertFile, u.Config.Uchiwa.SSL.KeyFile)
+	} else {
+		err = server.Serve(listener)
+	}
+
+	if err != http.ErrServerClosed {
+		logger.Fatal(err)
+	}
 }
@@ -0,0 +1,324 @@
+package uchiwa
+
+import (
+	gocontext "context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gorilla/context"
+	"github.com/sensu/uchiwa/uchiwa/authentication"
+	"github.com/sensu/uchiwa/uchiwa/authorization"
+	"github.com/sensu/uchiwa/uchiwa/config"
+	"github.com/sensu/uchiwa/uchiwa/filters"
+	"github.com/sensu/uchiwa/uchiwa/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminHandler(t *testing.T) {
+	handler := adminHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	// Authentication disabled
+	r, _ := http.NewRequest("GET", "/logs", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Regular user
+	token := jwt.New(jwt.GetSigningMethod("RS256"))
+	token.Claims["role"] = authentication.Role{}
+	r, _ = http.NewRequest("GET", "/logs", nil)
+	context.Set(r, authentication.JWTToken, token)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Administrator
+	token.Claims["role"] = authentication.Role{Admin: true}
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestNormalizePathsHandler(t *testing.T) {
+	handler := normalizePathsHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	// Collection endpoint with a trailing slash
+	r, _ := http.NewRequest("GET", "/clients/?dc=us-east-1", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusPermanentRedirect, w.Code)
+	assert.Equal(t, "/clients?dc=us-east-1", w.Header().Get("Location"))
+
+	// Collection endpoint
+	r, _ = http.NewRequest("GET", "/clients", nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Sub-resource
+	r, _ = http.NewRequest("GET", "/clients/foo", nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Static files
+	r, _ = http.NewRequest("GET", "/", nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestPathLimitHandler(t *testing.T) {
+	handler := pathLimitHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), 20, 3)
+
+	r, _ := http.NewRequest("GET", "/clients/foo/history", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Path too long
+	r, _ = http.NewRequest("GET", "/clients/"+strings.Repeat("a", 20), nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusRequestURITooLong, w.Code)
+
+	// Too many segments
+	r, _ = http.NewRequest("GET", "/a/b/c/d", nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusRequestURITooLong, w.Code)
+
+	// Limits disabled
+	handler = pathLimitHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), 0, 0)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestReadyHandler(t *testing.T) {
+	Filters = &filters.Uchiwa{}
+	u := &Uchiwa{
+		Config:    &config.Config{},
+		Data:      &structs.Data{},
+		Mu:        &sync.Mutex{},
+		readiness: newReadiness(),
+	}
+	handler := u.readyHandler(http.HandlerFunc(u.eventsHandler))
+
+	// The initial poll is in progress
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/events", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "not yet available")
+
+	// The empty data is served meanwhile with the ServeBeforeReady option
+	u.Config.Uchiwa.ServeBeforeReady = true
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/events", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "[]\n", w.Body.String())
+
+	u.Config.Uchiwa.ServeBeforeReady = false
+	u.readiness.update(&structs.Data{})
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/events", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestNewServeMux(t *testing.T) {
+	u := &Uchiwa{
+		Config:       &config.Config{},
+		Data:         &structs.Data{Health: structs.Health{Uchiwa: "ok"}},
+		Mu:           &sync.Mutex{},
+		PublicConfig: &config.Config{},
+		rateLimiter:  &rateLimiter{},
+	}
+	auth := authentication.Config{DriverName: "none"}
+	Authorization = &authorization.Uchiwa{}
+	Filters = &filters.Uchiwa{}
+
+	// The routes can be registered more than once
+	u.newServeMux("public", auth)
+	server := httptest.NewServer(u.newServeMux("public", auth))
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/health/uchiwa")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	res.Body.Close()
+
+	res, err = http.Get(server.URL + "/events")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	res.Body.Close()
+
+	// The bulk endpoints can be disabled
+	u.Config.Uchiwa.Features = map[string]bool{"bulkEvents": false, "bulkSilences": false}
+	for _, path := range []string{"/events/bulk", "/silenced/bulk"} {
+		res, err = http.Post(server.URL+path, "application/json", strings.NewReader("{}"))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode, path)
+		res.Body.Close()
+	}
+}
+
+func TestHandler(t *testing.T) {
+	u := &Uchiwa{
+		Auth:         authentication.Config{DriverName: "none"},
+		Config:       &config.Config{Uchiwa: config.GlobalConfig{MaxPathLength: 20, NormalizePaths: true}},
+		Data:         &structs.Data{Health: structs.Health{Uchiwa: "ok"}},
+		Mu:           &sync.Mutex{},
+		PublicConfig: &config.Config{},
+		PublicPath:   "public",
+		rateLimiter:  &rateLimiter{},
+	}
+	Authorization = &authorization.Uchiwa{}
+	Filters = &filters.Uchiwa{}
+
+	handler := u.Handler()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/health/uchiwa", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The paths are normalized
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/events/", nil))
+	assert.Equal(t, http.StatusPermanentRedirect, w.Code)
+
+	// The paths are limited
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/events/"+strings.Repeat("a", 20), nil))
+	assert.Equal(t, http.StatusRequestURITooLong, w.Code)
+}
+
+func TestHeadHandler(t *testing.T) {
+	u := &Uchiwa{Config: &config.Config{}}
+	handler := headHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		u.writeJSON(w, r, http.StatusMultipleChoices, []interface{}{map[string]interface{}{"name": "foo", "dc": "us-east-1"}})
+	}))
+
+	for _, encoding := range []string{"", "gzip"} {
+		r := httptest.NewRequest("GET", "/clients/foo", nil)
+		r.Header.Set("Accept-Encoding", encoding)
+		get := httptest.NewRecorder()
+		handler.ServeHTTP(get, r)
+
+		r = httptest.NewRequest("HEAD", "/clients/foo", nil)
+		r.Header.Set("Accept-Encoding", encoding)
+		head := httptest.NewRecorder()
+		handler.ServeHTTP(head, r)
+
+		// Same status & headers, without the body
+		assert.Equal(t, http.StatusMultipleChoices, head.Code)
+		assert.Equal(t, "application/json", head.Header().Get("Content-Type"))
+		assert.Equal(t, get.Header().Get("Content-Encoding"), head.Header().Get("Content-Encoding"))
+		assert.Equal(t, strconv.Itoa(get.Body.Len()), head.Header().Get("Content-Length"))
+		assert.Equal(t, 0, head.Body.Len())
+	}
+
+	// The status is sent even without any body
+	handler = headHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("HEAD", "/", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "", w.Header().Get("Content-Length"))
+}
+
+func TestShutdown(t *testing.T) {
+	u := &Uchiwa{
+		Config:       &config.Config{Uchiwa: config.GlobalConfig{Host: "127.0.0.1"}},
+		Data:         &structs.Data{},
+		Mu:           &sync.Mutex{},
+		PublicConfig: &config.Config{},
+		rateLimiter:  &rateLimiter{},
+	}
+
+	// Nothing to stop before the web server is started
+	assert.Nil(t, u.Shutdown(gocontext.Background()))
+
+	// The web server is not started once stopped
+	publicPath := "public"
+	u.WebServer(&publicPath, authentication.Config{DriverName: "none"})
+
+	u.shutdown = false
+	stopped := make(chan struct{})
+	go func() {
+		u.WebServer(&publicPath, authentication.Config{DriverName: "none"})
+		close(stopped)
+	}()
+
+	for {
+		u.serverMu.Lock()
+		started := u.server != nil
+		u.serverMu.Unlock()
+		if started {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	assert.Nil(t, u.Shutdown(gocontext.Background()))
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("The web server was not stopped")
+	}
+}
+
+func TestCorsHandler(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "", http.StatusUnauthorized)
+	})
+
+	// No origin configured
+	handler := corsHandler(next, nil)
+	r := httptest.NewRequest("OPTIONS", "/events", nil)
+	r.Header.Set("Origin", "https://cdn.example.com")
+	r.Header.Set("Access-Control-Request-Method", "GET")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "", w.Header().Get("Access-Control-Allow-Origin"))
+
+	// Preflight request from an allowed origin
+	handler = corsHandler(next, []string{"https://cdn.example.com"})
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://cdn.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), "DELETE")
+
+	// Actual request from an allowed origin
+	r = httptest.NewRequest("GET", "/events", nil)
+	r.Header.Set("Origin", "https://cdn.example.com")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "https://cdn.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, w.Header().Get("Access-Control-Expose-Headers"), totalCountHeader)
+
+	// Another origin
+	r.Header.Set("Origin", "https://evil.example.com")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, "", w.Header().Get("Access-Control-Allow-Origin"))
+
+	// Any origin, without the credentials
+	handler = corsHandler(next, []string{"*"})
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "", w.Header().Get("Access-Control-Allow-Credentials"))
+}
@@ -1,6 +1,26 @@
 package uchiwa
 
-import "github.com/sensu/uchiwa/uchiwa/logger"
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/sensu/uchiwa/uchiwa/config"
+	"github.com/sensu/uchiwa/uchiwa/helpers"
+	"github.com/sensu/uchiwa/uchiwa/logger"
+	"github.com/sensu/uchiwa/uchiwa/structs"
+)
+
+// silenceTargetsReject is the mode of validation of the subscription & check
+// targeted by silence entries that rejects the unknown ones, which are
+// otherwise only reported in a warning
+const silenceTargetsReject = "reject"
 
 type silence struct {
 	ID              string `json:"id"`
@@ -14,6 +34,270 @@ type silence struct {
 	ExpireOnResolve bool   `json:"expire_on_resolve,omitempty"`
 }
 
+// silenceResult contains the result of the creation of a silence entry, along
+// with the unknown resources it targets, if any
+type silenceResult struct {
+	silence
+	Warnings []string `json:"warnings,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// silencingPolicy contains the rules enforced on the creation of silence
+// entries
+type silencingPolicy struct {
+	// DefaultExpiration is the expiration, in seconds, applied to the entries
+	// provided without any. Disabled when not positive
+	DefaultExpiration int32
+	// DisableNoExpiration requires either an expiration or expire_on_resolve
+	DisableNoExpiration bool
+	// MaxReasonLength is the maximum number of characters of the reasons.
+	// Disabled when not positive
+	MaxReasonLength int
+	// ReasonPattern is the regular expression the reasons must match
+	ReasonPattern string
+	// RequireSilencingReason requires a reason on every entry
+	RequireSilencingReason bool
+	// SilenceDurations contains the durations, in hours, offered by default
+	SilenceDurations []float32
+	// ValidateSilenceTargets either warns about or rejects the entries
+	// targeting an unknown subscription or check
+	ValidateSilenceTargets string
+}
+
+// newSilencingPolicy returns the silencing policy derived from the provided
+// users options
+func newSilencingPolicy(options config.UsersOptions) silencingPolicy {
+	durations := options.SilenceDurations
+	if durations == nil {
+		durations = []float32{}
+	}
+
+	return silencingPolicy{
+		DefaultExpiration:      options.DefaultSilenceExpiration,
+		DisableNoExpiration:    options.DisableNoExpiration,
+		MaxReasonLength:        options.MaxSilenceReasonLength,
+		ReasonPattern:          options.SilenceReasonPattern,
+		RequireSilencingReason: options.RequireSilencingReason,
+		SilenceDurations:       durations,
+		ValidateSilenceTargets: options.ValidateSilenceTargets,
+	}
+}
+
+// applyDefaults sets the default expiration on the provided silence entry if
+// it has none, and returns whether it was applied
+func (p silencingPolicy) applyDefaults(data *silence) bool {
+	if p.DefaultExpiration < 1 || data.Expire > 0 {
+		return false
+	}
+
+	data.Expire = p.DefaultExpiration
+	return true
+}
+
+// validate returns an error if the provided silence entry does not comply
+// with the policy
+func (p silencingPolicy) validate(data silence) error {
+	if p.DisableNoExpiration && (data.Expire < 1 && !data.ExpireOnResolve) {
+		return errors.New("Open-ended silence entries are disallowed")
+	}
+
+	if p.RequireSilencingReason && data.Reason == "" {
+		return errors.New("A reason must be provided for every silence entry")
+	}
+
+	return nil
+}
+
+// validateReason returns an error if the reason of the provided silence entry
+// is too long or does not match the pattern. An entry without any reason is
+// left to validate
+func (p silencingPolicy) validateReason(data silence) error {
+	if data.Reason == "" {
+		return nil
+	}
+
+	if p.MaxReasonLength > 0 && utf8.RuneCountInString(data.Reason) > p.MaxReasonLength {
+		return fmt.Errorf("The reason must not exceed %d characters", p.MaxReasonLength)
+	}
+
+	if p.ReasonPattern != "" {
+		pattern, err := regexp.Compile(p.ReasonPattern)
+		if err != nil {
+			return fmt.Errorf("Invalid reason pattern: %s", err)
+		}
+		if !pattern.MatchString(data.Reason) {
+			return fmt.Errorf("The reason must match the pattern '%s'", p.ReasonPattern)
+		}
+	}
+
+	return nil
+}
+
+// silenceTargetMismatches returns a message for the subscription and the
+// check of the provided silence entry that match no client or check
+// definition of its datacenter
+func silenceTargetMismatches(data silence, clients, checks, events []interface{}) []string {
+	mismatches := []string{}
+
+	if data.Subscription != "" && !subscriptionExists(data.Subscription, data.Dc, clients, checks) {
+		mismatches = append(mismatches, fmt.Sprintf("The subscription '%s' matches no client in the datacenter '%s'", data.Subscription, data.Dc))
+	}
+
+	if data.Check != "" && !checkExists(data.Check, data.Dc, checks, events) {
+		mismatches = append(mismatches, fmt.Sprintf("The check '%s' does not exist in the datacenter '%s'", data.Check, data.Dc))
+	}
+
+	return mismatches
+}
+
+// subscriptionExists reports whether a client or a check definition of the
+// provided datacenter uses the subscription. The client:<name> subscriptions
+// exist as long as the client does
+func subscriptionExists(subscription, dc string, clients, checks []interface{}) bool {
+	for _, c := range clients {
+		var client structs.GenericClient
+		if err := mapstructure.Decode(c, &client); err != nil || client.Dc != dc {
+			continue
+		}
+
+		if helpers.IsStringInArray(subscription, client.Subscriptions) {
+			return true
+		}
+
+		if strings.HasPrefix(subscription, "client:") && strings.TrimPrefix(subscription, "client:") == client.Name {
+			return true
+		}
+	}
+
+	for _, c := range checks {
+		var check structs.GenericCheck
+		if err := mapstructure.Decode(c, &check); err != nil || check.Dc != dc {
+			continue
+		}
+
+		if helpers.IsStringInArray(subscription, check.Subscribers) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkExists reports whether a check definition of the provided datacenter
+// has the provided name. Since standalone checks are only defined on the
+// clients, the checks of the current events are considered as well
+func checkExists(name, dc string, checks, events []interface{}) bool {
+	for _, c := range checks {
+		check, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if check["name"] == name && check["dc"] == dc {
+			return true
+		}
+	}
+
+	for _, e := range events {
+		event, ok := e.(map[string]interface{})
+		if !ok || event["dc"] != dc {
+			continue
+		}
+
+		check, ok := event["check"].(map[string]interface{})
+		if ok && check["name"] == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// orphanedSilences returns the active silence entries whose subscription or
+// check matches nothing in their datacenter. The entries of the datacenters
+// that could not be polled are never considered orphaned
+func orphanedSilences(silenced, clients, checks, events []interface{}, polled map[string]bool, now time.Time) []interface{} {
+	orphaned := []interface{}{}
+
+	for _, s := range silenced {
+		entry, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		data := silence{}
+		data.ID, _ = entry["id"].(string)
+		data.Dc, _ = entry["dc"].(string)
+		data.Subscription, _ = entry["subscription"].(string)
+		data.Check, _ = entry["check"].(string)
+
+		if !polled[data.Dc] {
+			continue
+		}
+		if begin, ok := entry["begin"].(float64); ok && int64(begin) > now.Unix() {
+			continue
+		}
+
+		if len(silenceTargetMismatches(data, clients, checks, events)) > 0 {
+			orphaned = append(orphaned, s)
+		}
+	}
+
+	return orphaned
+}
+
+// defaultExpiryBucket is the default size, in seconds, of the buckets of the
+// silence expiration histogram
+const defaultExpiryBucket = 3600
+
+// expiryBucket holds the number of silence entries expiring within a bucket,
+// starting at the provided Unix time
+type expiryBucket struct {
+	Start int64 `json:"start"`
+	Count int   `json:"count"`
+}
+
+// expiryHistogram holds the number of silence entries expiring per bucket,
+// and the number of entries without expiration
+type expiryHistogram struct {
+	Bucket  int64          `json:"bucket"`
+	Buckets []expiryBucket `json:"buckets"`
+	Never   int            `json:"never"`
+}
+
+// buildExpiryHistogram buckets the provided silence entries by their
+// expiration time, based on the number of seconds until they expire
+func buildExpiryHistogram(silenced []interface{}, bucket int64, now time.Time) expiryHistogram {
+	histogram := expiryHistogram{Bucket: bucket, Buckets: []expiryBucket{}}
+	counts := make(map[int64]int)
+
+	for _, s := range silenced {
+		entry, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		expire, ok := entry["expire"].(float64)
+		if !ok || expire < 0 {
+			histogram.Never++
+			continue
+		}
+
+		expiration := now.Unix() + int64(expire)
+		counts[expiration-expiration%bucket]++
+	}
+
+	for start, count := range counts {
+		histogram.Buckets = append(histogram.Buckets, expiryBucket{Start: start, Count: count})
+	}
+
+	sort.Slice(histogram.Buckets, func(i, j int) bool {
+		return histogram.Buckets[i].Start < histogram.Buckets[j].Start
+	})
+
+	return histogram
+}
+
 // ClearSilenced send a POST request to the /stashes endpoint in order to create a stash
 func (u *Uchiwa) ClearSilenced(data silence) error {
 	api, err := getAPI(u.Datacenters, data.Dc)
@@ -47,3 +331,39 @@ func (u *Uchiwa) PostSilence(data silence) error {
 
 	return nil
 }
+
+// silenceURL returns the URL identifying the provided silence entry in the
+// audit log, i.e. its datacenter and either its ID or its targets
+func silenceURL(data silence) string {
+	query := url.Values{}
+	query.Set("dc", data.Dc)
+	if data.ID != "" {
+		query.Set("id", data.ID)
+	}
+	if data.Subscription != "" {
+		query.Set("subscription", data.Subscription)
+	}
+	if data.Check != "" {
+		query.Set("check", data.Check)
+	}
+
+	return "/silenced?" + query.Encode()
+}
+
+// silenceResources returns the resources targeted by the provided silence
+// entry, for the audit log. The client:<name> subscriptions target the client
+func silenceResources(data silence) []structs.AuditResource {
+	var resources []structs.AuditResource
+
+	if strings.HasPrefix(data.Subscription, "client:") {
+		resources = append(resources, structs.AuditResource{Type: "client", Name: strings.TrimPrefix(data.Subscription, "client:"), Dc: data.Dc})
+	} else if data.Subscription != "" {
+		resources = append(resources, structs.AuditResource{Type: "subscription", Name: data.Subscription, Dc: data.Dc})
+	}
+
+	if data.Check != "" {
+		resources = append(resources, structs.AuditResource{Type: "check", Name: data.Check, Dc: data.Dc})
+	}
+
+	return resources
+}
@@ -0,0 +1,237 @@
+package uchiwa
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sensu/uchiwa/uchiwa/audit"
+	"github.com/sensu/uchiwa/uchiwa/config"
+	"github.com/sensu/uchiwa/uchiwa/filters"
+	"github.com/sensu/uchiwa/uchiwa/sensu"
+	"github.com/sensu/uchiwa/uchiwa/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSilencingPolicy(t *testing.T) {
+	// Default policy
+	policy := newSilencingPolicy(config.UsersOptions{})
+	assert.Equal(t, []float32{}, policy.SilenceDurations)
+	assert.Nil(t, policy.validate(silence{}))
+
+	policy = newSilencingPolicy(config.UsersOptions{
+		DisableNoExpiration:    true,
+		RequireSilencingReason: true,
+		SilenceDurations:       []float32{0.25, 1, 24},
+	})
+	assert.Equal(t, []float32{0.25, 1, 24}, policy.SilenceDurations)
+
+	// Open-ended entry
+	err := policy.validate(silence{Reason: "maintenance"})
+	assert.EqualError(t, err, "Open-ended silence entries are disallowed")
+
+	// Missing reason
+	err = policy.validate(silence{Expire: 3600})
+	assert.EqualError(t, err, "A reason must be provided for every silence entry")
+
+	// Valid entries
+	assert.Nil(t, policy.validate(silence{Expire: 3600, Reason: "maintenance"}))
+	assert.Nil(t, policy.validate(silence{ExpireOnResolve: true, Reason: "maintenance"}))
+
+	// Default expiration
+	data := silence{Reason: "maintenance"}
+	assert.False(t, policy.applyDefaults(&data))
+	assert.Equal(t, int32(0), data.Expire)
+
+	policy = newSilencingPolicy(config.UsersOptions{DefaultSilenceExpiration: 7200, DisableNoExpiration: true})
+	assert.True(t, policy.applyDefaults(&data))
+	assert.Equal(t, int32(7200), data.Expire)
+	assert.Nil(t, policy.validate(data))
+
+	// The provided expiration is kept
+	data = silence{Expire: 60}
+	assert.False(t, policy.applyDefaults(&data))
+	assert.Equal(t, int32(60), data.Expire)
+}
+
+func TestSilenceTargetMismatches(t *testing.T) {
+	clients := []interface{}{
+		map[string]interface{}{"name": "foo", "dc": "us-east-1", "subscriptions": []interface{}{"linux"}},
+		map[string]interface{}{"name": "bar", "dc": "us-west-1", "subscriptions": []interface{}{"windows"}},
+	}
+	checks := []interface{}{
+		map[string]interface{}{"name": "cpu", "dc": "us-east-1", "subscribers": []interface{}{"web"}},
+	}
+	events := []interface{}{
+		map[string]interface{}{"dc": "us-east-1", "check": map[string]interface{}{"name": "disk"}},
+	}
+
+	valid := []silence{
+		{Dc: "us-east-1", Subscription: "linux"},
+		{Dc: "us-east-1", Subscription: "web"},
+		{Dc: "us-east-1", Subscription: "client:foo"},
+		{Dc: "us-east-1", Check: "cpu"},
+		{Dc: "us-east-1", Check: "disk"},
+		{Dc: "us-east-1", Subscription: "linux", Check: "cpu"},
+	}
+	for _, s := range valid {
+		assert.Equal(t, []string{}, silenceTargetMismatches(s, clients, checks, events))
+	}
+
+	mismatches := silenceTargetMismatches(silence{Dc: "us-east-1", Subscription: "windows", Check: "mem"}, clients, checks, events)
+	assert.Equal(t, []string{
+		"The subscription 'windows' matches no client in the datacenter 'us-east-1'",
+		"The check 'mem' does not exist in the datacenter 'us-east-1'",
+	}, mismatches)
+
+	mismatches = silenceTargetMismatches(silence{Dc: "us-west-1", Subscription: "client:foo", Check: "cpu"}, clients, checks, events)
+	assert.Equal(t, 2, len(mismatches))
+}
+
+func TestOrphanedSilences(t *testing.T) {
+	clients := []interface{}{
+		map[string]interface{}{"name": "foo", "dc": "us-east-1", "subscriptions": []interface{}{"linux"}},
+	}
+	checks := []interface{}{
+		map[string]interface{}{"name": "cpu", "dc": "us-east-1"},
+	}
+	silenced := []interface{}{
+		map[string]interface{}{"id": "linux:*", "dc": "us-east-1", "subscription": "linux"},
+		map[string]interface{}{"id": "client:bar:*", "dc": "us-east-1", "subscription": "client:bar"},
+		map[string]interface{}{"id": "*:mem", "dc": "us-east-1", "check": "mem"},
+		map[string]interface{}{"id": "client:foo:cpu", "dc": "us-east-1", "subscription": "client:foo", "check": "cpu"},
+		map[string]interface{}{"id": "*:disk", "dc": "us-east-1", "check": "disk", "begin": float64(2000)},
+		map[string]interface{}{"id": "*:mem", "dc": "us-west-1", "check": "mem"},
+	}
+	polled := map[string]bool{"us-east-1": true}
+
+	orphaned := orphanedSilences(silenced, clients, checks, nil, polled, time.Unix(1000, 0))
+	assert.Equal(t, []interface{}{silenced[1], silenced[2]}, orphaned)
+}
+
+func TestBuildExpiryHistogram(t *testing.T) {
+	now := time.Unix(7200, 0)
+	silenced := []interface{}{
+		map[string]interface{}{"id": "a", "expire": float64(60)},
+		map[string]interface{}{"id": "b", "expire": float64(3599)},
+		map[string]interface{}{"id": "c", "expire": float64(3600)},
+		map[string]interface{}{"id": "d", "expire": float64(-1)},
+		map[string]interface{}{"id": "e"},
+		"foo",
+	}
+
+	histogram := buildExpiryHistogram(silenced, 3600, now)
+	assert.Equal(t, int64(3600), histogram.Bucket)
+	assert.Equal(t, 2, histogram.Never)
+	assert.Equal(t, []expiryBucket{{Start: 7200, Count: 2}, {Start: 10800, Count: 1}}, histogram.Buckets)
+
+	histogram = buildExpiryHistogram([]interface{}{}, 60, now)
+	assert.Equal(t, []expiryBucket{}, histogram.Buckets)
+	assert.Equal(t, 0, histogram.Never)
+}
+
+func TestSilenceURL(t *testing.T) {
+	assert.Equal(t, "/silenced?check=disk&dc=us-east-1&subscription=client%3Afoo", silenceURL(silence{Dc: "us-east-1", Subscription: "client:foo", Check: "disk"}))
+	assert.Equal(t, "/silenced?dc=us-east-1&id=%2A%3Adisk", silenceURL(silence{Dc: "us-east-1", ID: "*:disk"}))
+}
+
+func TestSilenceResources(t *testing.T) {
+	resources := silenceResources(silence{Dc: "us-east-1", Subscription: "client:foo", Check: "disk"})
+	assert.Equal(t, []structs.AuditResource{
+		{Type: "client", Name: "foo", Dc: "us-east-1"},
+		{Type: "check", Name: "disk", Dc: "us-east-1"},
+	}, resources)
+
+	resources = silenceResources(silence{Dc: "us-east-1", Subscription: "linux"})
+	assert.Equal(t, []structs.AuditResource{{Type: "subscription", Name: "linux", Dc: "us-east-1"}}, resources)
+}
+
+func TestValidateReason(t *testing.T) {
+	// Not configured
+	policy := newSilencingPolicy(config.UsersOptions{})
+	assert.Nil(t, policy.validateReason(silence{Reason: strings.Repeat("a", 1000)}))
+
+	policy = newSilencingPolicy(config.UsersOptions{MaxSilenceReasonLength: 20, SilenceReasonPattern: `[A-Z]+-[0-9]+`})
+	assert.Nil(t, policy.validateReason(silence{Reason: "Deploying OPS-1234"}))
+	assert.Nil(t, policy.validateReason(silence{Reason: "Déploiement OPS-12"}))
+
+	// Missing reasons are left to the other rules
+	assert.Nil(t, policy.validateReason(silence{}))
+
+	err := policy.validateReason(silence{Reason: "Deploying the new release OPS-1234"})
+	assert.EqualError(t, err, "The reason must not exceed 20 characters")
+
+	err = policy.validateReason(silence{Reason: "Deploying"})
+	assert.EqualError(t, err, "The reason must match the pattern '[A-Z]+-[0-9]+'")
+}
+
+func TestSilencedBulkHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	var logs []structs.AuditLog
+	audit.Log = func(log structs.AuditLog) error {
+		logs = append(logs, log)
+		return nil
+	}
+	defer func() { audit.Log = nil }()
+
+	api := sensu.API{URL: server.URL, Timeout: 1}
+	api.Init()
+	Filters = &filters.Uchiwa{}
+	u := &Uchiwa{
+		Config:      &config.Config{},
+		Data:        &structs.Data{},
+		Datacenters: &[]sensu.Sensu{{Name: "us-east-1", APIs: []sensu.API{api}}},
+		Mu:          &sync.Mutex{},
+	}
+	u.Config.Uchiwa.UsersOptions.DisableNoExpiration = true
+	u.Config.Uchiwa.UsersOptions.RequireSilencingReason = true
+
+	body := `[{"dc":"us-east-1","check":"cpu","expire":3600,"reason":"maintenance"},{"dc":"us-east-1","check":"disk","reason":"maintenance"},{"dc":"us-east-1","check":"ram","expire":3600}]`
+	r, _ := http.NewRequest("POST", "/silenced/bulk", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	u.silencedBulkHandler(w, r)
+	assert.Equal(t, http.StatusMultiStatus, w.Code)
+
+	var results []silenceResult
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &results))
+	assert.Equal(t, 3, len(results))
+	assert.Equal(t, "", results[0].Error)
+	assert.Equal(t, "cpu", results[0].Check)
+	assert.Equal(t, "Unknown", results[0].Creator)
+	assert.Equal(t, "Open-ended silence entries are disallowed", results[1].Error)
+	assert.Equal(t, "A reason must be provided for every silence entry", results[2].Error)
+
+	// Only the successful creation is audited
+	assert.Equal(t, 1, len(logs))
+	assert.Equal(t, "silence", logs[0].Action)
+	assert.Equal(t, "/silenced?check=cpu&dc=us-east-1", logs[0].URL)
+
+	// The datacenter names are normalized
+	logs = nil
+	r, _ = http.NewRequest("POST", "/silenced/bulk", strings.NewReader(`[{"dc":"US-East-1","check":"cpu","expire":3600,"reason":"maintenance"},{"dc":"us-west-1","check":"cpu","expire":3600,"reason":"maintenance"}]`))
+	r.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	u.silencedBulkHandler(w, r)
+	results = nil
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &results))
+	assert.Equal(t, "us-east-1", results[0].Dc)
+	assert.Equal(t, "", results[0].Error)
+	assert.Equal(t, "Could not find the datacenter 'us-west-1'", results[1].Error)
+	assert.Equal(t, "/silenced?check=cpu&dc=us-east-1", logs[0].URL)
+
+	// Every entry created
+	r, _ = http.NewRequest("POST", "/silenced/bulk", strings.NewReader(`[{"dc":"us-east-1","check":"cpu","expire":3600,"reason":"maintenance"}]`))
+	r.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	u.silencedBulkHandler(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -13,6 +13,23 @@ type stash struct {
 	Expire  int32                  `json:"expire,omitempty"`
 }
 
+// stashExists reports whether a stash exists at the provided path of the
+// provided datacenter
+func stashExists(stashes []interface{}, dc, path string) bool {
+	for _, s := range stashes {
+		m, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if m["dc"] == dc && m["path"] == path {
+			return true
+		}
+	}
+
+	return false
+}
+
 // PostStash send a POST request to the /stashes endpoint in order to create a stash
 func (u *Uchiwa) PostStash(data stash) error {
 	api, err := getAPI(u.Datacenters, data.Dc)
@@ -1,8 +1,14 @@
 package uchiwa
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/sensu/uchiwa/uchiwa/config"
+	"github.com/sensu/uchiwa/uchiwa/filters"
 	"github.com/sensu/uchiwa/uchiwa/structs"
 	"github.com/stretchr/testify/assert"
 )
@@ -34,3 +40,36 @@ func TestFindStash(t *testing.T) {
 	_, err = u.findCheck("qux")
 	assert.NotNil(t, err)
 }
+
+func TestStashExists(t *testing.T) {
+	stashes := []interface{}{
+		map[string]interface{}{"dc": "us-east-1", "path": "silence/foo"},
+		"foo",
+	}
+
+	assert.True(t, stashExists(stashes, "us-east-1", "silence/foo"))
+	assert.False(t, stashExists(stashes, "us-west-1", "silence/foo"))
+	assert.False(t, stashExists(stashes, "us-east-1", "silence/bar"))
+}
+
+func TestStashesHandlerPreventOverwrite(t *testing.T) {
+	Filters = &filters.Uchiwa{}
+	u := &Uchiwa{
+		Config: &config.Config{Uchiwa: config.GlobalConfig{UsersOptions: config.UsersOptions{PreventStashOverwrite: true}}},
+		Data:   &structs.Data{Stashes: []interface{}{map[string]interface{}{"dc": "us-east-1", "path": "silence/foo"}}},
+		Mu:     &sync.Mutex{},
+	}
+	body := `{"dc":"us-east-1","path":"silence/foo","content":{}}`
+
+	r, _ := http.NewRequest("POST", "/stashes", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	u.stashesHandler(w, r)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	r, _ = http.NewRequest("POST", "/stashes?overwrite=foo", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	u.stashesHandler(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,211 @@
+package uchiwa
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/sensu/uchiwa/uchiwa/authentication"
+	"github.com/sensu/uchiwa/uchiwa/logger"
+)
+
+// streamKeepalive is the interval at which a comment is sent on the streams,
+// so the idle connections are not closed by the proxies
+var streamKeepalive = 30 * time.Second
+
+// streamLimiter counts the open streaming connections, e.g. SSE or WebSocket
+type streamLimiter struct {
+	mutex sync.Mutex
+	count int
+}
+
+// acquire records a new connection and returns false if the provided limit
+// of connections is already reached. A limit below 1 means no limit
+func (l *streamLimiter) acquire(limit int) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if limit > 0 && l.count >= limit {
+		return false
+	}
+
+	l.count++
+	return true
+}
+
+// release records the closing of a connection
+func (l *streamLimiter) release() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.count > 0 {
+		l.count--
+	}
+}
+
+// open returns the number of open connections
+func (l *streamLimiter) open() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	return l.count
+}
+
+// streamLimitHandler rejects the streaming connections beyond the
+// MaxStreamConnections limit. The wrapped handler must only return once the
+// stream is closed, so the connection is counted down whenever it returns,
+// including when the client goes away or the handler panics
+func (u *Uchiwa) streamLimitHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !u.streams.acquire(u.Config.Uchiwa.MaxStreamConnections) {
+			logger.Debugf("Rejecting the stream from %s, too many open connections", r.RemoteAddr)
+			http.Error(w, "Too many open streams", http.StatusServiceUnavailable)
+			return
+		}
+		defer u.streams.release()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// dataSubscribers notifies the streams whenever the data is refreshed. Each
+// subscriber has a channel buffered to a single notification, so the refresh
+// never waits for a slow consumer and the notifications it missed are
+// coalesced into one. The done channel is closed once the streams must end,
+// e.g. when the web server shuts down
+type dataSubscribers struct {
+	mutex       sync.Mutex
+	subscribers map[chan struct{}]bool
+	done        chan struct{}
+	once        sync.Once
+}
+
+func newDataSubscribers() *dataSubscribers {
+	return &dataSubscribers{
+		subscribers: make(map[chan struct{}]bool),
+		done:        make(chan struct{}),
+	}
+}
+
+// close ends every stream, including the ones opened afterwards
+func (s *dataSubscribers) close() {
+	if s == nil {
+		return
+	}
+
+	s.once.Do(func() { close(s.done) })
+}
+
+// subscribe returns a channel notified whenever the data is refreshed
+func (s *dataSubscribers) subscribe() chan struct{} {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ch := make(chan struct{}, 1)
+	s.subscribers[ch] = true
+	return ch
+}
+
+// unsubscribe stops notifying the provided channel
+func (s *dataSubscribers) unsubscribe(ch chan struct{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.subscribers, ch)
+}
+
+// notify notifies every subscriber without blocking. A subscriber that was
+// not done with its previous notification is not notified again
+func (s *dataSubscribers) notify() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for ch := range s.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// writeEventsMessage writes the events visible to the user as a Server-Sent
+// Event, identified by the refresh generation of the data. The mutex is only
+// held while the events are filtered, never while the message is written
+func (u *Uchiwa) writeEventsMessage(w http.ResponseWriter, token *jwt.Token) error {
+	u.lockData()
+	events := Filters.Events(&u.Data.Events, token)
+	u.Mu.Unlock()
+
+	if events == nil {
+		events = make([]interface{}, 0)
+	}
+
+	b, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: events\ndata: %s\n\n", atomic.LoadUint64(&u.dataVersion), b)
+	return err
+}
+
+// eventsStreamHandler serves the /events/stream endpoint, which pushes the
+// events visible to the user as Server-Sent Events, right away and then
+// whenever the data is refreshed, until the client disconnects or the web
+// server shuts down
+func (u *Uchiwa) eventsStreamHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "", http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	token := authentication.GetJWTFromContext(r)
+
+	updates := u.subscribers.subscribe()
+	defer u.subscribers.unsubscribe(updates)
+
+	// Create header
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if err := u.writeEventsMessage(w, token); err != nil {
+		logger.Warningf("Cannot write the events to the stream: %v", err)
+		return
+	}
+	flusher.Flush()
+
+	keepalive := time.NewTicker(streamKeepalive)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-u.subscribers.done:
+			return
+		case <-updates:
+			if err := u.writeEventsMessage(w, token); err != nil {
+				logger.Debugf("Closing the events stream of %s: %v", r.RemoteAddr, err)
+				return
+			}
+		case <-keepalive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				logger.Debugf("Closing the events stream of %s: %v", r.RemoteAddr, err)
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
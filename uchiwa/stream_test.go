@@ -0,0 +1,178 @@
+package uchiwa
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sensu/uchiwa/uchiwa/config"
+	"github.com/sensu/uchiwa/uchiwa/filters"
+	"github.com/sensu/uchiwa/uchiwa/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamLimiter(t *testing.T) {
+	l := &streamLimiter{}
+
+	assert.True(t, l.acquire(2))
+	assert.True(t, l.acquire(2))
+	assert.False(t, l.acquire(2))
+
+	l.release()
+	assert.True(t, l.acquire(2))
+
+	// No limit
+	assert.True(t, l.acquire(0))
+	assert.Equal(t, 3, l.count)
+
+	// The count never goes below zero
+	l = &streamLimiter{}
+	l.release()
+	assert.Equal(t, 0, l.count)
+}
+
+func TestStreamLimitHandler(t *testing.T) {
+	u := &Uchiwa{
+		Config:  &config.Config{Uchiwa: config.GlobalConfig{MaxStreamConnections: 1}},
+		streams: &streamLimiter{},
+	}
+
+	open := make(chan struct{})
+	done := make(chan struct{})
+	handler := u.streamLimitHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		open <- struct{}{}
+		<-done
+	}))
+
+	// Keep a first stream open
+	closed := make(chan struct{})
+	go func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/events/stream", nil))
+		close(closed)
+	}()
+	<-open
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/events/stream", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	// The connection is released once the stream is closed
+	close(done)
+	<-closed
+	assert.Equal(t, 0, u.streams.count)
+
+	// A panicking stream releases its connection too
+	u.streams = &streamLimiter{}
+	panicking := u.streamLimitHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("connection reset")
+	}))
+	assert.Panics(t, func() {
+		panicking.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/events/stream", nil))
+	})
+	assert.Equal(t, 0, u.streams.count)
+}
+
+func TestDataSubscribers(t *testing.T) {
+	s := newDataSubscribers()
+	ch := s.subscribe()
+
+	// The notifications are coalesced instead of blocking
+	s.notify()
+	s.notify()
+	assert.Equal(t, 1, len(ch))
+
+	<-ch
+	s.unsubscribe(ch)
+	s.notify()
+	assert.Equal(t, 0, len(ch))
+}
+
+func TestEventsStreamHandler(t *testing.T) {
+	Filters = &filters.Uchiwa{}
+	u := &Uchiwa{
+		Data:        &structs.Data{Events: []interface{}{map[string]interface{}{"_id": "us-east-1/foo/cpu"}}},
+		Mu:          &sync.Mutex{},
+		subscribers: newDataSubscribers(),
+	}
+
+	keepalive := streamKeepalive
+	streamKeepalive = 10 * time.Millisecond
+	defer func() { streamKeepalive = keepalive }()
+
+	server := httptest.NewServer(http.HandlerFunc(u.eventsStreamHandler))
+	defer server.Close()
+
+	res, err := http.Get(server.URL)
+	assert.Nil(t, err)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+	reader := bufio.NewReader(res.Body)
+
+	readMessage := func() string {
+		var message string
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil || line == "\n" {
+				return message
+			}
+			message += line
+		}
+	}
+
+	// The current events are sent right away
+	assert.Equal(t, "id: 0\nevent: events\ndata: [{\"_id\":\"us-east-1/foo/cpu\"}]\n", readMessage())
+
+	// Then whenever the data is refreshed
+	u.Mu.Lock()
+	u.Data = &structs.Data{}
+	u.Mu.Unlock()
+	u.subscribers.notify()
+
+	message := readMessage()
+	for message == ": keepalive\n" {
+		message = readMessage()
+	}
+	assert.Equal(t, "id: 0\nevent: events\ndata: []\n", message)
+
+	// The subscription is removed once the client disconnects
+	res.Body.Close()
+	for i := 0; i < 100; i++ {
+		u.subscribers.mutex.Lock()
+		count := len(u.subscribers.subscribers)
+		u.subscribers.mutex.Unlock()
+		if count == 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, 0, len(u.subscribers.subscribers))
+}
+
+func TestEventsStreamShutdown(t *testing.T) {
+	Filters = &filters.Uchiwa{}
+	u := &Uchiwa{
+		Data:        &structs.Data{},
+		Mu:          &sync.Mutex{},
+		subscribers: newDataSubscribers(),
+	}
+
+	server := httptest.NewUnstartedServer(nil)
+	server.Config = u.newServer("", http.HandlerFunc(u.eventsStreamHandler))
+	server.Start()
+	defer server.Close()
+
+	res, err := http.Get(server.URL)
+	assert.Nil(t, err)
+	defer res.Body.Close()
+	line, err := bufio.NewReader(res.Body).ReadString('\n')
+	assert.Nil(t, err)
+	assert.Equal(t, "id: 0\n", line)
+
+	// The open stream does not hold the graceful shutdown
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	assert.Nil(t, server.Config.Shutdown(ctx))
+}
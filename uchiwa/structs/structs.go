@@ -4,21 +4,41 @@ import "time"
 
 // AuditLog is a structure for holding a log of the audit
 type AuditLog struct {
-	Date       time.Time `json:"date"`
-	Action     string    `json:"action"`
-	Level      string    `json:"level"`
-	Output     string    `json:"output,omitempty"`
-	RemoteAddr string    `json:"remoteaddr"`
-	URL        string    `json:"url,omitempty"`
-	User       string    `json:"user"`
+	Date       time.Time       `json:"date"`
+	Action     string          `json:"action"`
+	Level      string          `json:"level"`
+	Output     string          `json:"output,omitempty"`
+	RemoteAddr string          `json:"remoteaddr"`
+	Resources  []AuditResource `json:"resources,omitempty"`
+	URL        string          `json:"url,omitempty"`
+	User       string          `json:"user"`
+}
+
+// AuditResource identifies a resource, e.g. a client or a check, targeted by
+// an audited action
+type AuditResource struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	Dc   string `json:"dc,omitempty"`
 }
 
 // Auth struct contains the generic configuration and details
 // about the authentication
 type Auth struct {
-	Driver     string
-	PrivateKey string
-	PublicKey  string
+	Driver           string
+	ExpectedAudience string
+	ExpectedIssuer   string
+	PrivateKey       string
+	PublicKey        string
+	UsernameClaim    string
+}
+
+// CheckSample is a structure for holding the outcome of a single check execution
+type CheckSample struct {
+	Client   string  `json:"client"`
+	Duration float64 `json:"duration"`
+	Executed int64   `json:"executed"`
+	Status   int     `json:"status"`
 }
 
 // CheckExecution struct contains the payload for issuing a
@@ -33,10 +53,12 @@ type CheckExecution struct {
 type Data struct {
 	Aggregates    []interface{}
 	Checks        []interface{}
+	CheckSamples  map[string][]CheckSample `json:"-"`
 	Clients       []interface{}
 	Dc            []*Datacenter
 	Events        []interface{}
 	Health        Health
+	LastPoll      map[string]int64
 	Metrics       Metrics
 	SEMetrics     SEMetrics
 	SERawMetrics  SERawMetrics `json:"-"`
@@ -48,6 +70,7 @@ type Data struct {
 // Datacenter is a structure for holding the information about a datacenter
 type Datacenter struct {
 	Name    string         `json:"name"`
+	Alias   string         `json:"alias,omitempty"`
 	Info    Info           `json:"info"`
 	Metrics map[string]int `json:"metrics"`
 }
@@ -82,6 +105,7 @@ type GenericEvent struct {
 // Health is a structure for holding health informaton about Sensu & Uchiwa
 type Health struct {
 	Sensu  map[string]SensuHealth `json:"sensu"`
+	Stale  bool                   `json:"stale,omitempty"`
 	Uchiwa string                 `json:"uchiwa"`
 }
 
@@ -89,6 +113,8 @@ type Health struct {
 type SensuHealth struct {
 	Output string `json:"output"`
 	Status int    `json:"status"`
+	// Interval is the polling interval of the datacenter, in seconds
+	Interval int `json:"interval,omitempty"`
 }
 
 // Info is a structure for holding the /info API information
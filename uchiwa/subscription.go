@@ -0,0 +1,218 @@
+package uchiwa
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/sensu/uchiwa/uchiwa/helpers"
+	"github.com/sensu/uchiwa/uchiwa/logger"
+	"github.com/sensu/uchiwa/uchiwa/structs"
+)
+
+const (
+	coverageAll  = "all"
+	coverageNone = "none"
+	coverageSome = "some"
+)
+
+// checkCoverage holds which clients of a subscription run a given check
+type checkCoverage struct {
+	Check    string   `json:"check"`
+	Dc       string   `json:"dc"`
+	Coverage string   `json:"coverage"`
+	Missing  []string `json:"missing"`
+	Running  []string `json:"running"`
+}
+
+// subscriptionCoverage holds the coverage of the checks expected to be run by
+// the clients of a subscription
+type subscriptionCoverage struct {
+	Subscription string          `json:"subscription"`
+	Clients      int             `json:"clients"`
+	Checks       []checkCoverage `json:"checks"`
+}
+
+// subscriptionClients returns the clients that are members of the provided
+// subscription
+func subscriptionClients(subscription string, clients []interface{}) []structs.GenericClient {
+	var members []structs.GenericClient
+	for _, c := range clients {
+		var client structs.GenericClient
+		if err := mapstructure.Decode(c, &client); err != nil {
+			logger.Debug(err)
+			continue
+		}
+
+		if helpers.IsStringInArray(subscription, client.Subscriptions) {
+			members = append(members, client)
+		}
+	}
+
+	return members
+}
+
+// subscriptionEvents returns the events of the clients that are members of the
+// provided subscription
+func subscriptionEvents(subscription string, clients, events []interface{}) []interface{} {
+	members := make(map[string]bool)
+	for _, client := range subscriptionClients(subscription, clients) {
+		members[client.Dc+"/"+client.Name] = true
+	}
+
+	result := make([]interface{}, 0)
+	for _, e := range events {
+		var event structs.GenericEvent
+		if err := mapstructure.Decode(e, &event); err != nil {
+			logger.Debug(err)
+			continue
+		}
+
+		if members[event.Dc+"/"+event.Client.Name] {
+			result = append(result, e)
+		}
+	}
+
+	return result
+}
+
+// historyChecks returns the name of every check found in a client history
+func historyChecks(history []interface{}) []string {
+	var checks []string
+	for _, h := range history {
+		m, ok := h.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if name, ok := m["check"].(string); ok {
+			checks = append(checks, name)
+		}
+	}
+
+	return checks
+}
+
+// buildSubscriptionCoverage reports, for every check definition subscribed to
+// the provided subscription, which of the subscription's clients run it,
+// according to the check names found in the results of each client. The
+// results are keyed by datacenter and client name
+func buildSubscriptionCoverage(subscription string, clients, checks []interface{}, results map[string]map[string][]string) subscriptionCoverage {
+	members := subscriptionClients(subscription, clients)
+
+	coverage := subscriptionCoverage{
+		Subscription: subscription,
+		Clients:      len(members),
+		Checks:       []checkCoverage{},
+	}
+
+	for _, c := range checks {
+		check, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name, ok := check["name"].(string)
+		if !ok {
+			continue
+		}
+
+		var generic structs.GenericCheck
+		if err := mapstructure.Decode(check, &generic); err != nil {
+			logger.Debug(err)
+			continue
+		}
+
+		if !helpers.IsStringInArray(subscription, generic.Subscribers) {
+			continue
+		}
+
+		result := checkCoverage{
+			Check:   name,
+			Dc:      generic.Dc,
+			Missing: []string{},
+			Running: []string{},
+		}
+
+		for _, client := range members {
+			if client.Dc != generic.Dc {
+				continue
+			}
+
+			if helpers.IsStringInArray(name, results[client.Dc][client.Name]) {
+				result.Running = append(result.Running, client.Name)
+			} else {
+				result.Missing = append(result.Missing, client.Name)
+			}
+		}
+
+		switch {
+		case len(result.Missing) == 0 && len(result.Running) > 0:
+			result.Coverage = coverageAll
+		case len(result.Running) == 0:
+			result.Coverage = coverageNone
+		default:
+			result.Coverage = coverageSome
+		}
+
+		coverage.Checks = append(coverage.Checks, result)
+	}
+
+	sort.Slice(coverage.Checks, func(i, j int) bool {
+		if coverage.Checks[i].Dc != coverage.Checks[j].Dc {
+			return coverage.Checks[i].Dc < coverage.Checks[j].Dc
+		}
+		return coverage.Checks[i].Check < coverage.Checks[j].Check
+	})
+
+	return coverage
+}
+
+// retainedResults returns the name of the checks with a result retained in
+// memory for each client, keyed by datacenter and client name. These are the
+// checks of the events and, when the sampling is enabled, of the check samples
+func retainedResults(events []interface{}, samples map[string][]structs.CheckSample) map[string]map[string][]string {
+	results := make(map[string]map[string][]string)
+	add := func(dc, client, check string) {
+		if results[dc] == nil {
+			results[dc] = make(map[string][]string)
+		}
+		if !helpers.IsStringInArray(check, results[dc][client]) {
+			results[dc][client] = append(results[dc][client], check)
+		}
+	}
+
+	for _, e := range events {
+		event, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		dc, _ := event["dc"].(string)
+		client, _ := nestedField(event, []string{"client", "name"})
+		check, _ := nestedField(event, []string{"check", "name"})
+		clientName, ok := client.(string)
+		if !ok {
+			continue
+		}
+		checkName, ok := check.(string)
+		if !ok {
+			continue
+		}
+
+		add(dc, clientName, checkName)
+	}
+
+	// The samples are keyed by datacenter and check name
+	for key, checkSamples := range samples {
+		i := strings.LastIndex(key, "/")
+		if i < 0 {
+			continue
+		}
+		for _, sample := range checkSamples {
+			add(key[:i], sample.Client, key[i+1:])
+		}
+	}
+
+	return results
+}
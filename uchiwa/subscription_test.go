@@ -0,0 +1,92 @@
+package uchiwa
+
+import (
+	"testing"
+
+	"github.com/sensu/uchiwa/uchiwa/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildSubscriptionCoverage(t *testing.T) {
+	clients := []interface{}{
+		map[string]interface{}{"dc": "us-east-1", "name": "foo", "subscriptions": []interface{}{"linux"}},
+		map[string]interface{}{"dc": "us-east-1", "name": "bar", "subscriptions": []interface{}{"linux", "web"}},
+		map[string]interface{}{"dc": "us-east-1", "name": "qux", "subscriptions": []interface{}{"web"}},
+	}
+	checks := []interface{}{
+		map[string]interface{}{"dc": "us-east-1", "name": "disk", "subscribers": []interface{}{"linux"}},
+		map[string]interface{}{"dc": "us-east-1", "name": "cpu", "subscribers": []interface{}{"linux"}},
+		map[string]interface{}{"dc": "us-east-1", "name": "load", "subscribers": []interface{}{"linux"}},
+		map[string]interface{}{"dc": "us-east-1", "name": "nginx", "subscribers": []interface{}{"web"}},
+	}
+	results := map[string]map[string][]string{
+		"us-east-1": {
+			"foo": {"disk", "cpu"},
+			"bar": {"disk", "nginx"},
+		},
+	}
+
+	coverage := buildSubscriptionCoverage("linux", clients, checks, results)
+	assert.Equal(t, "linux", coverage.Subscription)
+	assert.Equal(t, 2, coverage.Clients)
+	assert.Equal(t, 3, len(coverage.Checks))
+
+	assert.Equal(t, "cpu", coverage.Checks[0].Check)
+	assert.Equal(t, coverageSome, coverage.Checks[0].Coverage)
+	assert.Equal(t, []string{"foo"}, coverage.Checks[0].Running)
+	assert.Equal(t, []string{"bar"}, coverage.Checks[0].Missing)
+
+	assert.Equal(t, "disk", coverage.Checks[1].Check)
+	assert.Equal(t, coverageAll, coverage.Checks[1].Coverage)
+
+	assert.Equal(t, "load", coverage.Checks[2].Check)
+	assert.Equal(t, coverageNone, coverage.Checks[2].Coverage)
+	assert.Equal(t, []string{}, coverage.Checks[2].Running)
+
+	// Unknown subscription
+	coverage = buildSubscriptionCoverage("windows", clients, checks, results)
+	assert.Equal(t, 0, coverage.Clients)
+	assert.Equal(t, 0, len(coverage.Checks))
+}
+
+func TestRetainedResults(t *testing.T) {
+	events := []interface{}{
+		map[string]interface{}{"dc": "us-east-1", "client": map[string]interface{}{"name": "foo"}, "check": map[string]interface{}{"name": "cpu"}},
+		map[string]interface{}{"dc": "us-east-1", "client": map[string]interface{}{"name": "foo"}, "check": map[string]interface{}{"name": "disk"}},
+		map[string]interface{}{"dc": "us-west-1", "client": "foo", "check": "cpu"},
+	}
+	samples := map[string][]structs.CheckSample{
+		"us-east-1/cpu":  {{Client: "foo"}, {Client: "bar"}},
+		"us-west-1/load": {{Client: "qux"}},
+	}
+
+	results := retainedResults(events, samples)
+	assert.Equal(t, []string{"cpu", "disk"}, results["us-east-1"]["foo"])
+	assert.Equal(t, []string{"cpu"}, results["us-east-1"]["bar"])
+	assert.Equal(t, []string{"load"}, results["us-west-1"]["qux"])
+	assert.Equal(t, 0, len(results["us-west-1"]["foo"]))
+}
+
+func TestSubscriptionEvents(t *testing.T) {
+	clients := []interface{}{
+		map[string]interface{}{"name": "foo", "dc": "us-east-1", "subscriptions": []interface{}{"team/web"}},
+		map[string]interface{}{"name": "bar", "dc": "us-east-1", "subscriptions": []interface{}{"db"}},
+		map[string]interface{}{"name": "foo", "dc": "us-west-1", "subscriptions": []interface{}{"db"}},
+	}
+	events := []interface{}{
+		map[string]interface{}{"dc": "us-east-1", "client": map[string]interface{}{"name": "foo"}, "check": map[string]interface{}{"name": "cpu"}},
+		map[string]interface{}{"dc": "us-east-1", "client": map[string]interface{}{"name": "bar"}, "check": map[string]interface{}{"name": "cpu"}},
+		map[string]interface{}{"dc": "us-west-1", "client": map[string]interface{}{"name": "foo"}, "check": map[string]interface{}{"name": "disk"}},
+		"foo",
+	}
+
+	result := subscriptionEvents("team/web", clients, events)
+	assert.Equal(t, []interface{}{events[0]}, result)
+
+	result = subscriptionEvents("db", clients, events)
+	assert.Equal(t, []interface{}{events[1], events[2]}, result)
+
+	result = subscriptionEvents("qux", clients, events)
+	assert.NotNil(t, result)
+	assert.Equal(t, 0, len(result))
+}
@@ -0,0 +1,44 @@
+package uchiwa
+
+import (
+	"github.com/sensu/uchiwa/uchiwa/helpers"
+	"github.com/sensu/uchiwa/uchiwa/structs"
+)
+
+// estateSummary holds the number of events by status, of healthy clients and
+// of unreachable datacenters across every datacenter
+type estateSummary struct {
+	Critical        int `json:"critical"`
+	Warning         int `json:"warning"`
+	Unknown         int `json:"unknown"`
+	Silenced        int `json:"silenced"`
+	OK              int `json:"ok"`
+	Clients         int `json:"clients"`
+	Datacenters     int `json:"datacenters"`
+	DatacentersDown int `json:"datacenters_down"`
+}
+
+// buildEstateSummary rolls up the provided clients, events and datacenters
+// health. A datacenter is down when it could not be reached at all
+func buildEstateSummary(clients, events []interface{}, health map[string]structs.SensuHealth) estateSummary {
+	clientsMetrics := helpers.BuildClientsMetrics(&clients)
+	eventsMetrics := helpers.BuildEventsMetrics(&events)
+
+	summary := estateSummary{
+		Critical:    eventsMetrics.Critical,
+		Warning:     eventsMetrics.Warning,
+		Unknown:     eventsMetrics.Unknown,
+		Silenced:    eventsMetrics.Silenced,
+		OK:          clientsMetrics.Healthy,
+		Clients:     clientsMetrics.Total,
+		Datacenters: len(health),
+	}
+
+	for _, dc := range health {
+		if dc.Status == 2 {
+			summary.DatacentersDown++
+		}
+	}
+
+	return summary
+}
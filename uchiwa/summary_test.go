@@ -0,0 +1,41 @@
+package uchiwa
+
+import (
+	"testing"
+
+	"github.com/sensu/uchiwa/uchiwa/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildEstateSummary(t *testing.T) {
+	clients := []interface{}{
+		map[string]interface{}{"name": "foo", "status": 0},
+		map[string]interface{}{"name": "bar", "status": 2},
+		map[string]interface{}{"name": "qux", "status": 0},
+	}
+	events := []interface{}{
+		map[string]interface{}{"check": map[string]interface{}{"status": float64(2)}},
+		map[string]interface{}{"check": map[string]interface{}{"status": float64(2)}, "silenced": true},
+		map[string]interface{}{"check": map[string]interface{}{"status": float64(1)}},
+		map[string]interface{}{"check": map[string]interface{}{"status": float64(3)}},
+	}
+	health := map[string]structs.SensuHealth{
+		"us-east-1": {Output: "ok", Status: 0},
+		"us-west-1": {Output: "Not connected to Redis", Status: 1},
+		"eu-west-1": {Output: "Connection error", Status: 2},
+	}
+
+	summary := buildEstateSummary(clients, events, health)
+	assert.Equal(t, estateSummary{
+		Critical:        1,
+		Warning:         1,
+		Unknown:         1,
+		Silenced:        1,
+		OK:              2,
+		Clients:         3,
+		Datacenters:     3,
+		DatacentersDown: 1,
+	}, summary)
+
+	assert.Equal(t, estateSummary{}, buildEstateSummary(nil, nil, nil))
+}
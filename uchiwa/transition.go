@@ -0,0 +1,139 @@
+package uchiwa
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sensu/uchiwa/uchiwa/structs"
+)
+
+// defaultFlapThreshold is the default number of state transitions above which
+// an event is considered flapping
+const defaultFlapThreshold = 5
+
+// transitionLog records when the status of each event changes across the
+// polls. The resolution of an event, which disappears once resolved, counts as
+// a transition. It must be accessed with the mutex held
+type transitionLog struct {
+	statuses    map[string]float64
+	transitions map[string][]int64
+}
+
+// flappingEvent holds an event and its number of recent state transitions
+type flappingEvent struct {
+	Transitions int         `json:"transitions"`
+	Event       interface{} `json:"event"`
+}
+
+// eventStatus returns the identifier and the check status of an event
+func eventStatus(e interface{}) (string, float64, bool) {
+	event, ok := e.(map[string]interface{})
+	if !ok {
+		return "", 0, false
+	}
+
+	id, ok := event["_id"].(string)
+	if !ok {
+		return "", 0, false
+	}
+
+	check, _ := event["check"].(map[string]interface{})
+	status, _ := check["status"].(float64)
+	return id, status, true
+}
+
+// update records the state transitions of the events of the provided data
+// and drops the transitions older than window seconds. The events of the
+// datacenters that could not be polled are left as is, and the events found
+// on the first successful poll are not reported as transitions
+func (l *transitionLog) update(data *structs.Data, now time.Time, window int) {
+	if len(data.Dc) == 0 {
+		return
+	}
+
+	polled := make(map[string]bool, len(data.Dc))
+	for _, dc := range data.Dc {
+		polled[dc.Name] = true
+	}
+
+	first := l.statuses == nil
+	if first {
+		l.statuses = make(map[string]float64)
+		l.transitions = make(map[string][]int64)
+	}
+
+	current := make(map[string]bool, len(data.Events))
+	for _, e := range data.Events {
+		id, status, ok := eventStatus(e)
+		if !ok {
+			continue
+		}
+		current[id] = true
+
+		previous, ok := l.statuses[id]
+		if !first && (ok && previous != status || !ok && status != 0) {
+			l.transitions[id] = append(l.transitions[id], now.Unix())
+		}
+		l.statuses[id] = status
+	}
+
+	for id := range l.statuses {
+		if current[id] || !polled[eventDc(id)] {
+			continue
+		}
+
+		// The event was resolved
+		delete(l.statuses, id)
+		l.transitions[id] = append(l.transitions[id], now.Unix())
+	}
+
+	oldest := now.Unix() - int64(window)
+	for id, transitions := range l.transitions {
+		i := 0
+		for i < len(transitions) && transitions[i] < oldest {
+			i++
+		}
+
+		if i == len(transitions) {
+			delete(l.transitions, id)
+		} else if i > 0 {
+			l.transitions[id] = append([]int64(nil), transitions[i:]...)
+		}
+	}
+}
+
+// eventDc returns the datacenter of an event identifier, dc/client/check
+func eventDc(id string) string {
+	return strings.SplitN(id, "/", 2)[0]
+}
+
+// flappingEvents returns the provided events with more than threshold state
+// transitions since the provided time, the noisiest first
+func flappingEvents(events []interface{}, transitions map[string][]int64, threshold int, since time.Time) []flappingEvent {
+	flapping := []flappingEvent{}
+
+	for _, e := range events {
+		id, _, ok := eventStatus(e)
+		if !ok {
+			continue
+		}
+
+		count := 0
+		for _, t := range transitions[id] {
+			if t >= since.Unix() {
+				count++
+			}
+		}
+
+		if count > threshold {
+			flapping = append(flapping, flappingEvent{Transitions: count, Event: e})
+		}
+	}
+
+	sort.SliceStable(flapping, func(i, j int) bool {
+		return flapping[i].Transitions > flapping[j].Transitions
+	})
+
+	return flapping
+}
@@ -0,0 +1,53 @@
+package uchiwa
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sensu/uchiwa/uchiwa/structs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransitionLog(t *testing.T) {
+	l := &transitionLog{}
+	now := time.Unix(1000, 0)
+
+	event := func(id string, status float64) interface{} {
+		return map[string]interface{}{"_id": id, "check": map[string]interface{}{"status": status}}
+	}
+	dcs := []*structs.Datacenter{{Name: "us-east-1"}}
+
+	// The events of the first poll are not transitions
+	l.update(&structs.Data{Dc: dcs, Events: []interface{}{event("us-east-1/foo/cpu", 2)}}, now, 600)
+	assert.Equal(t, 0, len(l.transitions))
+
+	l.update(&structs.Data{Dc: dcs, Events: []interface{}{event("us-east-1/foo/cpu", 1), event("us-east-1/bar/disk", 2)}}, now.Add(10*time.Second), 600)
+	l.update(&structs.Data{Dc: dcs, Events: []interface{}{event("us-east-1/foo/cpu", 1)}}, now.Add(20*time.Second), 600)
+	assert.Equal(t, []int64{1010}, l.transitions["us-east-1/foo/cpu"])
+	assert.Equal(t, []int64{1010, 1020}, l.transitions["us-east-1/bar/disk"])
+
+	// The events of an unreachable datacenter are not resolved
+	l.update(&structs.Data{Dc: []*structs.Datacenter{{Name: "us-west-1"}}}, now.Add(30*time.Second), 600)
+	assert.Equal(t, []int64{1010}, l.transitions["us-east-1/foo/cpu"])
+
+	// The transitions are dropped after the window
+	l.update(&structs.Data{Dc: dcs, Events: []interface{}{event("us-east-1/foo/cpu", 1)}}, now.Add(615*time.Second), 600)
+	assert.Equal(t, 1, len(l.transitions))
+	assert.Equal(t, []int64{1020}, l.transitions["us-east-1/bar/disk"])
+}
+
+func TestFlappingEvents(t *testing.T) {
+	foo := map[string]interface{}{"_id": "us-east-1/foo/cpu"}
+	bar := map[string]interface{}{"_id": "us-east-1/bar/disk"}
+	qux := map[string]interface{}{"_id": "us-east-1/qux/mem"}
+	transitions := map[string][]int64{
+		"us-east-1/foo/cpu":  {100, 200, 300},
+		"us-east-1/bar/disk": {200, 300, 400, 500},
+		"us-east-1/qux/mem":  {100, 110, 120, 130, 140},
+	}
+
+	flapping := flappingEvents([]interface{}{foo, bar, qux}, transitions, 1, time.Unix(150, 0))
+	assert.Equal(t, []flappingEvent{{Transitions: 4, Event: bar}, {Transitions: 2, Event: foo}}, flapping)
+
+	assert.Equal(t, []flappingEvent{}, flappingEvents([]interface{}{foo}, transitions, 5, time.Unix(0, 0)))
+}